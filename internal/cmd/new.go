@@ -29,8 +29,22 @@ func NewNewCmd(app *stamp.App) *cobra.Command {
 		},
 	}
 
-	cmd.Flags().BoolVar(&app.Config.DryRun, "dry-run", app.Config.DryRun, "Show generator tasks without taking action.")
+	cmd.Flags().BoolVar(&app.Config.DryRun, "dry-run", app.Config.DryRun, "Show a diff of generator changes without taking action.")
 	cmd.Flags().Lookup("dry-run").NoOptDefVal = "true"
+	cmd.Flags().BoolVar(&app.Config.NoRollback, "no-rollback", app.Config.NoRollback,
+		"Leave generated files in place if a task fails.")
+	cmd.Flags().Lookup("no-rollback").NoOptDefVal = "true"
+	cmd.Flags().IntVar(&app.Config.Jobs, "jobs", app.Config.Jobs,
+		"Number of files to generate concurrently.")
+	cmd.Flags().TextVar(&app.Config.Output, "output", app.Config.Output,
+		"Output format for task actions: text or json.")
+	cmd.Flags().StringVar(&action.ValuesPath, "values", action.ValuesPath,
+		"Read values from a JSON or YAML answers file (use - for stdin).")
+	cmd.Flags().StringVar(&action.SaveAnswersPath, "save-answers", action.SaveAnswersPath,
+		"Write the values you set to an answers file that can be replayed with --values.")
+	cmd.Flags().BoolVar(&app.Config.NoInput, "no-input", app.Config.NoInput,
+		"Fail rather than prompting for missing values.")
+	cmd.Flags().Lookup("no-input").NoOptDefVal = "true"
 
 	cmd.Flags().SortFlags = false
 	cmd.DisableFlagParsing = true
@@ -48,7 +62,9 @@ func NewNewAction(app *stamp.App) *NewAction {
 type NewAction struct {
 	*stamp.App
 
-	Name string
+	Name            string
+	SaveAnswersPath string
+	ValuesPath      string
 
 	cmd  *cobra.Command
 	args []string
@@ -88,6 +104,9 @@ func (a *NewAction) Run() error {
 		if len(all) == 0 {
 			return pflag.ErrHelp
 		}
+		if a.noInput() {
+			return fmt.Errorf("%w: generator name", stamp.ErrNoInput)
+		}
 
 		names := []string{}
 		for _, g := range all {
@@ -141,31 +160,70 @@ func (a *NewAction) Run() error {
 		return err
 	}
 
-	if err := generator.Values.Prompt(a.UI); err != nil {
+	// Set any values from the answers file (flags and args take precedence).
+	if err := a.setAnswers(generator); err != nil {
+		return err
+	}
+
+	if a.Config.NoInput {
+		if missing := generator.Values.Missing(); len(missing) > 0 {
+			return fmt.Errorf("%w: missing values for %s", stamp.ErrNoInput, strings.Join(missing, ", "))
+		}
+	} else if err := generator.Values.Prompt(a.UI); err != nil {
 		return err
 	}
 	if err := generator.Values.Validate(); err != nil {
 		return err
 	}
+	if a.SaveAnswersPath != "" {
+		if err := stamp.WriteAnswers(a.SaveAnswersPath, generator.Values.Answers(), a.IO.Out); err != nil {
+			return err
+		}
+	}
 
-	a.UI.Out("\n")
-	a.UI.Out("Running: %s\n", generator.Name())
-	a.UI.Out("\n")
-
-	// Prepare everything needed to execute.
-	ctx := stamp.NewTaskContext(a.App)
-	values := generator.Values.GetAll()
+	if isTextOutput(a.App) {
+		a.UI.Out("\n")
+		a.UI.Out("Running: %s\n", generator.Name())
+		a.UI.Out("\n")
+	}
 
 	// And finally... Release the hounds™
-	if err := generator.Tasks.Execute(ctx, values); err != nil {
+	if _, err := executeGenerator(a.App, generator, generator.Values.GetAll()); err != nil {
 		return err
 	}
 
-	a.UI.Out("\n")
+	if isTextOutput(a.App) {
+		a.UI.Out("\n")
+	}
 
 	return nil
 }
 
+// executeGenerator runs the generator's tasks and records a manifest
+// and journal of the run in the destination dir.
+// If anything fails, the destination is restored to its pre-run state
+// (unless the user wants to inspect the wreckage).
+func executeGenerator(app *stamp.App, generator *stamp.Generator, values map[string]any) (*stamp.TaskContext, error) {
+	ctx := stamp.NewTaskContext(app)
+
+	err := generator.Execute(ctx, values)
+	if err != nil && !app.Config.NoRollback {
+		if isTextOutput(app) {
+			app.UI.Out("\n")
+		}
+		if rbErr := ctx.Rollback(); rbErr != nil {
+			err = errors.Join(err, fmt.Errorf("rollback: %w", rbErr))
+		}
+	}
+	ctx.Logger.Summary(err)
+	return ctx, err
+}
+
+// isTextOutput returns true unless the user asked for machine readable output.
+func isTextOutput(app *stamp.App) bool {
+	return app.Config.Output != stamp.OutputFormatJson
+}
+
 func (a *NewAction) setUsage(generator *stamp.Generator) {
 	a.cmd.Use = strings.ReplaceAll(a.cmd.Use, "[name]", generator.Name())
 	for _, v := range generator.Values.Args() {
@@ -210,6 +268,35 @@ func (a *NewAction) setArgs(generator *stamp.Generator) error {
 	return nil
 }
 
+func (a *NewAction) setAnswers(generator *stamp.Generator) error {
+	if a.ValuesPath == "" {
+		return nil
+	}
+	answers, err := stamp.ReadAnswers(a.ValuesPath, a.IO.In)
+	if err != nil {
+		return err
+	}
+	for key, data := range answers {
+		if val := generator.Values.Value(key); val != nil && !val.IsUnset() {
+			continue
+		}
+		if err := generator.Values.Set(key, data); err != nil {
+			return fmt.Errorf("invalid answer for %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func (a *NewAction) noInput() bool {
+	// may not have parsed args yet, so manually check first
+	for _, arg := range a.args {
+		if arg == "--no-input" || arg == "--no-input=true" {
+			return true
+		}
+	}
+	return a.Config.NoInput
+}
+
 func (a *NewAction) showHelp() bool {
 	// may not have parsed args yet, so manually check first
 	for _, arg := range a.args {
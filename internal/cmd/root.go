@@ -23,7 +23,11 @@ func NewRootCmd(app *stamp.App) *cobra.Command {
 	cmd.AddCommand(NewNewCmd(app))
 	cmd.AddCommand(NewRemoveCmd(app))
 	cmd.AddCommand(NewSchemaCmd(app))
+	cmd.AddCommand(NewTestCmd(app))
+	cmd.AddCommand(NewUndoCmd(app))
 	cmd.AddCommand(NewUpdateCmd(app))
+	cmd.AddCommand(NewUpgradeCmd(app))
+	cmd.AddCommand(NewValidateCmd(app))
 	cmd.AddCommand(NewVersionCmd(app))
 
 	return cmd
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/twelvelabs/stamp/internal/stamp"
+)
+
+func NewTestCmd(app *stamp.App) *cobra.Command {
+	action := NewTestAction(app)
+
+	cmd := &cobra.Command{
+		Use:   "test <path|name>",
+		Short: "Run a generator's golden file tests",
+		Long: strings.Join([]string{
+			"Run a generator's golden file tests",
+			"",
+			"Each sub-dir of _test/ is a test case containing:",
+			"",
+			"  values.yaml  values used to run the generator (no prompts)",
+			"  before/      optional files the generator is run against",
+			"  expected/    the files the destination should contain afterwards",
+			"",
+			"Use --update to rewrite each expected/ dir with the current output.",
+		}, "\n"),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := action.Setup(cmd, args); err != nil {
+				return err
+			}
+			if err := action.Validate(); err != nil {
+				return err
+			}
+			return action.Run()
+		},
+	}
+
+	cmd.Flags().BoolVar(&action.Update, "update", action.Update, "Rewrite the expected files with the generator output.")
+	cmd.SilenceUsage = true
+
+	return cmd
+}
+
+func NewTestAction(app *stamp.App) *TestAction {
+	return &TestAction{
+		App: app,
+	}
+}
+
+type TestAction struct {
+	*stamp.App
+
+	Name   string
+	Update bool
+}
+
+func (a *TestAction) Setup(_ *cobra.Command, args []string) error {
+	if len(args) >= 1 {
+		a.Name = strings.Trim(args[0], " ")
+	}
+	return nil
+}
+
+func (a *TestAction) Validate() error {
+	if a.Name == "" {
+		return errors.New("path or name must not be blank")
+	}
+	return nil
+}
+
+func (a *TestAction) Run() error {
+	var (
+		generator *stamp.Generator
+		cleanup   stamp.CleanupFunc
+		err       error
+	)
+
+	generator, err = a.Store.Load(a.Name)
+	if errors.Is(err, stamp.ErrNotFound) {
+		generator, cleanup, err = a.Store.Stage(a.Name)
+		defer cleanup()
+	}
+	if err != nil {
+		return err
+	}
+
+	cases, err := generator.TestCases()
+	if err != nil {
+		return err
+	}
+	if len(cases) == 0 {
+		return fmt.Errorf("no test cases found in %s/%s", generator.Path(), stamp.TestDir)
+	}
+
+	failed := 0
+	for _, tc := range cases {
+		result, err := tc.Run(a.App, a.Update)
+		switch {
+		case err != nil:
+			failed++
+			a.UI.Out(a.UI.FailureIcon()+" %s: %s\n", tc.Name, err)
+		case result.Updated:
+			a.UI.Out(a.UI.SuccessIcon()+" %s (updated)\n", tc.Name)
+		case result.Passed():
+			a.UI.Out(a.UI.SuccessIcon()+" %s\n", tc.Name)
+		default:
+			failed++
+			a.UI.Out(a.UI.FailureIcon()+" %s\n", tc.Name)
+			for _, diff := range result.Diffs {
+				a.UI.Out("%s\n", diff)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d test case(s) failed", failed, len(cases))
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/twelvelabs/stamp/internal/fsutil"
+	"github.com/twelvelabs/stamp/internal/stamp"
+)
+
+func NewUndoCmd(app *stamp.App) *cobra.Command {
+	action := NewUndoAction(app)
+
+	cmd := &cobra.Command{
+		Use:   "undo",
+		Short: "Revert the last generator run in the current directory",
+		Long: strings.Join([]string{
+			"Revert the last generator run in the current directory",
+			"",
+			"Created files are removed, and updated or deleted files are restored.",
+			"Refuses to run if any of those files have been modified since (unless --force is passed).",
+		}, "\n"),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := action.Setup(cmd, args); err != nil {
+				return err
+			}
+			if err := action.Validate(); err != nil {
+				return err
+			}
+			return action.Run()
+		},
+	}
+
+	cmd.Flags().BoolVarP(&action.Force, "force", "f", action.Force, "Revert files even if they have been modified.")
+	cmd.SilenceUsage = true
+
+	return cmd
+}
+
+func NewUndoAction(app *stamp.App) *UndoAction {
+	return &UndoAction{
+		App: app,
+	}
+}
+
+type UndoAction struct {
+	*stamp.App
+
+	Force    bool
+	RootPath string
+}
+
+func (a *UndoAction) Setup(_ *cobra.Command, _ []string) error {
+	root, err := fsutil.ResolvePath(".")
+	if err != nil {
+		return err
+	}
+	a.RootPath = root
+	return nil
+}
+
+func (a *UndoAction) Validate() error {
+	return nil
+}
+
+func (a *UndoAction) Run() error {
+	ctx := stamp.NewTaskContext(a.App)
+
+	err := ctx.Undo(stamp.NewState(ctx.FS, a.RootPath), a.Force)
+	if errors.Is(err, stamp.ErrNotFound) {
+		return fmt.Errorf("nothing to undo in %s", a.RootPath)
+	}
+	if errors.Is(err, stamp.ErrModifiedSinceRun) {
+		return fmt.Errorf("%w (use --force to undo anyway)", err)
+	}
+	return err
+}
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/twelvelabs/stamp/internal/fsutil"
+	"github.com/twelvelabs/stamp/internal/stamp"
+)
+
+func NewUpgradeCmd(app *stamp.App) *cobra.Command {
+	action := NewUpgradeAction(app)
+
+	cmd := &cobra.Command{
+		Use:   "upgrade [name]",
+		Short: "Re-run a generator against the current directory",
+		Long: strings.Join([]string{
+			"Re-run a generator against the current directory",
+			"",
+			"Uses the latest installed version of the generator and the values",
+			"recorded in the manifest from the last time it was run.",
+			"Name is only required if more than one generator has been run.",
+		}, "\n"),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := action.Setup(cmd, args); err != nil {
+				return err
+			}
+			if err := action.Validate(); err != nil {
+				return err
+			}
+			return action.Run()
+		},
+	}
+
+	cmd.Flags().BoolVar(&app.Config.DryRun, "dry-run", app.Config.DryRun, "Show a diff of generator changes without taking action.")
+	cmd.Flags().Lookup("dry-run").NoOptDefVal = "true"
+	cmd.Flags().BoolVar(&app.Config.NoRollback, "no-rollback", app.Config.NoRollback,
+		"Leave generated files in place if a task fails.")
+	cmd.Flags().Lookup("no-rollback").NoOptDefVal = "true"
+	cmd.Flags().IntVar(&app.Config.Jobs, "jobs", app.Config.Jobs,
+		"Number of files to generate concurrently.")
+
+	cmd.Flags().TextVar(&app.Config.Output, "output", app.Config.Output,
+		"Output format for task actions: text or json.")
+	cmd.Flags().SortFlags = false
+	cmd.SilenceUsage = true
+
+	return cmd
+}
+
+func NewUpgradeAction(app *stamp.App) *UpgradeAction {
+	return &UpgradeAction{
+		App: app,
+	}
+}
+
+type UpgradeAction struct {
+	*stamp.App
+
+	Name     string
+	RootPath string
+}
+
+func (a *UpgradeAction) Setup(_ *cobra.Command, args []string) error {
+	if len(args) >= 1 {
+		a.Name = strings.Trim(args[0], " ")
+	}
+	root, err := fsutil.ResolvePath(".")
+	if err != nil {
+		return err
+	}
+	a.RootPath = root
+	return nil
+}
+
+func (a *UpgradeAction) Validate() error {
+	return nil
+}
+
+func (a *UpgradeAction) Run() error {
+	manifest, err := a.findManifest(stamp.NewState(a.App.FS, a.RootPath))
+	if err != nil {
+		return err
+	}
+
+	var (
+		generator *stamp.Generator
+		cleanup   stamp.CleanupFunc
+	)
+
+	// Prefer the installed generator, falling back to where it originally came from.
+	generator, err = a.Store.Load(manifest.Generator.Name)
+	if errors.Is(err, stamp.ErrNotFound) && manifest.Generator.Origin != "" {
+		generator, cleanup, err = a.Store.Stage(manifest.Generator.Origin)
+		defer cleanup()
+	}
+	if err != nil {
+		return err
+	}
+
+	// Replay the recorded values, then prompt for any the generator
+	// has added since the last run.
+	for key, val := range manifest.Values {
+		if err := generator.Values.Set(key, val); err != nil {
+			return fmt.Errorf("unable to set value '%s': %w", key, err)
+		}
+	}
+	if err := generator.Values.Set("DstPath", a.RootPath); err != nil {
+		return err
+	}
+	_ = generator.Values.GetAll() // workaround for cache invalidation issue
+	for _, val := range generator.Values.All() {
+		if _, ok := manifest.Values[val.Key]; ok || val.Key == "DstPath" {
+			continue
+		}
+		if err := val.Prompt(a.UI); err != nil {
+			return err
+		}
+	}
+	if err := generator.Values.Validate(); err != nil {
+		return err
+	}
+
+	if isTextOutput(a.App) {
+		a.UI.Out("\n")
+		a.UI.Out("Upgrading: %s %s\n", generator.Name(), versionChange(manifest, generator))
+		a.UI.Out("\n")
+	}
+
+	ctx, err := executeGenerator(a.App, generator, generator.Values.GetAll())
+	if err != nil {
+		return err
+	}
+
+	upstream, local, err := manifest.Changes(a.RootPath, ctx.Journal)
+	if err != nil {
+		return err
+	}
+
+	if isTextOutput(a.App) {
+		a.UI.Out("\n")
+		a.printPaths("Changed upstream", upstream)
+		a.printPaths("Changed locally", local)
+	}
+
+	return nil
+}
+
+// findManifest returns the manifest for the named generator,
+// or the only manifest in the state dir if no name was given.
+func (a *UpgradeAction) findManifest(state *stamp.State) (*stamp.Manifest, error) {
+	if a.Name != "" {
+		m, err := state.ReadManifest(a.Name)
+		if errors.Is(err, stamp.ErrNotFound) {
+			return nil, fmt.Errorf("no manifest found for '%s' in %s", a.Name, a.RootPath)
+		}
+		return m, err
+	}
+
+	manifests, err := state.Manifests()
+	if err != nil {
+		return nil, err
+	}
+	switch len(manifests) {
+	case 0:
+		return nil, fmt.Errorf("no manifests found in %s", a.RootPath)
+	case 1:
+		return manifests[0], nil
+	default:
+		names := []string{}
+		for _, m := range manifests {
+			names = append(names, m.Generator.Name)
+		}
+		return nil, fmt.Errorf("multiple generators found, specify one of: %s", strings.Join(names, ", "))
+	}
+}
+
+func (a *UpgradeAction) printPaths(label string, paths []string) {
+	a.UI.Out("%s:\n", a.UI.Formatter.Bold(label))
+	if len(paths) == 0 {
+		a.UI.Out("  (none)\n")
+	}
+	for _, p := range paths {
+		a.UI.Out("  %s\n", p)
+	}
+	a.UI.Out("\n")
+}
+
+func versionChange(m *stamp.Manifest, generator *stamp.Generator) string {
+	from, to := m.Generator.Version, generator.Version()
+	if from == "" && to == "" {
+		return ""
+	}
+	if from == to {
+		return fmt.Sprintf("(%s)", to)
+	}
+	return fmt.Sprintf("(%s -> %s)", from, to)
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/twelvelabs/stamp/internal/pkg"
+	"github.com/twelvelabs/stamp/internal/stamp"
+)
+
+func NewValidateCmd(app *stamp.App) *cobra.Command {
+	action := NewValidateAction(app)
+
+	cmd := &cobra.Command{
+		Use:   "validate <path|name>",
+		Short: "Check a generator for problems",
+		Long: strings.Join([]string{
+			"Check a generator for problems",
+			"",
+			"Validates generator.yaml against the generator schema, parses each value and task,",
+			"and compiles every template (including the files in _src).",
+			"Templates referencing undefined values and missing src paths are reported as well.",
+		}, "\n"),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := action.Setup(cmd, args); err != nil {
+				return err
+			}
+			if err := action.Validate(); err != nil {
+				return err
+			}
+			return action.Run()
+		},
+	}
+
+	cmd.SilenceUsage = true
+
+	return cmd
+}
+
+func NewValidateAction(app *stamp.App) *ValidateAction {
+	return &ValidateAction{
+		App: app,
+	}
+}
+
+type ValidateAction struct {
+	*stamp.App
+
+	Name string
+}
+
+func (a *ValidateAction) Setup(_ *cobra.Command, args []string) error {
+	if len(args) >= 1 {
+		a.Name = strings.Trim(args[0], " ")
+	}
+	return nil
+}
+
+func (a *ValidateAction) Validate() error {
+	if a.Name == "" {
+		return errors.New("path or name must not be blank")
+	}
+	return nil
+}
+
+func (a *ValidateAction) Run() error {
+	path, err := a.packagePath()
+	if err != nil {
+		return err
+	}
+
+	problems, err := stamp.ValidateGenerator(a.Store, path)
+	if err != nil {
+		return err
+	}
+
+	if len(problems) == 0 {
+		a.UI.Out(a.UI.SuccessIcon()+" %s is valid\n", a.Name)
+		return nil
+	}
+	for _, p := range problems {
+		p.Path = relativePath(p.Path)
+		a.UI.Out("%s\n", p)
+	}
+	return fmt.Errorf("found %d problem(s) in %s", len(problems), a.Name)
+}
+
+// packagePath resolves a local path or the name of an installed generator.
+// Does not load the generator, since that stops at the first problem.
+func (a *ValidateAction) packagePath() (string, error) {
+	if pkg.IsPackagePath(a.Name, a.Store.MetaFile) {
+		return filepath.Abs(a.Name)
+	}
+	path, err := pkg.PackagePath(a.Store.BasePath, a.Name)
+	if err == nil && pkg.IsPackagePath(path, a.Store.MetaFile) {
+		return path, nil
+	}
+	return "", fmt.Errorf("%w: %s", stamp.ErrNotFound, a.Name)
+}
+
+// relativePath returns path relative to the working dir when it is beneath it.
+func relativePath(path string) string {
+	wd, err := filepath.Abs(".")
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
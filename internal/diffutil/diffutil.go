@@ -0,0 +1,44 @@
+package diffutil
+
+import (
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const (
+	// DevNull is the label used for the missing side of a diff
+	// (i.e. when a file is being created or deleted).
+	DevNull = "/dev/null"
+
+	// DefaultContext is the number of unchanged lines shown around each hunk.
+	DefaultContext = 3
+)
+
+// Unified returns a unified diff between a and b.
+// Returns an empty string if the content is identical.
+func Unified(a []byte, b []byte, fromFile string, toFile string) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        SplitLines(a),
+		B:        SplitLines(b),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  DefaultContext,
+	})
+}
+
+// SplitLines splits content into lines, each retaining its trailing newline.
+// Empty content returns an empty slice.
+func SplitLines(content []byte) []string {
+	if len(content) == 0 {
+		return []string{}
+	}
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	} else {
+		// Ensure the diff output is line oriented.
+		lines[len(lines)-1] += "\n"
+	}
+	return lines
+}
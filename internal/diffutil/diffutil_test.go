@@ -0,0 +1,67 @@
+package diffutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		Desc     string
+		A        string
+		B        string
+		From     string
+		To       string
+		Expected string
+	}{
+		{
+			Desc:     "returns an empty string when content is identical",
+			A:        "foo\nbar\n",
+			B:        "foo\nbar\n",
+			From:     "a/file.txt",
+			To:       "b/file.txt",
+			Expected: "",
+		},
+		{
+			Desc: "diffs new files against /dev/null",
+			A:    "",
+			B:    "foo\nbar\n",
+			From: DevNull,
+			To:   "b/file.txt",
+			Expected: "--- /dev/null\n" +
+				"+++ b/file.txt\n" +
+				"@@ -0,0 +1,2 @@\n" +
+				"+foo\n" +
+				"+bar\n",
+		},
+		{
+			Desc: "diffs changed lines",
+			A:    "foo\nbar\nbaz",
+			B:    "foo\nBAR\nbaz",
+			From: "a/file.txt",
+			To:   "b/file.txt",
+			Expected: "--- a/file.txt\n" +
+				"+++ b/file.txt\n" +
+				"@@ -1,3 +1,3 @@\n" +
+				" foo\n" +
+				"-bar\n" +
+				"+BAR\n" +
+				" baz\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Desc, func(t *testing.T) {
+			actual, err := Unified([]byte(tt.A), []byte(tt.B), tt.From, tt.To)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.Expected, actual)
+		})
+	}
+}
+
+func TestSplitLines(t *testing.T) {
+	assert.Equal(t, []string{}, SplitLines(nil))
+	assert.Equal(t, []string{"foo\n", "bar\n"}, SplitLines([]byte("foo\nbar\n")))
+	assert.Equal(t, []string{"foo\n", "bar\n"}, SplitLines([]byte("foo\nbar")))
+	assert.Equal(t, []string{"\n"}, SplitLines([]byte("\n")))
+}
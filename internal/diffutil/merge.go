@@ -0,0 +1,193 @@
+package diffutil
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// Conflict markers (git style).
+const (
+	MarkerOurs   = "<<<<<<<"
+	MarkerSep    = "======="
+	MarkerTheirs = ">>>>>>>"
+)
+
+// hunk is a change to base[start:end], replacing it with lines.
+type hunk struct {
+	start int
+	end   int
+	lines []string
+	ours  bool
+}
+
+// Merge3 performs a line based three-way merge.
+//
+// Changes made to base in ours and theirs are combined. When both sides
+// change the same region of base differently, the region is wrapped in
+// git-style conflict markers (using the given labels) and
+// the returned bool will be true.
+//
+// When base is empty (i.e. no previous version was recorded),
+// ours and theirs are merged two-way: lines they share are kept and
+// only the regions that differ are wrapped in conflict markers.
+func Merge3(base, ours, theirs []byte, oursLabel, theirsLabel string) ([]byte, bool) {
+	if len(base) == 0 {
+		return merge2(ours, theirs, oursLabel, theirsLabel)
+	}
+
+	baseLines := splitLinesExact(base)
+	oursLines := splitLinesExact(ours)
+	theirsLines := splitLinesExact(theirs)
+
+	hunks := append(
+		changes(baseLines, oursLines, true),
+		changes(baseLines, theirsLines, false)...,
+	)
+	sort.SliceStable(hunks, func(i, j int) bool {
+		return hunks[i].start < hunks[j].start
+	})
+
+	out := &bytes.Buffer{}
+	conflicted := false
+	pos := 0 // current position in base
+	for i := 0; i < len(hunks); {
+		// Group all hunks that overlap into a single cluster.
+		cluster := []hunk{hunks[i]}
+		lo, hi := hunks[i].start, hunks[i].end
+		i++
+		for i < len(hunks) && overlaps(lo, hi, hunks[i]) {
+			cluster = append(cluster, hunks[i])
+			hi = max(hi, hunks[i].end)
+			i++
+		}
+
+		// Copy unchanged base lines up to the cluster.
+		writeLines(out, baseLines[pos:lo])
+		pos = hi
+
+		oursVersion, oursChanged := apply(baseLines, lo, hi, cluster, true)
+		theirsVersion, theirsChanged := apply(baseLines, lo, hi, cluster, false)
+		switch {
+		case !theirsChanged:
+			writeLines(out, oursVersion)
+		case !oursChanged:
+			writeLines(out, theirsVersion)
+		case strings.Join(oursVersion, "") == strings.Join(theirsVersion, ""):
+			writeLines(out, oursVersion)
+		default:
+			conflicted = true
+			writeMarker(out, MarkerOurs, oursLabel)
+			writeLines(out, oursVersion)
+			writeMarker(out, MarkerSep, "")
+			writeLines(out, theirsVersion)
+			writeMarker(out, MarkerTheirs, theirsLabel)
+		}
+	}
+	writeLines(out, baseLines[pos:])
+
+	return out.Bytes(), conflicted
+}
+
+// merge2 performs a line based two-way merge,
+// writing conflict markers around each region where ours and theirs differ.
+func merge2(ours, theirs []byte, oursLabel, theirsLabel string) ([]byte, bool) {
+	oursLines := splitLinesExact(ours)
+	theirsLines := splitLinesExact(theirs)
+
+	out := &bytes.Buffer{}
+	conflicted := false
+	matcher := difflib.NewMatcherWithJunk(oursLines, theirsLines, false, nil)
+	for _, op := range matcher.GetOpCodes() {
+		if op.Tag == 'e' {
+			writeLines(out, oursLines[op.I1:op.I2])
+			continue
+		}
+		conflicted = true
+		writeMarker(out, MarkerOurs, oursLabel)
+		writeLines(out, oursLines[op.I1:op.I2])
+		writeMarker(out, MarkerSep, "")
+		writeLines(out, theirsLines[op.J1:op.J2])
+		writeMarker(out, MarkerTheirs, theirsLabel)
+	}
+	return out.Bytes(), conflicted
+}
+
+// changes returns the hunks needed to transform base into other.
+func changes(base, other []string, ours bool) []hunk {
+	hunks := []hunk{}
+	matcher := difflib.NewMatcherWithJunk(base, other, false, nil)
+	for _, op := range matcher.GetOpCodes() {
+		if op.Tag == 'e' {
+			continue
+		}
+		hunks = append(hunks, hunk{
+			start: op.I1,
+			end:   op.I2,
+			lines: other[op.J1:op.J2],
+			ours:  ours,
+		})
+	}
+	return hunks
+}
+
+// overlaps returns true if h touches the base range [lo, hi).
+// Insertions at the same position are considered overlapping.
+func overlaps(lo, hi int, h hunk) bool {
+	if h.start == lo {
+		return true
+	}
+	return h.start < hi
+}
+
+// apply returns base[lo:hi] with one side's hunks applied, and whether
+// that side made any changes.
+func apply(base []string, lo, hi int, cluster []hunk, ours bool) ([]string, bool) {
+	result := []string{}
+	pos := lo
+	changed := false
+	for _, h := range cluster {
+		if h.ours != ours {
+			continue
+		}
+		changed = true
+		result = append(result, base[pos:h.start]...)
+		result = append(result, h.lines...)
+		pos = h.end
+	}
+	result = append(result, base[pos:hi]...)
+	return result, changed
+}
+
+func writeLines(out *bytes.Buffer, lines []string) {
+	for _, line := range lines {
+		out.WriteString(line)
+	}
+}
+
+func writeMarker(out *bytes.Buffer, marker, label string) {
+	// Markers must always start on their own line.
+	if out.Len() > 0 && !bytes.HasSuffix(out.Bytes(), []byte("\n")) {
+		out.WriteString("\n")
+	}
+	out.WriteString(marker)
+	if label != "" {
+		out.WriteString(" " + label)
+	}
+	out.WriteString("\n")
+}
+
+// splitLinesExact splits content into lines, each retaining its trailing newline.
+// Unlike SplitLines, a missing trailing newline is preserved.
+func splitLinesExact(content []byte) []string {
+	if len(content) == 0 {
+		return []string{}
+	}
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
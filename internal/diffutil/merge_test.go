@@ -0,0 +1,122 @@
+package diffutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge3(t *testing.T) {
+	tests := []struct {
+		Desc       string
+		Base       string
+		Ours       string
+		Theirs     string
+		Expected   string
+		Conflicted bool
+	}{
+		{
+			Desc:     "returns base when nothing changed",
+			Base:     "aaa\nbbb\nccc\n",
+			Ours:     "aaa\nbbb\nccc\n",
+			Theirs:   "aaa\nbbb\nccc\n",
+			Expected: "aaa\nbbb\nccc\n",
+		},
+		{
+			Desc:     "keeps our changes",
+			Base:     "aaa\nbbb\nccc\n",
+			Ours:     "aaa\nBBB\nccc\n",
+			Theirs:   "aaa\nbbb\nccc\n",
+			Expected: "aaa\nBBB\nccc\n",
+		},
+		{
+			Desc:     "applies their changes",
+			Base:     "aaa\nbbb\nccc\n",
+			Ours:     "aaa\nbbb\nccc\n",
+			Theirs:   "aaa\nbbb\nccc\nddd\n",
+			Expected: "aaa\nbbb\nccc\nddd\n",
+		},
+		{
+			Desc:     "combines non-overlapping changes",
+			Base:     "aaa\nbbb\nccc\nddd\neee\n",
+			Ours:     "AAA\nbbb\nccc\nddd\neee\n",
+			Theirs:   "aaa\nbbb\nccc\nddd\nEEE\nfff\n",
+			Expected: "AAA\nbbb\nccc\nddd\nEEE\nfff\n",
+		},
+		{
+			Desc:     "does not conflict when both sides make the same change",
+			Base:     "aaa\nbbb\nccc\n",
+			Ours:     "aaa\nBBB\nccc\n",
+			Theirs:   "aaa\nBBB\nccc\n",
+			Expected: "aaa\nBBB\nccc\n",
+		},
+		{
+			Desc:   "writes conflict markers when changes collide",
+			Base:   "aaa\nbbb\nccc\n",
+			Ours:   "aaa\nours\nccc\n",
+			Theirs: "aaa\ntheirs\nccc\n",
+			Expected: "aaa\n" +
+				"<<<<<<< local\n" +
+				"ours\n" +
+				"=======\n" +
+				"theirs\n" +
+				">>>>>>> generated\n" +
+				"ccc\n",
+			Conflicted: true,
+		},
+		{
+			Desc:   "conflicts when both sides insert at the same position",
+			Base:   "",
+			Ours:   "ours\n",
+			Theirs: "theirs",
+			Expected: "<<<<<<< local\n" +
+				"ours\n" +
+				"=======\n" +
+				"theirs\n" +
+				">>>>>>> generated\n",
+			Conflicted: true,
+		},
+		{
+			Desc:     "preserves a missing trailing newline",
+			Base:     "aaa\nbbb",
+			Ours:     "AAA\nbbb",
+			Theirs:   "aaa\nbbb",
+			Expected: "AAA\nbbb",
+		},
+		{
+			Desc:     "does not conflict without a base when both sides are the same",
+			Base:     "",
+			Ours:     "aaa\nbbb\nccc\n",
+			Theirs:   "aaa\nbbb\nccc\n",
+			Expected: "aaa\nbbb\nccc\n",
+		},
+		{
+			Desc:   "only conflicts on the differing lines without a base",
+			Base:   "",
+			Ours:   "aaa\nbbb\nccc\nddd\n",
+			Theirs: "aaa\nBBB\nccc\nddd\neee\n",
+			Expected: "aaa\n" +
+				"<<<<<<< local\n" +
+				"bbb\n" +
+				"=======\n" +
+				"BBB\n" +
+				">>>>>>> generated\n" +
+				"ccc\n" +
+				"ddd\n" +
+				"<<<<<<< local\n" +
+				"=======\n" +
+				"eee\n" +
+				">>>>>>> generated\n",
+			Conflicted: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Desc, func(t *testing.T) {
+			merged, conflicted := Merge3(
+				[]byte(tt.Base), []byte(tt.Ours), []byte(tt.Theirs), "local", "generated",
+			)
+			assert.Equal(t, tt.Expected, string(merged))
+			assert.Equal(t, tt.Conflicted, conflicted)
+		})
+	}
+}
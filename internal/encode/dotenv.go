@@ -0,0 +1,225 @@
+package encode
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+var _ Encoder = &DotenvEncoder{}
+var _ Patcher = &DotenvEncoder{}
+
+var dotenvKeyPattern = regexp.MustCompile(`^(\s*(?:export\s+)?([A-Za-z_][A-Za-z0-9_.]*)\s*=\s*)(.*)$`)
+
+type DotenvEncoder struct {
+}
+
+// Decode deserializes the given dotenv encoded byte array into a map
+// of variable names to (string) values. Variable references
+// (i.e. `${FOO}`) are not expanded.
+func (e *DotenvEncoder) Decode(encoded []byte) (any, error) {
+	entries, err := parseDotenv(encoded)
+	if err != nil {
+		return nil, err
+	}
+	data := map[string]any{}
+	for _, entry := range entries {
+		data[entry.key] = entry.value
+	}
+	return data, nil
+}
+
+// Encode serializes the given map into a dotenv encoded byte array
+// (one `KEY=value` line per key, sorted by key).
+func (e *DotenvEncoder) Encode(data any) ([]byte, error) {
+	values, err := dotenvValues(data)
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	for _, key := range sortedKeys(values) {
+		buf.WriteString(key + "=" + quoteDotenvValue(values[key]) + "\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// Patch serializes the given map by updating the lines of the original
+// dotenv file. Comments, blank lines, and the order of existing
+// variables are preserved. New variables are appended (sorted by key).
+func (e *DotenvEncoder) Patch(original []byte, data any) ([]byte, error) {
+	values, err := dotenvValues(data)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := parseDotenv(original)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.SplitAfter(string(original), "\n")
+	seen := map[string]bool{}
+	buf := &bytes.Buffer{}
+	next := 0
+	for _, entry := range entries {
+		buf.WriteString(strings.Join(lines[next:entry.start], ""))
+		next = entry.end
+		seen[entry.key] = true
+
+		value, ok := values[entry.key]
+		switch {
+		case !ok:
+			continue // removed
+		case value == entry.value:
+			buf.WriteString(strings.Join(lines[entry.start:entry.end], ""))
+		default:
+			buf.WriteString(entry.prefix + quoteDotenvValue(value) + entry.suffix)
+		}
+	}
+	buf.WriteString(strings.Join(lines[next:], ""))
+
+	for _, key := range sortedKeys(values) {
+		if seen[key] {
+			continue
+		}
+		if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(key + "=" + quoteDotenvValue(values[key]) + "\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// dotenvEntry is a variable assignment spanning lines [start, end).
+type dotenvEntry struct {
+	key    string
+	value  string
+	start  int
+	end    int
+	prefix string // everything before the value (i.e. `export KEY=`)
+	suffix string // everything after the value (i.e. ` # comment\n`)
+}
+
+func parseDotenv(encoded []byte) ([]dotenvEntry, error) {
+	lines := strings.SplitAfter(string(encoded), "\n")
+	entries := []dotenvEntry{}
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r\n")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		m := dotenvKeyPattern.FindStringSubmatch(line)
+		if m == nil {
+			return nil, fmt.Errorf("dotenv decode: invalid line %d: %s", i+1, line)
+		}
+		entry := dotenvEntry{key: m[2], prefix: m[1], start: i}
+		raw := m[3]
+
+		if quote := leadingQuote(raw); quote != "" {
+			// Quoted values may span multiple lines.
+			for {
+				if end := closingQuote(raw, quote); end > 0 {
+					entry.value = unquoteDotenvValue(raw[:end+1])
+					entry.suffix = raw[end+1:] + lineEnding(lines[i])
+					break
+				}
+				if i+1 >= len(lines) || lines[i+1] == "" {
+					return nil, fmt.Errorf("dotenv decode: unterminated value for %s", entry.key)
+				}
+				i++
+				raw += "\n" + strings.TrimRight(lines[i], "\r\n")
+			}
+		} else {
+			value := raw
+			if idx := strings.Index(value, " #"); idx >= 0 {
+				value = value[:idx]
+			}
+			entry.value = strings.TrimSpace(value)
+			entry.suffix = raw[len(strings.TrimRight(value, " \t")):] + lineEnding(lines[i])
+		}
+		entry.end = i + 1
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func dotenvValues(data any) (map[string]string, error) {
+	if data == nil {
+		return map[string]string{}, nil
+	}
+	m, err := cast.ToStringMapE(data)
+	if err != nil {
+		return nil, fmt.Errorf("dotenv encode: unable to cast: %#v", data)
+	}
+	values := map[string]string{}
+	for k, v := range m {
+		s, err := cast.ToStringE(v)
+		if err != nil {
+			return nil, fmt.Errorf("dotenv encode: unable to cast %s: %#v", k, v)
+		}
+		values[k] = s
+	}
+	return values, nil
+}
+
+func leadingQuote(raw string) string {
+	if strings.HasPrefix(raw, `"`) || strings.HasPrefix(raw, `'`) {
+		return raw[:1]
+	}
+	return ""
+}
+
+// closingQuote returns the index of the (unescaped) quote
+// that terminates raw, or -1 if not found.
+func closingQuote(raw string, quote string) int {
+	for i := 1; i < len(raw); i++ {
+		if quote == `"` && raw[i] == '\\' {
+			i++
+			continue
+		}
+		if raw[i] == quote[0] {
+			return i
+		}
+	}
+	return -1
+}
+
+func unquoteDotenvValue(quoted string) string {
+	value := quoted[1 : len(quoted)-1]
+	if quoted[0] == '\'' {
+		return value
+	}
+	return strings.NewReplacer(`\n`, "\n", `\"`, `"`, `\\`, `\`).Replace(value)
+}
+
+var dotenvUnquotedPattern = regexp.MustCompile(`^[^\s"'#\\]*$`)
+
+func quoteDotenvValue(value string) string {
+	if dotenvUnquotedPattern.MatchString(value) {
+		return value
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
+
+func lineEnding(line string) string {
+	if strings.HasSuffix(line, "\r\n") {
+		return "\r\n"
+	}
+	if strings.HasSuffix(line, "\n") {
+		return "\n"
+	}
+	return ""
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,79 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDotenvEncoder_Patch_RoundTrip(t *testing.T) {
+	original := "# Service config.\n" +
+		"export NAME=api # the service name\n" +
+		"\n" +
+		"HOST=\"0.0.0.0\"\n" +
+		"PORT=8080\n" +
+		"URL=${HOST}:${PORT}\n"
+
+	tests := []struct {
+		name string
+		edit func(data map[string]any)
+		want string
+	}{
+		{
+			name: "leaves unchanged content byte-identical",
+			edit: func(data map[string]any) {},
+			want: original,
+		},
+		{
+			name: "updates a single key",
+			edit: func(data map[string]any) {
+				data["PORT"] = "9090"
+			},
+			want: "# Service config.\n" +
+				"export NAME=api # the service name\n" +
+				"\n" +
+				"HOST=\"0.0.0.0\"\n" +
+				"PORT=9090\n" +
+				"URL=${HOST}:${PORT}\n",
+		},
+		{
+			name: "keeps comments on updated keys",
+			edit: func(data map[string]any) {
+				data["NAME"] = "web"
+			},
+			want: "# Service config.\n" +
+				"export NAME=web # the service name\n" +
+				"\n" +
+				"HOST=\"0.0.0.0\"\n" +
+				"PORT=8080\n" +
+				"URL=${HOST}:${PORT}\n",
+		},
+		{
+			name: "appends keys",
+			edit: func(data map[string]any) {
+				data["TLS"] = "true"
+				data["DEBUG"] = "false"
+			},
+			want: "# Service config.\n" +
+				"export NAME=api # the service name\n" +
+				"\n" +
+				"HOST=\"0.0.0.0\"\n" +
+				"PORT=8080\n" +
+				"URL=${HOST}:${PORT}\n" +
+				"DEBUG=false\n" +
+				"TLS=true\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &DotenvEncoder{}
+			data, err := e.Decode([]byte(original))
+			assert.NoError(t, err)
+			tt.edit(data.(map[string]any))
+
+			got, err := e.Patch([]byte(original), data)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
@@ -9,3 +9,13 @@ type Encoder interface {
 	// Encode serializes the given data structure into a byte array.
 	Encode(data any) ([]byte, error)
 }
+
+// Patcher is an optional interface for encoders that can serialize
+// data structures by patching a previously encoded byte array,
+// preserving the formatting (comments, key order, etc) of any
+// content that has not changed.
+type Patcher interface {
+	// Patch serializes the given data structure into an updated copy
+	// of the original encoded byte array.
+	Patch(original []byte, data any) ([]byte, error)
+}
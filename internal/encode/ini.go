@@ -0,0 +1,291 @@
+package encode
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+var _ Encoder = &INIEncoder{}
+var _ Patcher = &INIEncoder{}
+
+const iniContinuationIndent = "    "
+
+var (
+	iniSectionPattern = regexp.MustCompile(`^\s*\[([^\]]+)\]\s*$`)
+	iniKeyPattern     = regexp.MustCompile(`^([^\s=:;#\[][^=:]*?)(\s*[=:]\s*)(.*)$`)
+)
+
+type INIEncoder struct {
+}
+
+// Decode deserializes the given INI encoded byte array into a map.
+// Keys outside of any section are top-level keys, and each section
+// is a nested map of keys to (string) values. Indented continuation
+// lines (i.e. `setup.cfg` lists) are joined with newlines.
+func (e *INIEncoder) Decode(encoded []byte) (any, error) {
+	file, err := parseINI(encoded)
+	if err != nil {
+		return nil, err
+	}
+	data := map[string]any{}
+	for _, section := range file.sections {
+		table := data
+		if section.name != "" {
+			if existing, ok := data[section.name].(map[string]any); ok {
+				table = existing
+			} else {
+				table = map[string]any{}
+				data[section.name] = table
+			}
+		}
+		for _, entry := range section.entries {
+			table[entry.key] = entry.value
+		}
+	}
+	return data, nil
+}
+
+// Encode serializes the given map into an INI encoded byte array.
+// Top-level keys are written first, followed by a section
+// for each nested map (keys are sorted).
+func (e *INIEncoder) Encode(data any) ([]byte, error) {
+	root, sections, err := iniValues(data)
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	for _, key := range sortedKeys(root) {
+		buf.WriteString(formatINIEntry(key, " = ", root[key], iniContinuationIndent))
+	}
+	for _, name := range sortedKeys(sections) {
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(formatINISection(name, sections[name]))
+	}
+	return buf.Bytes(), nil
+}
+
+// Patch serializes the given map by updating the lines of the original
+// INI file. Comments, blank lines, and the order of existing sections
+// and keys are preserved. New keys are added to the end of their section
+// and new sections are appended to the end of the file (sorted by name).
+func (e *INIEncoder) Patch(original []byte, data any) ([]byte, error) {
+	root, sections, err := iniValues(data)
+	if err != nil {
+		return nil, err
+	}
+	file, err := parseINI(original)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.SplitAfter(string(original), "\n")
+	seen := map[string]bool{}
+	buf := &bytes.Buffer{}
+	for _, section := range file.sections {
+		values := root
+		if section.name != "" {
+			var ok bool
+			if values, ok = sections[section.name]; !ok {
+				continue // removed
+			}
+			if seen[section.name] {
+				// Duplicate sections were merged when decoding.
+				buf.WriteString(strings.Join(lines[section.start:section.end], ""))
+				continue
+			}
+		}
+		seen[section.name] = true
+
+		next := section.start
+		written := map[string]bool{}
+		for _, entry := range section.entries {
+			buf.WriteString(strings.Join(lines[next:entry.start], ""))
+			next = entry.end
+			written[entry.key] = true
+
+			value, ok := values[entry.key]
+			switch {
+			case !ok:
+				continue // removed
+			case value == entry.value:
+				buf.WriteString(strings.Join(lines[entry.start:entry.end], ""))
+			default:
+				buf.WriteString(formatINIEntry(entry.key, entry.delimiter, value, file.indent))
+			}
+		}
+		// Add new keys after the last entry (before any trailing blank lines).
+		trailing := section.end
+		for trailing > next && strings.TrimSpace(lines[trailing-1]) == "" {
+			trailing--
+		}
+		buf.WriteString(strings.Join(lines[next:trailing], ""))
+		for _, key := range sortedKeys(values) {
+			if written[key] {
+				continue
+			}
+			if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+				buf.WriteString("\n")
+			}
+			buf.WriteString(formatINIEntry(key, file.delimiter, values[key], file.indent))
+		}
+		buf.WriteString(strings.Join(lines[trailing:section.end], ""))
+	}
+
+	for _, name := range sortedKeys(sections) {
+		if seen[name] {
+			continue
+		}
+		if buf.Len() > 0 {
+			if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+				buf.WriteString("\n")
+			}
+			if !bytes.HasSuffix(buf.Bytes(), []byte("\n\n")) {
+				buf.WriteString("\n")
+			}
+		}
+		buf.WriteString(formatINISection(name, sections[name]))
+	}
+	return buf.Bytes(), nil
+}
+
+type iniFile struct {
+	sections  []iniSection
+	delimiter string // the delimiter used by the first key
+	indent    string // the indentation used by the first continuation line
+}
+
+// iniSection is a (possibly unnamed) section spanning lines [start, end).
+type iniSection struct {
+	name    string
+	entries []iniEntry
+	start   int
+	end     int
+}
+
+// iniEntry is a key and value spanning lines [start, end).
+type iniEntry struct {
+	key       string
+	delimiter string
+	value     string
+	start     int
+	end       int
+}
+
+func parseINI(encoded []byte) (*iniFile, error) {
+	lines := strings.SplitAfter(string(encoded), "\n")
+	file := &iniFile{sections: []iniSection{{}}}
+	section := &file.sections[0]
+	var entry *iniEntry
+	for i, raw := range lines {
+		line := strings.TrimRight(raw, "\r\n")
+		trimmed := strings.TrimSpace(line)
+
+		// Indented lines continue the previous value.
+		if indented := strings.TrimLeft(line, " \t"); entry != nil && trimmed != "" && indented != line {
+			if file.indent == "" {
+				file.indent = line[:len(line)-len(indented)]
+			}
+			if entry.value != "" {
+				entry.value += "\n"
+			}
+			entry.value += trimmed
+			entry.end = i + 1
+			continue
+		}
+		entry = nil
+
+		if trimmed == "" || strings.HasPrefix(trimmed, ";") || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if m := iniSectionPattern.FindStringSubmatch(line); m != nil {
+			section.end = i
+			file.sections = append(file.sections, iniSection{name: strings.TrimSpace(m[1]), start: i})
+			section = &file.sections[len(file.sections)-1]
+			continue
+		}
+		m := iniKeyPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			return nil, fmt.Errorf("ini decode: invalid line %d: %s", i+1, line)
+		}
+		if file.delimiter == "" {
+			file.delimiter = m[2]
+		}
+		section.entries = append(section.entries, iniEntry{
+			key:       strings.TrimSpace(m[1]),
+			delimiter: m[2],
+			value:     strings.TrimSpace(m[3]),
+			start:     i,
+			end:       i + 1,
+		})
+		entry = &section.entries[len(section.entries)-1]
+	}
+	section.end = len(lines)
+
+	if file.delimiter == "" {
+		file.delimiter = " = "
+	}
+	if file.indent == "" {
+		file.indent = iniContinuationIndent
+	}
+	return file, nil
+}
+
+// iniValues splits data into top-level keys and sections.
+func iniValues(data any) (map[string]string, map[string]map[string]string, error) {
+	root := map[string]string{}
+	sections := map[string]map[string]string{}
+	if data == nil {
+		return root, sections, nil
+	}
+	m, err := cast.ToStringMapE(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ini encode: unable to cast: %#v", data)
+	}
+	for k, v := range m {
+		if _, ok := v.(map[string]any); ok {
+			table := cast.ToStringMap(v)
+			sections[k] = map[string]string{}
+			for tk, tv := range table {
+				s, err := cast.ToStringE(tv)
+				if err != nil {
+					return nil, nil, fmt.Errorf("ini encode: unable to cast %s.%s: %#v", k, tk, tv)
+				}
+				sections[k][tk] = s
+			}
+			continue
+		}
+		s, err := cast.ToStringE(v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("ini encode: unable to cast %s: %#v", k, v)
+		}
+		root[k] = s
+	}
+	return root, sections, nil
+}
+
+func formatINISection(name string, values map[string]string) string {
+	s := "[" + name + "]\n"
+	for _, key := range sortedKeys(values) {
+		s += formatINIEntry(key, " = ", values[key], iniContinuationIndent)
+	}
+	return s
+}
+
+// formatINIEntry returns the line(s) for key. Multi-line values
+// are written as indented continuation lines.
+func formatINIEntry(key string, delimiter string, value string, indent string) string {
+	if !strings.Contains(value, "\n") {
+		return key + delimiter + value + "\n"
+	}
+	s := key + strings.TrimRight(delimiter, " \t") + "\n"
+	for _, line := range strings.Split(value, "\n") {
+		s += indent + line + "\n"
+	}
+	return s
+}
@@ -0,0 +1,104 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestINIEncoder_Patch_RoundTrip(t *testing.T) {
+	original := "; Service config.\n" +
+		"name = api\n" +
+		"\n" +
+		"[server]\n" +
+		"host = 0.0.0.0\n" +
+		"port = 8080 ; the default port\n" +
+		"\n" +
+		"[options]\n" +
+		"features =\n" +
+		"    auth\n" +
+		"    metrics\n"
+
+	tests := []struct {
+		name string
+		edit func(data map[string]any)
+		want string
+	}{
+		{
+			name: "leaves unchanged content byte-identical",
+			edit: func(data map[string]any) {},
+			want: original,
+		},
+		{
+			name: "updates a single key",
+			edit: func(data map[string]any) {
+				data["server"].(map[string]any)["host"] = "localhost"
+			},
+			want: "; Service config.\n" +
+				"name = api\n" +
+				"\n" +
+				"[server]\n" +
+				"host = localhost\n" +
+				"port = 8080 ; the default port\n" +
+				"\n" +
+				"[options]\n" +
+				"features =\n" +
+				"    auth\n" +
+				"    metrics\n",
+		},
+		{
+			name: "keeps comments around updated keys",
+			edit: func(data map[string]any) {
+				data["name"] = "web"
+			},
+			want: "; Service config.\n" +
+				"name = web\n" +
+				"\n" +
+				"[server]\n" +
+				"host = 0.0.0.0\n" +
+				"port = 8080 ; the default port\n" +
+				"\n" +
+				"[options]\n" +
+				"features =\n" +
+				"    auth\n" +
+				"    metrics\n",
+		},
+		{
+			name: "appends keys, continuation lines, and sections",
+			edit: func(data map[string]any) {
+				data["server"].(map[string]any)["tls"] = "true"
+				options := data["options"].(map[string]any)
+				options["features"] = options["features"].(string) + "\ntracing"
+				data["logging"] = map[string]any{"level": "info"}
+			},
+			want: "; Service config.\n" +
+				"name = api\n" +
+				"\n" +
+				"[server]\n" +
+				"host = 0.0.0.0\n" +
+				"port = 8080 ; the default port\n" +
+				"tls = true\n" +
+				"\n" +
+				"[options]\n" +
+				"features =\n" +
+				"    auth\n" +
+				"    metrics\n" +
+				"    tracing\n" +
+				"\n" +
+				"[logging]\n" +
+				"level = info\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &INIEncoder{}
+			data, err := e.Decode([]byte(original))
+			assert.NoError(t, err)
+			tt.edit(data.(map[string]any))
+
+			got, err := e.Patch([]byte(original), data)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
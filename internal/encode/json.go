@@ -1,20 +1,33 @@
 package encode
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/ohler55/ojg/oj"
+	"github.com/tailscale/hujson"
 )
 
 var _ Encoder = &JSONEncoder{}
+var _ Patcher = &JSONEncoder{}
+
+const jsonIndent = "    "
 
 type JSONEncoder struct {
 }
 
 // Decode deserializes the given JSON encoded byte array into a data structure.
+// Comments and trailing commas (i.e. JSONC) are allowed.
 func (e *JSONEncoder) Decode(encoded []byte) (any, error) {
-	data, err := oj.Parse(encoded)
+	standard, err := hujson.Standardize(bytes.Clone(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("json decode: %w", err)
+	}
+	data, err := oj.Parse(standard)
 	if err != nil {
 		return nil, fmt.Errorf("json decode: %w", err)
 	}
@@ -25,9 +38,310 @@ func (e *JSONEncoder) Decode(encoded []byte) (any, error) {
 func (e *JSONEncoder) Encode(data any) ([]byte, error) {
 	// Note: using standard lib to marshal because it sorts JSON object keys
 	// (oj does not and it looks ugly when adding new keys).
-	content, err := json.MarshalIndent(data, "", "    ")
+	content, err := json.MarshalIndent(data, "", jsonIndent)
 	if err != nil {
 		return nil, fmt.Errorf("json encode: %w", err)
 	}
 	return content, nil
 }
+
+// Patch serializes the given data structure by updating the syntax tree
+// of the original JSON (or JSONC) document. Comments, key order,
+// indentation, and the trailing newline are preserved.
+func (e *JSONEncoder) Patch(original []byte, data any) ([]byte, error) {
+	root, err := hujson.Parse(original)
+	if err != nil {
+		return nil, fmt.Errorf("json decode: %w", err)
+	}
+	// Documents without any whitespace (other than an empty object or array)
+	// should stay that way.
+	minimized := root.Clone()
+	minimized.Minimize()
+	trimmed := bytes.TrimSpace(original)
+	p := &jsonPatcher{
+		compact: len(trimmed) > 2 && len(minimized.Pack()) == len(trimmed),
+		indent:  detectJSONIndent(original),
+	}
+	if err := p.patch(&root, data, 0); err != nil {
+		return nil, fmt.Errorf("json encode: %w", err)
+	}
+	return root.Pack(), nil
+}
+
+// detectJSONIndent returns the whitespace used to indent the first
+// nested line of the encoded document (defaulting to four spaces).
+func detectJSONIndent(encoded []byte) string {
+	for _, line := range bytes.Split(encoded, []byte("\n"))[1:] {
+		trimmed := bytes.TrimLeft(line, " \t")
+		if len(trimmed) > 0 && len(trimmed) < len(line) {
+			return string(line[:len(line)-len(trimmed)])
+		}
+	}
+	return jsonIndent
+}
+
+type jsonPatcher struct {
+	compact bool // the original document has no whitespace
+	indent  string
+}
+
+// patch updates v to represent data.
+// The value is left as-is if it already represents data.
+func (p *jsonPatcher) patch(v *hujson.Value, data any, depth int) error {
+	current, err := decodeJSONValue(*v)
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(current, data) {
+		return nil
+	}
+
+	switch d := data.(type) {
+	case map[string]any:
+		if obj, ok := v.Value.(*hujson.Object); ok {
+			return p.patchObject(obj, d, depth)
+		}
+	case []any:
+		if arr, ok := v.Value.(*hujson.Array); ok {
+			return p.patchArray(arr, d, depth)
+		}
+	}
+
+	created, err := p.newValue(data, depth)
+	if err != nil {
+		return err
+	}
+	v.Value = created.Value
+	return nil
+}
+
+// patchObject updates the members of obj to match data.
+// Existing members keep their order and new members are appended (sorted).
+func (p *jsonPatcher) patchObject(obj *hujson.Object, data map[string]any, depth int) error {
+	trailingComma := hasTrailingComma(obj.Members)
+	names := make([]*hujson.Value, len(obj.Members))
+	for i := range obj.Members {
+		names[i] = &obj.Members[i].Name
+	}
+	first, separator := p.separators(names, depth)
+
+	members := []hujson.ObjectMember{}
+	existing := map[string]bool{}
+	for _, member := range obj.Members {
+		name := member.Name.Value.(hujson.Literal).String()
+		existing[name] = true
+		item, found := data[name]
+		if !found {
+			continue // removed
+		}
+		if err := p.patch(&member.Value, item, depth+1); err != nil {
+			return err
+		}
+		members = append(members, member)
+	}
+
+	keys := []string{}
+	for key := range data {
+		if !existing[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value, err := p.newValue(data[key], depth+1)
+		if err != nil {
+			return err
+		}
+		value.BeforeExtra = p.colonSeparator(obj.Members)
+		members = append(members, hujson.ObjectMember{
+			Name:  hujson.Value{Value: hujson.String(key)},
+			Value: value,
+		})
+	}
+
+	if len(obj.Members) == 0 && len(members) > 0 && !p.compact {
+		obj.AfterExtra = p.newline(depth)
+	}
+	obj.Members = members
+	for i := range members {
+		setSeparator(&members[i].Name, i, first, separator)
+	}
+	if len(members) > 0 {
+		setTrailingComma(&members[len(members)-1].Value, trailingComma)
+	}
+	return nil
+}
+
+// patchArray updates the elements of arr to match data.
+// Unchanged elements are reused (in order) so that their comments are preserved.
+func (p *jsonPatcher) patchArray(arr *hujson.Array, data []any, depth int) error {
+	trailingComma := hasTrailingComma(arr.Elements)
+	values := make([]*hujson.Value, len(arr.Elements))
+	for i := range arr.Elements {
+		values[i] = &arr.Elements[i]
+	}
+	first, separator := p.separators(values, depth)
+
+	elements := []hujson.Value{}
+	if len(arr.Elements) == len(data) {
+		// Same length: update each element in place.
+		for i, elem := range arr.Elements {
+			if err := p.patch(&elem, data[i], depth+1); err != nil {
+				return err
+			}
+			elements = append(elements, elem)
+		}
+	} else {
+		// Elements were added or removed: reuse matching elements.
+		decoded := make([]any, len(arr.Elements))
+		for i, elem := range arr.Elements {
+			var err error
+			if decoded[i], err = decodeJSONValue(elem); err != nil {
+				return err
+			}
+		}
+		next := 0
+		for _, item := range data {
+			found := false
+			for j := next; j < len(arr.Elements); j++ {
+				if reflect.DeepEqual(decoded[j], item) {
+					elements = append(elements, arr.Elements[j])
+					next = j + 1
+					found = true
+					break
+				}
+			}
+			if found {
+				continue
+			}
+			value, err := p.newValue(item, depth+1)
+			if err != nil {
+				return err
+			}
+			elements = append(elements, value)
+		}
+	}
+
+	if len(arr.Elements) == 0 && len(elements) > 0 && !p.compact {
+		arr.AfterExtra = p.newline(depth)
+	}
+	arr.Elements = elements
+	for i := range elements {
+		setSeparator(&elements[i], i, first, separator)
+	}
+	if len(elements) > 0 {
+		setTrailingComma(&elements[len(elements)-1], trailingComma)
+	}
+	return nil
+}
+
+// separators returns the whitespace used before the first (and subsequent)
+// of values, falling back to one value per line when values is empty.
+func (p *jsonPatcher) separators(values []*hujson.Value, depth int) (hujson.Extra, hujson.Extra) {
+	if p.compact {
+		return nil, nil
+	}
+	switch len(values) {
+	case 0:
+		return p.newline(depth + 1), p.newline(depth + 1)
+	case 1:
+		first := whitespace(values[0].BeforeExtra)
+		if bytes.Contains(first, []byte("\n")) {
+			return first, first
+		}
+		return first, hujson.Extra(" ")
+	default:
+		return whitespace(values[0].BeforeExtra), whitespace(values[len(values)-1].BeforeExtra)
+	}
+}
+
+// colonSeparator returns the whitespace used after the colon of members.
+func (p *jsonPatcher) colonSeparator(members []hujson.ObjectMember) hujson.Extra {
+	if p.compact {
+		return nil
+	}
+	if n := len(members); n > 0 {
+		return whitespace(members[n-1].Value.BeforeExtra)
+	}
+	return hujson.Extra(" ")
+}
+
+// newline returns a newline followed by the indentation for depth.
+func (p *jsonPatcher) newline(depth int) hujson.Extra {
+	return hujson.Extra("\n" + strings.Repeat(p.indent, depth))
+}
+
+// newValue returns a new value representing data (indented for depth).
+func (p *jsonPatcher) newValue(data any, depth int) (hujson.Value, error) {
+	if p.compact {
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			return hujson.Value{}, err
+		}
+		return hujson.Parse(encoded)
+	}
+	encoded, err := json.MarshalIndent(data, strings.Repeat(p.indent, depth), p.indent)
+	if err != nil {
+		return hujson.Value{}, err
+	}
+	return hujson.Parse(encoded)
+}
+
+// whitespace returns the trailing whitespace in extra (i.e. after any comments).
+func whitespace(extra hujson.Extra) hujson.Extra {
+	i := len(extra)
+	for i > 0 && bytes.IndexByte([]byte(" \t\r\n"), extra[i-1]) >= 0 {
+		i--
+	}
+	if i > 0 {
+		// Comments should be followed by a newline, so only keep the last line.
+		if j := bytes.LastIndexByte(extra[i:], '\n'); j >= 0 {
+			return bytes.Clone(extra[i+j:])
+		}
+	}
+	return bytes.Clone(extra[i:])
+}
+
+// setSeparator sets the whitespace before the value at index i
+// (unless it is preceded by comments).
+func setSeparator(v *hujson.Value, i int, first hujson.Extra, separator hujson.Extra) {
+	if len(bytes.TrimSpace(v.BeforeExtra)) > 0 {
+		return
+	}
+	if i == 0 {
+		v.BeforeExtra = bytes.Clone(first)
+	} else {
+		v.BeforeExtra = bytes.Clone(separator)
+	}
+}
+
+// decodeJSONValue deserializes v into a data structure.
+func decodeJSONValue(v hujson.Value) (any, error) {
+	clone := v.Clone()
+	clone.Standardize()
+	return oj.Parse(clone.Pack())
+}
+
+// hasTrailingComma returns true if the last of values is followed by a comma.
+func hasTrailingComma[T hujson.Value | hujson.ObjectMember](values []T) bool {
+	if len(values) == 0 {
+		return false
+	}
+	switch last := any(values[len(values)-1]).(type) {
+	case hujson.ObjectMember:
+		return last.Value.AfterExtra != nil
+	case hujson.Value:
+		return last.AfterExtra != nil
+	}
+	return false
+}
+
+// setTrailingComma adds (or removes) the comma following the last value.
+func setTrailingComma(last *hujson.Value, comma bool) {
+	switch {
+	case comma && last.AfterExtra == nil:
+		last.AfterExtra = hujson.Extra{}
+	case !comma && len(last.AfterExtra) == 0:
+		last.AfterExtra = nil
+	}
+}
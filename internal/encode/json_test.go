@@ -0,0 +1,96 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONEncoder_Patch_RoundTrip(t *testing.T) {
+	original := "{\n" +
+		"  // The service name.\n" +
+		"  \"name\": \"api\",\n" +
+		"  \"server\": {\"host\": \"0.0.0.0\", \"port\": 8080},\n" +
+		"  \"features\": [\n" +
+		"    \"auth\", // required\n" +
+		"    \"metrics\"\n" +
+		"  ],\n" +
+		"  \"debug\": false,\n" +
+		"}\n"
+
+	tests := []struct {
+		name string
+		edit func(data map[string]any)
+		want string
+	}{
+		{
+			name: "leaves unchanged content byte-identical",
+			edit: func(data map[string]any) {},
+			want: original,
+		},
+		{
+			name: "updates a single key",
+			edit: func(data map[string]any) {
+				data["server"].(map[string]any)["port"] = 9090
+			},
+			want: "{\n" +
+				"  // The service name.\n" +
+				"  \"name\": \"api\",\n" +
+				"  \"server\": {\"host\": \"0.0.0.0\", \"port\": 9090},\n" +
+				"  \"features\": [\n" +
+				"    \"auth\", // required\n" +
+				"    \"metrics\"\n" +
+				"  ],\n" +
+				"  \"debug\": false,\n" +
+				"}\n",
+		},
+		{
+			name: "keeps comments on updated keys",
+			edit: func(data map[string]any) {
+				data["name"] = "web"
+			},
+			want: "{\n" +
+				"  // The service name.\n" +
+				"  \"name\": \"web\",\n" +
+				"  \"server\": {\"host\": \"0.0.0.0\", \"port\": 8080},\n" +
+				"  \"features\": [\n" +
+				"    \"auth\", // required\n" +
+				"    \"metrics\"\n" +
+				"  ],\n" +
+				"  \"debug\": false,\n" +
+				"}\n",
+		},
+		{
+			name: "appends keys and items",
+			edit: func(data map[string]any) {
+				data["server"].(map[string]any)["tls"] = true
+				data["features"] = append(data["features"].([]any), "tracing")
+				data["version"] = "1.0"
+			},
+			want: "{\n" +
+				"  // The service name.\n" +
+				"  \"name\": \"api\",\n" +
+				"  \"server\": {\"host\": \"0.0.0.0\", \"port\": 8080, \"tls\": true},\n" +
+				"  \"features\": [\n" +
+				"    \"auth\", // required\n" +
+				"    \"metrics\",\n" +
+				"    \"tracing\"\n" +
+				"  ],\n" +
+				"  \"debug\": false,\n" +
+				"  \"version\": \"1.0\",\n" +
+				"}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &JSONEncoder{}
+			data, err := e.Decode([]byte(original))
+			assert.NoError(t, err)
+			tt.edit(data.(map[string]any))
+
+			got, err := e.Patch([]byte(original), data)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package encode
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrUnknownFormat = errors.New("undefined format")
+	formats          = map[string]Format{}
+	formatsMu        sync.RWMutex
+)
+
+// The built-in formats. For backward compatibility, dotenv, ini, markdown,
+// and go register no extensions (files with those extensions were
+// always written as-is, and still are unless a content type is set).
+func init() {
+	RegisterFormat(Format{
+		Name:        "json",
+		Description: "JSON (or JSONC) data.",
+		Extensions:  []string{"json", "jsonc"},
+		Structured:  true,
+		Encoder:     &JSONEncoder{},
+	})
+	RegisterFormat(Format{
+		Name:        "yaml",
+		Description: "YAML data.",
+		Extensions:  []string{"yaml", "yml"},
+		Structured:  true,
+		Encoder:     &YAMLEncoder{},
+	})
+	RegisterFormat(Format{
+		Name:        "toml",
+		Description: "TOML data.",
+		Extensions:  []string{"toml"},
+		Structured:  true,
+		Encoder:     &TOMLEncoder{},
+	})
+	RegisterFormat(Format{
+		Name:        "xml",
+		Description: "XML documents.",
+		Extensions:  []string{"xml", "csproj", "fsproj", "vbproj"},
+		Encoder:     &XMLEncoder{},
+	})
+	RegisterFormat(Format{
+		// Not inferred from `.env` (or `.ini` and `.cfg`, below)
+		// so that existing files continue to be written as-is.
+		Name:        "dotenv",
+		Description: "Environment variable files, i.e. `.env` (must be set explicitly).",
+		Structured:  true,
+		Encoder:     &DotenvEncoder{},
+	})
+	RegisterFormat(Format{
+		Name:        "ini",
+		Description: "INI files, i.e. `setup.cfg` (must be set explicitly).",
+		Structured:  true,
+		Encoder:     &INIEncoder{},
+	})
+	RegisterFormat(Format{
+		// Not inferred from the `.md` extension so that regexp patterns
+		// (i.e. for text content) continue to work with existing files.
+		Name:        "markdown",
+		Description: "Markdown documents (must be set explicitly).",
+		Encoder:     &TextEncoder{},
+	})
+	RegisterFormat(Format{
+		// Not inferred from the `.go` extension for the same reason.
+		Name:        "go",
+		Description: "Go source files (must be set explicitly).",
+		Encoder:     &TextEncoder{},
+	})
+	RegisterFormat(Format{
+		Name:        "text",
+		Description: "Plain text.",
+		Encoder:     &TextEncoder{},
+	})
+}
+
+// Format is a named file type and the encoder used to read and write it.
+type Format struct {
+	Name        string
+	Description string
+	// File extensions (without the leading dot) that use this format.
+	// Formats without extensions are only used when set explicitly.
+	Extensions []string
+	// Structured formats are decoded into maps and slices
+	// (and may be targeted with JSON path expressions).
+	Structured bool
+	Encoder    Encoder
+}
+
+// GetFormat returns the format registered for name.
+// If name is not found, returns ErrUnknownFormat.
+func GetFormat(name string) (Format, error) {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	if f, ok := formats[name]; ok {
+		return f, nil
+	}
+	return Format{}, ErrUnknownFormat
+}
+
+// GetFormatForPath returns the format registered for the extension of path.
+// If none is found, returns ErrUnknownFormat.
+func GetFormatForPath(path string) (Format, error) {
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	for _, f := range RegisteredFormats() {
+		for _, e := range f.Extensions {
+			if e == ext {
+				return f, nil
+			}
+		}
+	}
+	return Format{}, ErrUnknownFormat
+}
+
+// RegisterFormat adds f to the registry.
+// Panics if a format has already been registered with the same name.
+func RegisterFormat(f Format) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	if _, ok := formats[f.Name]; ok {
+		panic("format already registered for name: " + f.Name)
+	}
+	formats[f.Name] = f
+}
+
+// UnregisterFormat removes f from the registry.
+func UnregisterFormat(f Format) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	delete(formats, f.Name)
+}
+
+// RegisteredFormats returns all registered formats, sorted by name.
+func RegisteredFormats() []Format {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	fs := []Format{}
+	for _, f := range formats {
+		fs = append(fs, f)
+	}
+	sort.Slice(fs, func(i, j int) bool {
+		return fs[i].Name < fs[j].Name
+	})
+	return fs
+}
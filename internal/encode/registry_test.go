@@ -0,0 +1,50 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetFormatForPath(t *testing.T) {
+	for path, want := range map[string]string{
+		"package.json":      "json",
+		"tsconfig.jsonc":    "json",
+		"config.YML":        "yaml",
+		"Cargo.toml":        "toml",
+		"app.csproj":        "xml",
+		"dir/settings.yaml": "yaml",
+	} {
+		f, err := GetFormatForPath(path)
+		assert.NoError(t, err, path)
+		assert.Equal(t, want, f.Name, path)
+	}
+
+	// Never inferred (for backward compatibility), so must be set explicitly.
+	for _, path := range []string{".env", "setup.cfg", "tox.ini", "README.md", "main.go", "notes.txt"} {
+		_, err := GetFormatForPath(path)
+		assert.ErrorIs(t, err, ErrUnknownFormat, path)
+	}
+}
+
+func TestRegisterFormat(t *testing.T) {
+	f := Format{Name: "custom", Extensions: []string{"custom"}, Encoder: &TextEncoder{}}
+	RegisterFormat(f)
+	defer UnregisterFormat(f)
+
+	got, err := GetFormat("custom")
+	assert.NoError(t, err)
+	assert.Equal(t, "custom", got.Name)
+	got, err = GetFormatForPath("file.custom")
+	assert.NoError(t, err)
+	assert.Equal(t, "custom", got.Name)
+	assert.Contains(t, RegisteredFormats(), got)
+
+	assert.PanicsWithValue(t, "format already registered for name: custom", func() {
+		RegisterFormat(f)
+	})
+
+	UnregisterFormat(f)
+	_, err = GetFormat("custom")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
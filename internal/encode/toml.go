@@ -0,0 +1,1189 @@
+package encode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+)
+
+var _ Encoder = &TOMLEncoder{}
+var _ Patcher = &TOMLEncoder{}
+
+type TOMLEncoder struct {
+}
+
+// Decode deserializes the given TOML encoded byte array into a data structure.
+func (e *TOMLEncoder) Decode(encoded []byte) (any, error) {
+	data := map[string]any{}
+	err := toml.Unmarshal(encoded, &data)
+	if err != nil {
+		return nil, fmt.Errorf("toml decode: %w", err)
+	}
+	return data, nil
+}
+
+// Encode serializes the given data structure into a TOML encoded byte array.
+// Keys are sorted so that output is stable.
+func (e *TOMLEncoder) Encode(data any) ([]byte, error) {
+	return e.encode(data, nil)
+}
+
+// Patch serializes the given data structure by editing only the spans
+// of the original TOML document that represent changed keys and tables.
+// Comments, blank lines, key/table order, and the quoting style of strings
+// are preserved (including within multi-line arrays and inline tables).
+// New keys are appended to their table (sorted), new [[array]] table items
+// after the existing ones, and new tables to the end of the document.
+func (e *TOMLEncoder) Patch(original []byte, data any) ([]byte, error) {
+	// Normalize the data to the types produced by Decode,
+	// so that values can be compared to the original.
+	encoded, err := e.Encode(data)
+	if err != nil {
+		return nil, err
+	}
+	normalized, err := e.Decode(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("toml encode: %w", err)
+	}
+
+	doc, err := parseTOMLDocument(original)
+	if err != nil {
+		return nil, fmt.Errorf("toml decode: %w", err)
+	}
+	current, err := e.Decode(original)
+	if err != nil {
+		return nil, err
+	}
+	if patched, err := doc.patch(current.(map[string]any), normalized.(map[string]any)); err == nil {
+		// Only use the patched document if it round trips
+		// (some layouts, i.e. dotted keys spread across tables, are not supported).
+		if decoded, err := e.Decode(patched); err == nil && reflect.DeepEqual(decoded, normalized) {
+			return patched, nil
+		}
+	}
+
+	// Otherwise, re-encode the document in its original key order.
+	order, err := tomlKeyOrder(original)
+	if err != nil {
+		return nil, fmt.Errorf("toml decode: %w", err)
+	}
+	return e.encode(data, order)
+}
+
+func (e *TOMLEncoder) encode(data any, order map[string][]string) ([]byte, error) {
+	table, ok := data.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("toml encode: expected a table, got %T", data)
+	}
+	w := &tomlWriter{order: order}
+	if err := w.writeTable(nil, table, false); err != nil {
+		return nil, fmt.Errorf("toml encode: %w", err)
+	}
+	return w.buf.Bytes(), nil
+}
+
+// tomlKeyOrder returns the order of the keys in each table
+// of the encoded document (keyed by the joined table path).
+func tomlKeyOrder(encoded []byte) (map[string][]string, error) {
+	order := map[string][]string{}
+	seen := map[string]bool{}
+	add := func(path []string) {
+		for i := range path {
+			parent := tomlPathKey(path[:i])
+			if child := tomlPathKey(path[:i+1]); !seen[child] {
+				seen[child] = true
+				order[parent] = append(order[parent], path[i])
+			}
+		}
+	}
+
+	p := unstable.Parser{}
+	p.Reset(encoded)
+	table := []string{}
+	for p.NextExpression() {
+		expr := p.Expression()
+		switch expr.Kind { //nolint:exhaustive
+		case unstable.Table, unstable.ArrayTable:
+			table = tomlKeys(expr.Key())
+			add(table)
+		case unstable.KeyValue:
+			add(append(append([]string{}, table...), tomlKeys(expr.Key())...))
+		}
+	}
+	return order, p.Error()
+}
+
+func tomlKeys(it unstable.Iterator) []string {
+	keys := []string{}
+	for it.Next() {
+		keys = append(keys, string(it.Node().Data))
+	}
+	return keys
+}
+
+func tomlPathKey(path []string) string {
+	return strings.Join(path, "\x00")
+}
+
+var tomlBareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// tomlKey returns key, quoted if needed.
+func tomlKey(key string) string {
+	if tomlBareKey.MatchString(key) {
+		return key
+	}
+	// JSON string escapes are valid in TOML basic strings.
+	quoted, _ := json.Marshal(key)
+	return string(quoted)
+}
+
+type tomlWriter struct {
+	buf   bytes.Buffer
+	order map[string][]string
+	quote byte // quote for strings (when unset, the library default is used)
+}
+
+// writeTable writes the key/values of table, followed by any sub-tables.
+func (w *tomlWriter) writeTable(path []string, table map[string]any, isArrayItem bool) error {
+	keys := w.keys(path, table)
+
+	values := []string{}
+	for _, key := range keys {
+		if !isTOMLTable(table[key]) && !isTOMLArrayOfTables(table[key]) {
+			values = append(values, key)
+		}
+	}
+
+	// Tables only containing sub-tables are implicitly defined.
+	if len(path) > 0 && (isArrayItem || len(values) > 0 || len(keys) == 0) {
+		if w.buf.Len() > 0 {
+			w.buf.WriteString("\n")
+		}
+		header := make([]string, len(path))
+		for i, key := range path {
+			header[i] = tomlKey(key)
+		}
+		if isArrayItem {
+			fmt.Fprintf(&w.buf, "[[%s]]\n", strings.Join(header, "."))
+		} else {
+			fmt.Fprintf(&w.buf, "[%s]\n", strings.Join(header, "."))
+		}
+	}
+
+	for _, key := range values {
+		if w.quote != 0 {
+			encoded, err := tomlInlineValue(table[key], w.quote)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(&w.buf, "%s = %s\n", tomlKey(key), encoded)
+			continue
+		}
+		// Let the library handle the encoding of values.
+		encoded, err := toml.Marshal(map[string]any{key: table[key]})
+		if err != nil {
+			return err
+		}
+		w.buf.Write(encoded)
+	}
+
+	for _, key := range keys {
+		subPath := append(append([]string{}, path...), key)
+		switch {
+		case isTOMLTable(table[key]):
+			if err := w.writeTable(subPath, table[key].(map[string]any), false); err != nil {
+				return err
+			}
+		case isTOMLArrayOfTables(table[key]):
+			for _, item := range table[key].([]any) {
+				if err := w.writeTable(subPath, item.(map[string]any), true); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// keys returns the keys of the table at path: those from the original
+// document first (in their original order), followed by any new keys (sorted).
+func (w *tomlWriter) keys(path []string, table map[string]any) []string {
+	keys := []string{}
+	seen := map[string]bool{}
+	for _, key := range w.order[tomlPathKey(path)] {
+		if _, found := table[key]; found && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	added := []string{}
+	for key := range table {
+		if !seen[key] {
+			added = append(added, key)
+		}
+	}
+	sort.Strings(added)
+	return append(keys, added...)
+}
+
+func isTOMLTable(value any) bool {
+	_, ok := value.(map[string]any)
+	return ok
+}
+
+func isTOMLArrayOfTables(value any) bool {
+	items, ok := value.([]any)
+	if !ok || len(items) == 0 {
+		return false
+	}
+	for _, item := range items {
+		if !isTOMLTable(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// tomlDocument describes the layout of an encoded TOML document
+// so that it can be patched in place.
+type tomlDocument struct {
+	src      []byte
+	sections []*tomlSection
+	known    map[string]bool // all keys and tables (including implicit ones)
+	headers  map[string]bool // tables with a [header]
+	values   map[string]bool // keys with a value
+	counts   map[string]int  // number of items in each [[array]] table
+	edits    []tomlEdit
+	items    []tomlEdit // new [[array]] table items (inserted after all other edits)
+}
+
+// tomlSection is the root table, or a [table] or [[array]] table item,
+// along with the key/values directly below it.
+type tomlSection struct {
+	key     string   // qualified path key (see tomlChildKey)
+	path    []string // table path
+	indexes []int    // array table item index for each path element (-1 otherwise)
+	start   int      // offset of the header line
+	end     int      // offset after the last key/value line (or the header)
+	keys    []*tomlKeyValue
+	deleted bool
+}
+
+// tomlKeyValue is a key/value expression.
+type tomlKeyValue struct {
+	path       []string // key path, relative to the section
+	start      int      // offset of the line
+	valueStart int
+	valueEnd   int
+	end        int // offset after the line
+}
+
+// tomlEdit replaces the bytes in [start, end) with text.
+type tomlEdit struct {
+	start int
+	end   int
+	text  string
+}
+
+// parseTOMLDocument returns the layout of the encoded document.
+func parseTOMLDocument(src []byte) (*tomlDocument, error) {
+	doc := &tomlDocument{
+		src:     src,
+		known:   map[string]bool{},
+		headers: map[string]bool{},
+		values:  map[string]bool{},
+		counts:  map[string]int{},
+	}
+	section := &tomlSection{end: -1}
+	doc.sections = append(doc.sections, section)
+
+	// Value spans end at the start of the next expression (or a trailing comment).
+	var pending *tomlKeyValue
+	finish := func(limit int) {
+		if pending == nil {
+			return
+		}
+		pending.valueEnd = pending.valueStart + len(bytes.TrimRight(src[pending.valueStart:limit], " \t\r\n"))
+		if pending.end < pending.valueEnd {
+			pending.end = pending.valueEnd
+		}
+		pending.end = tomlLineEnd(src, pending.end)
+		section.end = pending.end
+		pending = nil
+	}
+
+	p := unstable.Parser{KeepComments: true}
+	p.Reset(src)
+	for p.NextExpression() {
+		expr := p.Expression()
+		if expr.Kind == unstable.Comment {
+			finish(tomlLineStart(src, int(expr.Raw.Offset)))
+			continue
+		}
+
+		keys := []string{}
+		start, keyEnd := -1, 0
+		it := expr.Key()
+		for it.Next() {
+			node := it.Node()
+			if start < 0 {
+				start = int(node.Raw.Offset)
+			}
+			keyEnd = int(node.Raw.Offset + node.Raw.Length)
+			keys = append(keys, string(node.Data))
+		}
+		finish(tomlLineStart(src, start))
+
+		switch expr.Kind { //nolint:exhaustive
+		case unstable.Table, unstable.ArrayTable:
+			section = &tomlSection{
+				start: tomlLineStart(src, start),
+				end:   tomlLineEnd(src, keyEnd),
+				path:  keys,
+			}
+			for i, name := range keys {
+				section.key = tomlChildKey(section.key, name)
+				doc.known[section.key] = true
+				index := -1
+				if i == len(keys)-1 && expr.Kind == unstable.ArrayTable {
+					index = doc.counts[section.key]
+					doc.counts[section.key]++
+				} else if n, ok := doc.counts[section.key]; ok {
+					index = n - 1
+				}
+				section.indexes = append(section.indexes, index)
+				if index >= 0 {
+					section.key = tomlChildKey(section.key, fmt.Sprintf("#%d", index))
+				}
+			}
+			doc.headers[section.key] = true
+			doc.sections = append(doc.sections, section)
+		case unstable.KeyValue:
+			kv := &tomlKeyValue{
+				path:       keys,
+				start:      tomlLineStart(src, start),
+				valueStart: keyEnd,
+			}
+			// Skip the separator.
+			for kv.valueStart < len(src) && bytes.IndexByte([]byte(" \t="), src[kv.valueStart]) >= 0 {
+				kv.valueStart++
+			}
+			key := section.key
+			for _, name := range keys {
+				key = tomlChildKey(key, name)
+				doc.known[key] = true
+			}
+			doc.values[key] = true
+			section.keys = append(section.keys, kv)
+			pending = kv
+			if comment := expr.Next(); comment != nil && comment.Kind == unstable.Comment {
+				kv.end = int(comment.Raw.Offset + comment.Raw.Length)
+				finish(int(comment.Raw.Offset))
+			}
+		}
+	}
+	finish(len(src))
+	if err := p.Error(); err != nil {
+		return nil, err
+	}
+
+	// New root keys go before any leading table (and its comments).
+	if root := doc.sections[0]; root.end < 0 {
+		root.end = len(src)
+		if len(doc.sections) > 1 {
+			root.end = tomlHeadStart(src, doc.sections[1].start)
+		}
+	}
+	return doc, nil
+}
+
+// patch returns the document updated to represent data.
+func (d *tomlDocument) patch(current, data map[string]any) ([]byte, error) {
+	// Remove tables and keys that are no longer present.
+	trailing := len(d.sections)
+	for i, section := range d.sections {
+		value, found := tomlLookup(data, section.path, section.indexes)
+		section.deleted = i > 0 && (!found || !isTOMLTable(value))
+		if !section.deleted {
+			trailing = i + 1
+		}
+	}
+	for i, section := range d.sections {
+		if section.deleted {
+			d.deleteSection(section, i == trailing)
+		}
+	}
+
+	// Update changed values.
+	for _, section := range d.sections {
+		if section.deleted {
+			continue
+		}
+		for _, kv := range section.keys {
+			path := append(append([]string{}, section.path...), kv.path...)
+			indexes := append(append([]int{}, section.indexes...), make([]int, len(kv.path))...)
+			for i := len(section.indexes); i < len(indexes); i++ {
+				indexes[i] = -1
+			}
+			value, found := tomlLookup(data, path, indexes)
+			if !found {
+				d.edit(tomlHeadStart(d.src, kv.start), kv.end, "")
+				continue
+			}
+			original, _ := tomlLookup(current, path, indexes)
+			if err := d.patchValue(kv.valueStart, kv.valueEnd, original, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Add new keys (to their table) and new tables (to the end of the document).
+	// New strings are quoted the same way as the existing ones.
+	quote := d.quote(d.sections...)
+	if quote == 0 {
+		quote = '"'
+	}
+	appended := &tomlWriter{quote: quote}
+	for _, section := range d.sections {
+		if section.deleted {
+			continue
+		}
+		value, _ := tomlLookup(data, section.path, section.indexes)
+		inserted := &tomlWriter{quote: quote}
+		lines := []string{}
+		sectionQuote := d.quote(section)
+		if sectionQuote == 0 {
+			sectionQuote = quote
+		}
+		err := d.addKeys(section, nil, value.(map[string]any), sectionQuote, &lines, inserted, appended)
+		if err != nil {
+			return nil, err
+		}
+		if len(lines) > 0 {
+			text := strings.Join(lines, "\n") + "\n"
+			if section.path == nil && len(section.keys) == 0 && len(d.sections) > 1 {
+				text += "\n" // separate from the first table
+			}
+			d.insert(section.end, text)
+		}
+		if inserted.buf.Len() > 0 {
+			d.insert(section.end, "\n"+inserted.buf.String())
+		}
+	}
+	if appended.buf.Len() > 0 {
+		d.insert(len(d.src), "\n"+appended.buf.String())
+	}
+	// Items go after any keys and sub-tables added to the last existing item.
+	for _, item := range d.items {
+		d.insert(item.start, item.text)
+	}
+
+	return d.apply(), nil
+}
+
+// addKeys collects the keys in table that are not in the document.
+// Keys are added to section as (dotted) key/value lines,
+// while tables are written to either inserted (when nested in an array table item,
+// and must therefore follow the section) or appended.
+func (d *tomlDocument) addKeys(
+	section *tomlSection, rel []string, table map[string]any, quote byte, lines *[]string,
+	inserted, appended *tomlWriter,
+) error {
+	parentKey := section.key
+	for _, name := range rel {
+		parentKey = tomlChildKey(parentKey, name)
+	}
+	nested := false
+	for _, index := range section.indexes {
+		nested = nested || index >= 0
+	}
+
+	keys := []string{}
+	for key := range table {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := table[key]
+		path := append(append([]string{}, rel...), key)
+		fullPath := append(append([]string{}, section.path...), path...)
+		childKey := tomlChildKey(parentKey, key)
+
+		if count, ok := d.counts[childKey]; ok {
+			// An [[array]] table: add any new items after the existing ones.
+			items, _ := value.([]any)
+			if len(items) <= count {
+				continue
+			}
+			if nested {
+				return fmt.Errorf("unable to add items to nested array table %s", key)
+			}
+			w := &tomlWriter{quote: appended.quote}
+			for i := count; i < len(items); i++ {
+				if err := w.writeTable(fullPath, items[i].(map[string]any), true); err != nil {
+					return err
+				}
+			}
+			d.items = append(d.items, tomlEdit{start: d.itemsEnd(childKey, count), text: "\n" + w.buf.String()})
+			continue
+		}
+		if d.known[childKey] && (isTOMLTable(value) || !d.headers[childKey]) {
+			if sub, ok := value.(map[string]any); ok && !d.headers[childKey] && !d.values[childKey] {
+				// Implicit or dotted key table: add any new keys to this section.
+				if err := d.addKeys(section, path, sub, quote, lines, inserted, appended); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		switch {
+		case isTOMLTable(value), isTOMLArrayOfTables(value):
+			w := appended
+			if nested {
+				w = inserted
+			}
+			isArray := isTOMLArrayOfTables(value)
+			items := []any{value}
+			if isArray {
+				items = value.([]any)
+			}
+			for _, item := range items {
+				if err := w.writeTable(fullPath, item.(map[string]any), isArray); err != nil {
+					return err
+				}
+			}
+		default:
+			text, err := tomlInlineValue(value, quote)
+			if err != nil {
+				return err
+			}
+			dotted := make([]string, len(path))
+			for i, name := range path {
+				dotted[i] = tomlKey(name)
+			}
+			*lines = append(*lines, strings.Join(dotted, ".")+" = "+text)
+		}
+	}
+	return nil
+}
+
+// itemsEnd returns the offset after the last of the count items
+// (including their sub-tables) of the [[array]] table at key.
+func (d *tomlDocument) itemsEnd(key string, count int) int {
+	last := tomlChildKey(key, fmt.Sprintf("#%d", count-1))
+	end := -1
+	for _, section := range d.sections {
+		if !section.deleted && (section.key == last || strings.HasPrefix(section.key, last+"\x00")) {
+			end = max(end, section.end)
+		}
+	}
+	if end < 0 {
+		return len(d.src)
+	}
+	return end
+}
+
+// patchValue records the edits needed for the value in [start, end)
+// to represent value (original being the value it currently represents).
+// Arrays and inline tables are patched item by item, so that their layout,
+// comments, and key order are preserved.
+func (d *tomlDocument) patchValue(start, end int, original, value any) error {
+	if reflect.DeepEqual(original, value) {
+		return nil
+	}
+	switch v := value.(type) {
+	case []any:
+		if items, ok := original.([]any); ok {
+			if patched, err := d.patchArray(start, end, items, v); patched || err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		if table, ok := original.(map[string]any); ok {
+			if patched, err := d.patchInlineTable(start, end, table, v); patched || err != nil {
+				return err
+			}
+		}
+	}
+	text, err := tomlValue(value, string(d.src[start:end]))
+	if err != nil {
+		return err
+	}
+	d.edit(start, end, text)
+	return nil
+}
+
+// patchArray records the edits needed for the array in [start, end)
+// to represent value. It returns false if the array has to be replaced as a whole.
+func (d *tomlDocument) patchArray(start, end int, original, value []any) (bool, error) {
+	items, ok := tomlArrayItems(d.src, start, end)
+	if !ok || len(items) != len(original) || len(items) == 0 {
+		return false, nil
+	}
+	if len(items) == len(value) {
+		// Same length: update each item in place.
+		for i, item := range items {
+			if err := d.patchValue(item.start, item.end, original[i], value[i]); err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+
+	// Items were added or removed. Only arrays with one item per line
+	// can be updated in place (by adding and removing lines).
+	for _, item := range items {
+		if !item.ownLine {
+			return false, nil
+		}
+	}
+	kept := make([]bool, len(items))
+	pending := []any{}
+	next := 0
+	lastKept := -1
+	for _, v := range value {
+		reused := -1
+		for j := next; j < len(items); j++ {
+			if reflect.DeepEqual(original[j], v) {
+				reused = j
+				break
+			}
+		}
+		if reused < 0 {
+			pending = append(pending, v)
+			continue
+		}
+		if err := d.insertArrayItems(items[reused].lineStart, items[reused].indent, pending, true); err != nil {
+			return false, err
+		}
+		pending = []any{}
+		kept[reused] = true
+		lastKept = reused
+		next = reused + 1
+	}
+	if lastKept < 0 {
+		return false, nil
+	}
+	for i, item := range items {
+		if !kept[i] {
+			d.edit(item.lineStart, item.lineEnd, "")
+		}
+	}
+	if len(pending) > 0 {
+		last := items[lastKept]
+		if last.comma < 0 {
+			d.edit(last.end, last.end, ",")
+		}
+		trailing := items[len(items)-1].comma >= 0
+		if err := d.insertArrayItems(last.lineEnd, last.indent, pending, trailing); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// insertArrayItems inserts values (one per line) at offset.
+// The last value is followed by a comma if trailing is true.
+func (d *tomlDocument) insertArrayItems(offset int, indent string, values []any, trailing bool) error {
+	quote := d.quote(d.sections...)
+	lines := []string{}
+	for i, value := range values {
+		text, err := tomlInlineValue(value, quote)
+		if err != nil {
+			return err
+		}
+		if i < len(values)-1 || trailing {
+			text += ","
+		}
+		lines = append(lines, indent+text+"\n")
+	}
+	if len(lines) > 0 {
+		d.insert(offset, strings.Join(lines, ""))
+	}
+	return nil
+}
+
+// patchInlineTable records the edits needed for the inline table in [start, end)
+// to represent value. Existing keys keep their order and new keys are appended (sorted).
+// It returns false if the table has to be replaced as a whole.
+func (d *tomlDocument) patchInlineTable(start, end int, original, value map[string]any) (bool, error) {
+	entries, ok := tomlInlineEntries(d.src, start, end)
+	if !ok || len(entries) != len(original) {
+		return false, nil
+	}
+	kept := false
+	for _, entry := range entries {
+		_, found := value[entry.key]
+		kept = kept || found
+	}
+	if !kept {
+		return false, nil
+	}
+
+	last := -1
+	for i, entry := range entries {
+		item, found := value[entry.key]
+		if found {
+			if err := d.patchValue(entry.valueStart, entry.valueEnd, original[entry.key], item); err != nil {
+				return false, err
+			}
+			last = i
+			continue
+		}
+		// Remove the entry along with the separator before it
+		// (or after it, when it precedes all of the kept entries).
+		if last >= 0 {
+			d.edit(entries[i-1].valueEnd, entry.valueEnd, "")
+		} else {
+			d.edit(entry.keyStart, entries[i+1].keyStart, "")
+		}
+	}
+
+	keys := []string{}
+	for key := range value {
+		if _, found := original[key]; !found {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	quote := d.quote(d.sections...)
+	added := ""
+	for _, key := range keys {
+		text, err := tomlInlineValue(value[key], quote)
+		if err != nil {
+			return false, err
+		}
+		added += ", " + tomlKey(key) + " = " + text
+	}
+	if added != "" {
+		d.edit(entries[last].valueEnd, entries[last].valueEnd, added)
+	}
+	return true, nil
+}
+
+// quote returns the quote used by the last quoted value in sections
+// (or 0 if there are none).
+func (d *tomlDocument) quote(sections ...*tomlSection) byte {
+	for i := len(sections) - 1; i >= 0; i-- {
+		for j := len(sections[i].keys) - 1; j >= 0; j-- {
+			kv := sections[i].keys[j]
+			value := d.src[kv.valueStart:kv.valueEnd]
+			if k := bytes.IndexAny(value, `"'`); k >= 0 {
+				return value[k]
+			}
+		}
+	}
+	return 0
+}
+
+// deleteSection removes the section (and any blank lines following it).
+// When it is the first of the sections at the end of the document,
+// the blank lines preceding it are removed instead.
+func (d *tomlDocument) deleteSection(section *tomlSection, trailing bool) {
+	start := tomlHeadStart(d.src, section.start)
+	end := section.end
+	for end < len(d.src) {
+		next := tomlLineEnd(d.src, end)
+		if len(bytes.TrimSpace(d.src[end:next])) > 0 {
+			break
+		}
+		end = next
+	}
+	if trailing {
+		for start > 0 {
+			prev := tomlLineStart(d.src, start-1)
+			if len(bytes.TrimSpace(d.src[prev:start])) > 0 {
+				break
+			}
+			start = prev
+		}
+	}
+	d.edit(start, end, "")
+}
+
+// edit records the replacement of the bytes in [start, end).
+func (d *tomlDocument) edit(start, end int, text string) {
+	d.edits = append(d.edits, tomlEdit{start: start, end: end, text: text})
+}
+
+// insert records the insertion of text at offset
+// (on a new line, if offset is not at the start of one).
+func (d *tomlDocument) insert(offset int, text string) {
+	if offset > 0 && d.src[offset-1] != '\n' {
+		text = "\n" + text
+	}
+	d.edit(offset, offset, text)
+}
+
+// apply returns the document with all edits applied.
+func (d *tomlDocument) apply() []byte {
+	edits := make([]tomlEdit, len(d.edits))
+	copy(edits, d.edits)
+	// Apply edits back to front so that offsets stay valid.
+	// Replacements at an offset go before insertions at the same offset,
+	// and insertions at the same offset are applied in reverse order
+	// (so that the earliest ends up first).
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	sort.SliceStable(edits, func(i, j int) bool {
+		if edits[i].start != edits[j].start {
+			return edits[i].start > edits[j].start
+		}
+		return edits[i].end > edits[j].end
+	})
+
+	src := d.src
+	limit := len(src)
+	for _, edit := range edits {
+		// Overlapping deletions (i.e. of the blank lines between
+		// adjacent deleted tables) are clipped.
+		if edit.text == "" && edit.end > limit {
+			edit.end = max(limit, edit.start)
+		}
+		limit = edit.start
+		updated := make([]byte, 0, len(src)-(edit.end-edit.start)+len(edit.text))
+		updated = append(updated, src[:edit.start]...)
+		updated = append(updated, edit.text...)
+		updated = append(updated, src[edit.end:]...)
+		src = updated
+	}
+	return src
+}
+
+// tomlChildKey returns the qualified path key for a child of parent.
+// Array table items are children named "#<index>".
+func tomlChildKey(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "\x00" + name
+}
+
+// tomlLookup returns the value at path (and array table item indexes) in data.
+func tomlLookup(data map[string]any, path []string, indexes []int) (any, bool) {
+	var value any = data
+	for i, key := range path {
+		table, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = table[key]; !ok {
+			return nil, false
+		}
+		if indexes[i] >= 0 {
+			items, ok := value.([]any)
+			if !ok || indexes[i] >= len(items) {
+				return nil, false
+			}
+			value = items[indexes[i]]
+		}
+	}
+	return value, true
+}
+
+// tomlValue returns value encoded as an inline TOML value.
+// Strings keep the quoting style of original (the previously encoded value)
+// when possible.
+func tomlValue(value any, original string) (string, error) {
+	quote := byte('"')
+	if i := strings.IndexAny(original, `"'`); i >= 0 {
+		quote = original[i]
+	}
+	return tomlInlineValue(value, quote)
+}
+
+func tomlInlineValue(value any, quote byte) (string, error) {
+	switch v := value.(type) {
+	case string:
+		literal := strings.IndexFunc(v, func(r rune) bool {
+			return r == '\'' || (r < 0x20 && r != '\t') || r == 0x7f
+		}) < 0
+		if quote == '\'' && literal {
+			return "'" + v + "'", nil
+		}
+		// JSON string escapes are valid in TOML basic strings.
+		b := &bytes.Buffer{}
+		encoder := json.NewEncoder(b)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(v); err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(b.String(), "\n"), nil
+	case map[string]any:
+		keys := []string{}
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		items := []string{}
+		for _, key := range keys {
+			item, err := tomlInlineValue(v[key], quote)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, tomlKey(key)+" = "+item)
+		}
+		if len(items) == 0 {
+			return "{}", nil
+		}
+		return "{ " + strings.Join(items, ", ") + " }", nil
+	case []any:
+		items := []string{}
+		for _, item := range v {
+			encoded, err := tomlInlineValue(item, quote)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, encoded)
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	default:
+		// Let the library handle the encoding of other scalars.
+		encoded, err := toml.Marshal(map[string]any{"v": v})
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(strings.TrimPrefix(string(encoded), "v = "), "\n"), nil
+	}
+}
+
+// tomlArrayItem is the span of an item in an encoded array.
+type tomlArrayItem struct {
+	start     int
+	end       int
+	comma     int    // offset of the comma following the item (or -1)
+	ownLine   bool   // true if no other item shares the lines of the item
+	lineStart int    // offset of the first line of the item
+	lineEnd   int    // offset after the last line of the item (including any comment)
+	indent    string // indentation of the first line
+}
+
+// tomlArrayItems returns the items of the array in src[start:end].
+func tomlArrayItems(src []byte, start, end int) ([]tomlArrayItem, bool) {
+	if start >= end || src[start] != '[' {
+		return nil, false
+	}
+	items := []tomlArrayItem{}
+	i := tomlSkipSpace(src, start+1, end, true)
+	for i < end && src[i] != ']' {
+		item := tomlArrayItem{start: i, comma: -1}
+		item.end = tomlValueEnd(src, i, end)
+		if item.end <= i {
+			return nil, false
+		}
+		i = tomlSkipSpace(src, item.end, end, true)
+		if i < end && src[i] == ',' {
+			item.comma = i
+			i = tomlSkipSpace(src, i+1, end, true)
+		}
+		items = append(items, item)
+	}
+	if i >= end {
+		return nil, false
+	}
+
+	for k := range items {
+		item := &items[k]
+		item.lineStart = tomlLineStart(src, item.start)
+		item.indent = string(src[item.lineStart:item.start])
+		after := item.end
+		if item.comma >= 0 {
+			after = item.comma + 1
+		}
+		rest := tomlSkipSpace(src, after, end, false)
+		if rest < end && src[rest] == '#' {
+			rest = tomlLineEnd(src, rest) - 1
+		}
+		item.ownLine = strings.TrimSpace(item.indent) == "" &&
+			rest < end && (src[rest] == '\n' || src[rest] == '\r')
+		item.lineEnd = tomlLineEnd(src, rest)
+	}
+	return items, true
+}
+
+// tomlInlineEntry is the span of a key/value in an encoded inline table.
+type tomlInlineEntry struct {
+	key        string
+	keyStart   int
+	valueStart int
+	valueEnd   int
+}
+
+// tomlInlineEntries returns the entries of the inline table in src[start:end].
+// It returns false for tables with dotted keys.
+func tomlInlineEntries(src []byte, start, end int) ([]tomlInlineEntry, bool) {
+	if start >= end || src[start] != '{' {
+		return nil, false
+	}
+	entries := []tomlInlineEntry{}
+	i := tomlSkipSpace(src, start+1, end, false)
+	for i < end && src[i] != '}' {
+		entry := tomlInlineEntry{keyStart: i}
+		keyEnd := i
+		if src[i] == '"' || src[i] == '\'' {
+			keyEnd = tomlValueEnd(src, i, end)
+		} else {
+			for keyEnd < end && tomlBareKey.Match(src[keyEnd:keyEnd+1]) {
+				keyEnd++
+			}
+		}
+		if keyEnd <= i {
+			return nil, false
+		}
+		key, err := tomlParseKey(src[i:keyEnd])
+		if err != nil {
+			return nil, false
+		}
+		entry.key = key
+		i = tomlSkipSpace(src, keyEnd, end, false)
+		if i >= end || src[i] != '=' {
+			return nil, false // i.e. a dotted key
+		}
+		entry.valueStart = tomlSkipSpace(src, i+1, end, false)
+		entry.valueEnd = tomlValueEnd(src, entry.valueStart, end)
+		if entry.valueEnd <= entry.valueStart {
+			return nil, false
+		}
+		entries = append(entries, entry)
+		i = tomlSkipSpace(src, entry.valueEnd, end, false)
+		if i < end && src[i] == ',' {
+			i = tomlSkipSpace(src, i+1, end, false)
+		}
+	}
+	return entries, i < end
+}
+
+// tomlParseKey returns the name of an encoded simple key.
+func tomlParseKey(raw []byte) (string, error) {
+	if raw[0] != '"' && raw[0] != '\'' {
+		return string(raw), nil
+	}
+	table := map[string]any{}
+	if err := toml.Unmarshal(append(append([]byte{}, raw...), " = 0"...), &table); err != nil {
+		return "", err
+	}
+	for key := range table {
+		return key, nil
+	}
+	return "", fmt.Errorf("invalid key: %s", raw)
+}
+
+// tomlSkipSpace returns the offset of the next non-whitespace byte in src[i:end].
+// Newlines and comments are skipped too if multiline is true.
+func tomlSkipSpace(src []byte, i, end int, multiline bool) int {
+	for i < end {
+		switch c := src[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case multiline && (c == '\n' || c == '\r'):
+			i++
+		case multiline && c == '#':
+			i = tomlLineEnd(src, i)
+		default:
+			return i
+		}
+	}
+	return min(i, end)
+}
+
+// tomlValueEnd returns the offset after the encoded value starting at src[i]
+// (or i if it does not end before end).
+func tomlValueEnd(src []byte, i, end int) int {
+	if i >= end {
+		return i
+	}
+	switch {
+	case bytes.HasPrefix(src[i:end], []byte(`"""`)), bytes.HasPrefix(src[i:end], []byte("'''")):
+		delim := src[i : i+3]
+		for j := i + 3; j < end; j++ {
+			if delim[0] == '"' && src[j] == '\\' {
+				j++
+				continue
+			}
+			if bytes.HasPrefix(src[j:end], delim) {
+				// Up to two quotes may directly precede the closing delimiter.
+				k := j + 3
+				for k < end && k < j+5 && src[k] == delim[0] {
+					k++
+				}
+				return k
+			}
+		}
+		return i
+	case src[i] == '"' || src[i] == '\'':
+		for j := i + 1; j < end && src[j] != '\n'; j++ {
+			if src[i] == '"' && src[j] == '\\' {
+				j++
+				continue
+			}
+			if src[j] == src[i] {
+				return j + 1
+			}
+		}
+		return i
+	case src[i] == '[' || src[i] == '{':
+		depth := 0
+		for j := i; j < end; j++ {
+			switch src[j] {
+			case '"', '\'':
+				k := tomlValueEnd(src, j, end)
+				if k <= j {
+					return i
+				}
+				j = k - 1
+			case '#':
+				j = tomlLineEnd(src, j) - 1
+			case '[', '{':
+				depth++
+			case ']', '}':
+				depth--
+				if depth == 0 {
+					return j + 1
+				}
+			}
+		}
+		return i
+	}
+	// Numbers, booleans, and dates (which may contain a space).
+	j := i
+	for j < end && bytes.IndexByte([]byte(",]}#\r\n"), src[j]) < 0 {
+		j++
+	}
+	return i + len(bytes.TrimRight(src[i:j], " \t"))
+}
+
+// tomlLineStart returns the offset of the start of the line containing offset.
+func tomlLineStart(src []byte, offset int) int {
+	return bytes.LastIndexByte(src[:offset], '\n') + 1
+}
+
+// tomlLineEnd returns the offset after the end of the line containing offset
+// (including the newline).
+func tomlLineEnd(src []byte, offset int) int {
+	if i := bytes.IndexByte(src[offset:], '\n'); i >= 0 {
+		return offset + i + 1
+	}
+	return len(src)
+}
+
+// tomlHeadStart returns the offset of the comment lines directly above
+// the line starting at offset.
+func tomlHeadStart(src []byte, offset int) int {
+	for offset > 0 {
+		prev := tomlLineStart(src, offset-1)
+		if !bytes.HasPrefix(bytes.TrimLeft(src[prev:offset], " \t"), []byte("#")) {
+			break
+		}
+		offset = prev
+	}
+	return offset
+}
@@ -0,0 +1,236 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTOMLEncoder_Patch(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		data     any
+		want     string
+	}{
+		{
+			name: "appends to a multi-line array",
+			original: "deps = [\n" +
+				"  \"a\", # first\n" +
+				"  \"b\" # second\n" +
+				"]\n",
+			data: map[string]any{
+				"deps": []any{"a", "b", "c"},
+			},
+			want: "deps = [\n" +
+				"  \"a\", # first\n" +
+				"  \"b\", # second\n" +
+				"  \"c\"\n" +
+				"]\n",
+		},
+		{
+			name: "adds and removes items of a multi-line array",
+			original: "deps = [\n" +
+				"  'a', # first\n" +
+				"  'b', # second\n" +
+				"  'c',\n" +
+				"  'd',\n" +
+				"]\n",
+			data: map[string]any{
+				"deps": []any{"z", "a", "c"},
+			},
+			want: "deps = [\n" +
+				"  'z',\n" +
+				"  'a', # first\n" +
+				"  'c',\n" +
+				"]\n",
+		},
+		{
+			name:     "updates array items in place",
+			original: "deps = [1, 2,   3] # comment\n",
+			data: map[string]any{
+				"deps": []any{1, 5, 3},
+			},
+			want: "deps = [1, 5,   3] # comment\n",
+		},
+		{
+			name:     "keeps the key order of an inline table",
+			original: "point = { y = 1, x = 2, \"a b\" = 3 }\n",
+			data: map[string]any{
+				"point": map[string]any{"y": 1, "x": 5, "a b": 3},
+			},
+			want: "point = { y = 1, x = 5, \"a b\" = 3 }\n",
+		},
+		{
+			name:     "adds and removes inline table keys",
+			original: "point = { y = 1, x = 2, z = 3 }\n",
+			data: map[string]any{
+				"point": map[string]any{"x": 2, "w": 0},
+			},
+			want: "point = { x = 2, w = 0 }\n",
+		},
+		{
+			name: "adds array table items after the existing ones",
+			original: "[[item]]\n" +
+				"name = 'a'\n" +
+				"\n" +
+				"[[item]]\n" +
+				"name = 'b'\n" +
+				"[item.sub]\n" +
+				"v = 1\n" +
+				"\n" +
+				"# Other settings\n" +
+				"[other]\n" +
+				"k = 1\n",
+			data: map[string]any{
+				"item": []any{
+					map[string]any{"name": "a"},
+					map[string]any{"name": "b", "sub": map[string]any{"v": 1}},
+					map[string]any{"name": "c"},
+				},
+				"other": map[string]any{"k": 1},
+			},
+			want: "[[item]]\n" +
+				"name = 'a'\n" +
+				"\n" +
+				"[[item]]\n" +
+				"name = 'b'\n" +
+				"[item.sub]\n" +
+				"v = 1\n" +
+				"\n" +
+				"[[item]]\n" +
+				"name = 'c'\n" +
+				"\n" +
+				"# Other settings\n" +
+				"[other]\n" +
+				"k = 1\n",
+		},
+		{
+			name: "adds array table items after keys added to the last one",
+			original: "[[item]]\n" +
+				"name = \"a\"\n" +
+				"\n" +
+				"[other]\n" +
+				"k = 1\n",
+			data: map[string]any{
+				"item": []any{
+					map[string]any{"name": "a", "n": 2},
+					map[string]any{"name": "b"},
+				},
+				"other": map[string]any{"k": 1},
+			},
+			want: "[[item]]\n" +
+				"name = \"a\"\n" +
+				"n = 2\n" +
+				"\n" +
+				"[[item]]\n" +
+				"name = \"b\"\n" +
+				"\n" +
+				"[other]\n" +
+				"k = 1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &TOMLEncoder{}
+			got, err := e.Patch([]byte(tt.original), tt.data)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestTOMLEncoder_Patch_RoundTrip(t *testing.T) {
+	original := "# Service config.\n" +
+		"name = 'api' # the service name\n" +
+		"features = [\"auth\", \"metrics\"]\n" +
+		"\n" +
+		"[server]\n" +
+		"host = \"0.0.0.0\"\n" +
+		"port = 8080\n" +
+		"\n" +
+		"# Backends.\n" +
+		"[[backend]]\n" +
+		"url = \"http://a\"\n"
+
+	tests := []struct {
+		name string
+		edit func(data map[string]any)
+		want string
+	}{
+		{
+			name: "leaves unchanged content byte-identical",
+			edit: func(data map[string]any) {},
+			want: original,
+		},
+		{
+			name: "updates a single key",
+			edit: func(data map[string]any) {
+				data["server"].(map[string]any)["port"] = int64(9090)
+			},
+			want: "# Service config.\n" +
+				"name = 'api' # the service name\n" +
+				"features = [\"auth\", \"metrics\"]\n" +
+				"\n" +
+				"[server]\n" +
+				"host = \"0.0.0.0\"\n" +
+				"port = 9090\n" +
+				"\n" +
+				"# Backends.\n" +
+				"[[backend]]\n" +
+				"url = \"http://a\"\n",
+		},
+		{
+			name: "keeps comments on updated keys",
+			edit: func(data map[string]any) {
+				data["name"] = "web"
+			},
+			want: "# Service config.\n" +
+				"name = 'web' # the service name\n" +
+				"features = [\"auth\", \"metrics\"]\n" +
+				"\n" +
+				"[server]\n" +
+				"host = \"0.0.0.0\"\n" +
+				"port = 8080\n" +
+				"\n" +
+				"# Backends.\n" +
+				"[[backend]]\n" +
+				"url = \"http://a\"\n",
+		},
+		{
+			name: "appends keys, items, and tables",
+			edit: func(data map[string]any) {
+				data["server"].(map[string]any)["tls"] = true
+				data["features"] = append(data["features"].([]any), "tracing")
+				data["backend"] = append(data["backend"].([]any), map[string]any{"url": "http://b"})
+			},
+			want: "# Service config.\n" +
+				"name = 'api' # the service name\n" +
+				"features = [\"auth\", \"metrics\", \"tracing\"]\n" +
+				"\n" +
+				"[server]\n" +
+				"host = \"0.0.0.0\"\n" +
+				"port = 8080\n" +
+				"tls = true\n" +
+				"\n" +
+				"# Backends.\n" +
+				"[[backend]]\n" +
+				"url = \"http://a\"\n" +
+				"\n" +
+				"[[backend]]\n" +
+				"url = \"http://b\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &TOMLEncoder{}
+			data, err := e.Decode([]byte(original))
+			assert.NoError(t, err)
+			tt.edit(data.(map[string]any))
+
+			got, err := e.Patch([]byte(original), data)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package encode
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/beevik/etree"
+	"github.com/spf13/cast"
+)
+
+var _ Encoder = &XMLEncoder{}
+
+type XMLEncoder struct {
+}
+
+// Decode deserializes the given XML encoded byte array into a document.
+// Comments, namespaces, and whitespace are preserved.
+func (e *XMLEncoder) Decode(encoded []byte) (any, error) {
+	doc := etree.NewDocument()
+	doc.ReadSettings.PreserveCData = true
+	if len(bytes.TrimSpace(encoded)) == 0 {
+		return doc, nil
+	}
+	if err := doc.ReadFromBytes(encoded); err != nil {
+		return nil, fmt.Errorf("xml decode: %w", err)
+	}
+	return doc, nil
+}
+
+// Encode serializes the given document into an XML encoded byte array.
+// Any other data is cast to a string and returned as-is.
+func (e *XMLEncoder) Encode(data any) ([]byte, error) {
+	switch v := data.(type) {
+	case *etree.Document:
+		content, err := v.WriteToBytes()
+		if err != nil {
+			return nil, fmt.Errorf("xml encode: %w", err)
+		}
+		return content, nil
+	case *etree.Element:
+		return e.Encode(etree.NewDocumentWithRoot(v.Copy()))
+	default:
+		casted, err := cast.ToStringE(data)
+		if err != nil {
+			return nil, fmt.Errorf("xml encode: unable to cast: %#v", data)
+		}
+		return []byte(casted), nil
+	}
+}
@@ -0,0 +1,99 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/beevik/etree"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXMLEncoder_RoundTrip(t *testing.T) {
+	original := "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+		"<!-- Service config. -->\n" +
+		"<service xmlns:x=\"urn:x\" name=\"api\">\n" +
+		"  <server host=\"0.0.0.0\" port=\"8080\"/>\n" +
+		"  <!-- Enabled features. -->\n" +
+		"  <features>\n" +
+		"    <feature>auth</feature>\n" +
+		"    <x:feature><![CDATA[metrics]]></x:feature>\n" +
+		"  </features>\n" +
+		"</service>\n"
+
+	tests := []struct {
+		name string
+		edit func(doc *etree.Document)
+		want string
+	}{
+		{
+			name: "leaves unchanged content byte-identical",
+			edit: func(doc *etree.Document) {},
+			want: original,
+		},
+		{
+			name: "updates a single attribute",
+			edit: func(doc *etree.Document) {
+				doc.FindElement("/service/server").CreateAttr("port", "9090")
+			},
+			want: "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+				"<!-- Service config. -->\n" +
+				"<service xmlns:x=\"urn:x\" name=\"api\">\n" +
+				"  <server host=\"0.0.0.0\" port=\"9090\"/>\n" +
+				"  <!-- Enabled features. -->\n" +
+				"  <features>\n" +
+				"    <feature>auth</feature>\n" +
+				"    <x:feature><![CDATA[metrics]]></x:feature>\n" +
+				"  </features>\n" +
+				"</service>\n",
+		},
+		{
+			name: "keeps comments around updated elements",
+			edit: func(doc *etree.Document) {
+				doc.FindElement("/service/features/feature").SetText("sso")
+			},
+			want: "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+				"<!-- Service config. -->\n" +
+				"<service xmlns:x=\"urn:x\" name=\"api\">\n" +
+				"  <server host=\"0.0.0.0\" port=\"8080\"/>\n" +
+				"  <!-- Enabled features. -->\n" +
+				"  <features>\n" +
+				"    <feature>sso</feature>\n" +
+				"    <x:feature><![CDATA[metrics]]></x:feature>\n" +
+				"  </features>\n" +
+				"</service>\n",
+		},
+		{
+			name: "appends attributes and elements",
+			edit: func(doc *etree.Document) {
+				doc.FindElement("/service/server").CreateAttr("tls", "true")
+				features := doc.FindElement("/service/features")
+				last := features.ChildElements()[1]
+				features.InsertChildAt(last.Index()+1, etree.NewText("\n    "))
+				features.InsertChildAt(last.Index()+2, etree.NewElement("feature"))
+				features.ChildElements()[2].SetText("tracing")
+			},
+			want: "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+				"<!-- Service config. -->\n" +
+				"<service xmlns:x=\"urn:x\" name=\"api\">\n" +
+				"  <server host=\"0.0.0.0\" port=\"8080\" tls=\"true\"/>\n" +
+				"  <!-- Enabled features. -->\n" +
+				"  <features>\n" +
+				"    <feature>auth</feature>\n" +
+				"    <x:feature><![CDATA[metrics]]></x:feature>\n" +
+				"    <feature>tracing</feature>\n" +
+				"  </features>\n" +
+				"</service>\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &XMLEncoder{}
+			data, err := e.Decode([]byte(original))
+			assert.NoError(t, err)
+			tt.edit(data.(*etree.Document))
+
+			got, err := e.Encode(data)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
@@ -3,11 +3,15 @@ package encode
 import (
 	"bytes"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
 var _ Encoder = &YAMLEncoder{}
+var _ Patcher = &YAMLEncoder{}
 
 type YAMLEncoder struct {
 }
@@ -24,12 +28,690 @@ func (e *YAMLEncoder) Decode(encoded []byte) (any, error) {
 
 // Encode serializes the given data structure into a YAML encoded byte array.
 func (e *YAMLEncoder) Encode(data any) ([]byte, error) {
+	return e.encode(data, 2)
+}
+
+// Patch serializes the given data structure by editing only the lines
+// of the original YAML document that represent changed values.
+// Comments, blank lines, key order, anchors, indentation,
+// and quoting/flow styles are preserved for unchanged content.
+func (e *YAMLEncoder) Patch(original []byte, data any) ([]byte, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(original, doc); err != nil {
+		return nil, fmt.Errorf("yaml decode: %w", err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return e.patchEmpty(original, data)
+	}
+
+	root := doc.Content[0]
+	p := &yamlPatcher{
+		lines:  strings.Split(string(original), "\n"),
+		indent: yamlIndent(original),
+	}
+	patched, err := p.patchValue(root, data, p.documentEnd(root))
+	if err != nil {
+		return nil, fmt.Errorf("yaml encode: %w", err)
+	}
+	if !patched {
+		return e.Encode(data)
+	}
+	return p.apply(), nil
+}
+
+// patchEmpty appends data to a document without any content,
+// keeping whatever comments it has.
+func (e *YAMLEncoder) patchEmpty(original []byte, data any) ([]byte, error) {
+	if data == nil {
+		return original, nil
+	}
+	encoded, err := e.Encode(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(original)) == 0 {
+		return encoded, nil
+	}
+	patched := bytes.Clone(original)
+	if !bytes.HasSuffix(patched, []byte("\n")) {
+		patched = append(patched, '\n')
+	}
+	return append(patched, encoded...), nil
+}
+
+func (e *YAMLEncoder) encode(data any, indent int) ([]byte, error) {
 	b := &bytes.Buffer{}
 	encoder := yaml.NewEncoder(b)
-	encoder.SetIndent(2)
+	encoder.SetIndent(indent)
 	err := encoder.Encode(data)
 	if err != nil {
 		return nil, fmt.Errorf("yaml encode: %w", err)
 	}
 	return b.Bytes(), nil
 }
+
+// yamlIndent returns the number of spaces used to indent
+// the encoded document (defaulting to 2).
+func yamlIndent(encoded []byte) int {
+	for _, line := range bytes.Split(encoded, []byte("\n")) {
+		trimmed := bytes.TrimLeft(line, " ")
+		if len(trimmed) == 0 || trimmed[0] == '#' {
+			continue
+		}
+		if n := len(line) - len(trimmed); n > 0 {
+			if n < 2 || n > 8 {
+				break
+			}
+			return n
+		}
+	}
+	return 2
+}
+
+// yamlPatcher collects line based edits to a YAML document.
+// Line numbers are zero based and ranges are half-open.
+type yamlPatcher struct {
+	lines  []string
+	indent int
+	edits  []yamlEdit
+}
+
+// yamlEdit replaces lines [start, end) with lines.
+type yamlEdit struct {
+	start int
+	end   int
+	lines []string
+}
+
+// apply returns the document with all edits applied.
+func (p *yamlPatcher) apply() []byte {
+	edits := make([]yamlEdit, len(p.edits))
+	copy(edits, p.edits)
+	// Apply edits bottom up so that line numbers stay valid.
+	// Replacements at a line go before insertions at the same line,
+	// and insertions at the same line are applied in reverse order
+	// (so that the earliest ends up first).
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	sort.SliceStable(edits, func(i, j int) bool {
+		if edits[i].start != edits[j].start {
+			return edits[i].start > edits[j].start
+		}
+		return edits[i].end > edits[j].end
+	})
+
+	lines := p.lines
+	for _, edit := range edits {
+		updated := make([]string, 0, len(lines)-(edit.end-edit.start)+len(edit.lines))
+		updated = append(updated, lines[:edit.start]...)
+		updated = append(updated, edit.lines...)
+		updated = append(updated, lines[edit.end:]...)
+		lines = updated
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// edit records the replacement of lines [start, end).
+func (p *yamlPatcher) edit(start, end int, lines []string) {
+	p.edits = append(p.edits, yamlEdit{start: start, end: end, lines: lines})
+}
+
+// documentEnd returns the line ending the document that root belongs to.
+func (p *yamlPatcher) documentEnd(root *yaml.Node) int {
+	for i := root.Line; i < len(p.lines); i++ {
+		if strings.HasPrefix(p.lines[i], "---") || strings.HasPrefix(p.lines[i], "...") {
+			return i
+		}
+	}
+	return len(p.lines)
+}
+
+// entryEnd returns the end of an entry (a mapping key/value or a sequence item)
+// that starts at line start, given that the next entry (or parent) starts at limit.
+// Trailing blank lines and comments that are not indented past column
+// (i.e. the head comments of the next entry) are excluded.
+func (p *yamlPatcher) entryEnd(start, limit, column int) int {
+	end := limit
+	for end > start+1 {
+		line := p.lines[end-1]
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed != "" && !(trimmed[0] == '#' && len(line)-len(trimmed) <= column) {
+			break
+		}
+		end--
+	}
+	return end
+}
+
+// headStart returns the first line of the comments directly above line start.
+func (p *yamlPatcher) headStart(start, column int) int {
+	for start > 0 {
+		line := p.lines[start-1]
+		trimmed := strings.TrimLeft(line, " ")
+		if !strings.HasPrefix(trimmed, "#") || len(line)-len(trimmed) != column {
+			break
+		}
+		start--
+	}
+	return start
+}
+
+// patchValue records the edits needed for node to represent data,
+// where node ends before line end. It returns false if node can not
+// be patched in place, in which case the enclosing entry has to be
+// replaced as a whole.
+func (p *yamlPatcher) patchValue(node *yaml.Node, data any, end int) (bool, error) {
+	var current any
+	if err := node.Decode(&current); err != nil {
+		return false, err
+	}
+	if reflect.DeepEqual(current, data) {
+		return true, nil
+	}
+
+	block := node.Style&yaml.FlowStyle == 0
+	switch v := data.(type) {
+	case map[string]any:
+		if node.Kind == yaml.MappingNode && block && len(v) > 0 {
+			return p.patchMapping(node, current, v, end)
+		}
+	case []any:
+		if node.Kind == yaml.SequenceNode && block && len(v) > 0 {
+			return p.patchSequence(node, v, end)
+		}
+	}
+	return p.patchInline(node, data, end)
+}
+
+// patchMapping updates the key/value pairs in node to match data.
+// Existing keys keep their order and new keys are appended (sorted).
+func (p *yamlPatcher) patchMapping(node *yaml.Node, current any, data map[string]any, end int) (bool, error) {
+	merged, _ := current.(map[string]any)
+	column := node.Column - 1
+
+	explicit := map[string]bool{}
+	last := -1
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		limit := end
+		if i+2 < len(node.Content) {
+			limit = node.Content[i+2].Line - 1
+		}
+		start := key.Line - 1
+		entryEnd := p.entryEnd(start, limit, column)
+		last = entryEnd
+		if key.Tag == "!!merge" {
+			continue // merged keys are left as-is (see below)
+		}
+		explicit[key.Value] = true
+
+		item, found := data[key.Value]
+		if !found {
+			p.edit(p.headStart(start, column), entryEnd, nil) // removed
+			continue
+		}
+		patched, err := p.patchValue(value, item, entryEnd)
+		if err != nil {
+			return false, err
+		}
+		if !patched {
+			if err := p.replaceEntry(start, entryEnd, column, key, value, item); err != nil {
+				return false, err
+			}
+		}
+	}
+	if last < 0 {
+		return false, nil
+	}
+
+	keys := []string{}
+	for key, item := range data {
+		if explicit[key] {
+			continue
+		}
+		if value, found := merged[key]; found && reflect.DeepEqual(value, item) {
+			continue // unchanged value from a merged mapping
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return true, nil
+	}
+	sort.Strings(keys)
+	added := &yaml.Node{Kind: yaml.MappingNode}
+	for _, key := range keys {
+		keyNode, err := newYAMLNode(key)
+		if err != nil {
+			return false, err
+		}
+		valueNode, err := newYAMLNode(data[key])
+		if err != nil {
+			return false, err
+		}
+		added.Content = append(added.Content, keyNode, valueNode)
+	}
+	lines, err := p.encodeLines(added, column)
+	if err != nil {
+		return false, err
+	}
+	p.edit(last, last, lines)
+	return true, nil
+}
+
+// patchSequence updates the items in node to match data.
+// Unchanged items are reused (in order) so that their formatting is preserved.
+func (p *yamlPatcher) patchSequence(node *yaml.Node, data []any, end int) (bool, error) {
+	items := node.Content
+	if len(items) == 0 {
+		return false, nil
+	}
+	column := node.Column - 1
+	starts := make([]int, len(items))
+	ends := make([]int, len(items))
+	decoded := make([]any, len(items))
+	for i, item := range items {
+		starts[i] = p.dashLine(item, column)
+	}
+	for i, item := range items {
+		limit := end
+		if i+1 < len(items) {
+			limit = starts[i+1]
+		}
+		ends[i] = p.entryEnd(starts[i], limit, column)
+		if err := item.Decode(&decoded[i]); err != nil {
+			return false, err
+		}
+	}
+
+	if len(items) == len(data) {
+		// Same length: update each item in place.
+		for i, item := range items {
+			patched, err := p.patchValue(item, data[i], ends[i])
+			if err != nil {
+				return false, err
+			}
+			if !patched {
+				if err := p.replaceItem(starts[i], ends[i], column, item, data[i]); err != nil {
+					return false, err
+				}
+			}
+		}
+		return true, nil
+	}
+
+	// Items were added or removed: reuse matching items.
+	next := 0
+	pending := []any{}
+	for _, value := range data {
+		reused := -1
+		for j := next; j < len(items); j++ {
+			if reflect.DeepEqual(decoded[j], value) {
+				reused = j
+				break
+			}
+		}
+		if reused < 0 {
+			pending = append(pending, value)
+			continue
+		}
+		for j := next; j < reused; j++ {
+			p.edit(starts[j], ends[j], nil) // removed
+		}
+		if err := p.insertItems(starts[reused], column, pending); err != nil {
+			return false, err
+		}
+		pending = []any{}
+		next = reused + 1
+	}
+	for j := next; j < len(items); j++ {
+		p.edit(starts[j], ends[j], nil) // removed
+	}
+	if err := p.insertItems(ends[len(items)-1], column, pending); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// dashLine returns the line of the "-" indicator for a sequence item.
+func (p *yamlPatcher) dashLine(item *yaml.Node, column int) int {
+	for i := item.Line - 1; i >= 0; i-- {
+		line := []rune(p.lines[i])
+		if len(line) > column && line[column] == '-' {
+			return i
+		}
+	}
+	return item.Line - 1
+}
+
+// insertItems inserts new sequence items at line.
+func (p *yamlPatcher) insertItems(line, column int, values []any) error {
+	if len(values) == 0 {
+		return nil
+	}
+	node, err := newYAMLNode(values)
+	if err != nil {
+		return err
+	}
+	lines, err := p.encodeLines(node, column)
+	if err != nil {
+		return err
+	}
+	p.edit(line, line, lines)
+	return nil
+}
+
+// replaceEntry replaces the mapping entry in lines [start, end) with key: data.
+func (p *yamlPatcher) replaceEntry(start, end, column int, key, value *yaml.Node, data any) error {
+	updated, err := p.newValueNode(value, data)
+	if err != nil {
+		return err
+	}
+	keyNode := &yaml.Node{Kind: key.Kind, Tag: key.Tag, Value: key.Value, Style: key.Style}
+	lines, err := p.encodeLines(&yaml.Node{
+		Kind:    yaml.MappingNode,
+		Content: []*yaml.Node{keyNode, updated},
+	}, column)
+	if err != nil {
+		return err
+	}
+	p.edit(start, end, lines)
+	return nil
+}
+
+// replaceItem replaces the sequence item in lines [start, end) with data.
+func (p *yamlPatcher) replaceItem(start, end, column int, item *yaml.Node, data any) error {
+	updated, err := p.newValueNode(item, data)
+	if err != nil {
+		return err
+	}
+	lines, err := p.encodeLines(&yaml.Node{
+		Kind:    yaml.SequenceNode,
+		Content: []*yaml.Node{updated},
+	}, column)
+	if err != nil {
+		return err
+	}
+	p.edit(start, end, lines)
+	return nil
+}
+
+// patchInline replaces the text of a scalar or flow collection node,
+// keeping the rest of its line (e.g. comments) intact. It returns false
+// if either the original or the updated value spans multiple lines.
+func (p *yamlPatcher) patchInline(node *yaml.Node, data any, end int) (bool, error) {
+	flow := node.Kind != yaml.ScalarNode
+	if flow && node.Style&yaml.FlowStyle == 0 {
+		return false, nil
+	}
+	if node.Kind == yaml.AliasNode || node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		return false, nil
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" && node.Value == "" {
+		return false, nil // implicit null (no text to replace)
+	}
+	row := node.Line - 1
+	if p.entryEnd(row, end, node.Column-1) > row+1 && !flow {
+		return false, nil // multi-line plain scalar
+	}
+
+	line := []rune(p.lines[row])
+	start := node.Column - 1
+	stop := yamlTokenEnd(line, start)
+	if stop < 0 {
+		return false, nil
+	}
+
+	updated, err := p.mergeNode(node, data)
+	if err != nil {
+		return false, err
+	}
+	if updated.Kind != yaml.ScalarNode && !flow {
+		return false, nil
+	}
+	if flow {
+		setYAMLFlowStyle(updated)
+	}
+	updated.HeadComment, updated.LineComment, updated.FootComment = "", "", ""
+	encoded, err := yaml.Marshal(updated)
+	if err != nil {
+		return false, err
+	}
+	text := strings.TrimSuffix(string(encoded), "\n")
+	if strings.Contains(text, "\n") {
+		return false, nil
+	}
+
+	p.edit(row, row+1, []string{string(line[:start]) + text + string(line[stop:])})
+	return true, nil
+}
+
+// newValueNode returns a new node representing data,
+// keeping the anchor, comments, and quoting style of node.
+func (p *yamlPatcher) newValueNode(node *yaml.Node, data any) (*yaml.Node, error) {
+	updated, err := newYAMLNode(data)
+	if err != nil {
+		return nil, err
+	}
+	if node.Kind == yaml.ScalarNode && updated.Kind == yaml.ScalarNode &&
+		node.Tag == updated.Tag && node.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle) != 0 {
+		// Keep the original quoting style for strings.
+		updated.Style = node.Style
+	}
+	updated.Anchor = node.Anchor
+	updated.LineComment = node.LineComment
+	return updated, nil
+}
+
+// mergeNode returns a new node representing data that reuses
+// the unchanged parts of node, so that the key order and quoting
+// of a flow collection are preserved.
+func (p *yamlPatcher) mergeNode(node *yaml.Node, data any) (*yaml.Node, error) {
+	var current any
+	if err := node.Decode(&current); err != nil {
+		return nil, err
+	}
+	if reflect.DeepEqual(current, data) {
+		return node, nil
+	}
+
+	switch v := data.(type) {
+	case map[string]any:
+		if node.Kind == yaml.MappingNode {
+			return p.mergeMapping(node, v)
+		}
+	case []any:
+		if node.Kind == yaml.SequenceNode {
+			return p.mergeSequence(node, v)
+		}
+	}
+	return p.newValueNode(node, data)
+}
+
+// mergeMapping returns a mapping node for data with the keys of node
+// in their original order (new keys are appended, sorted).
+func (p *yamlPatcher) mergeMapping(node *yaml.Node, data map[string]any) (*yaml.Node, error) {
+	merged := &yaml.Node{Kind: yaml.MappingNode, Style: node.Style, Anchor: node.Anchor}
+	explicit := map[string]bool{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Tag == "!!merge" {
+			return p.newValueNode(node, data)
+		}
+		explicit[key.Value] = true
+		item, found := data[key.Value]
+		if !found {
+			continue // removed
+		}
+		updated, err := p.mergeNode(value, item)
+		if err != nil {
+			return nil, err
+		}
+		merged.Content = append(merged.Content, key, updated)
+	}
+
+	keys := []string{}
+	for key := range data {
+		if !explicit[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		keyNode, err := newYAMLNode(key)
+		if err != nil {
+			return nil, err
+		}
+		valueNode, err := newYAMLNode(data[key])
+		if err != nil {
+			return nil, err
+		}
+		merged.Content = append(merged.Content, keyNode, valueNode)
+	}
+	return merged, nil
+}
+
+// mergeSequence returns a sequence node for data, reusing the items of node
+// (in order) that are unchanged.
+func (p *yamlPatcher) mergeSequence(node *yaml.Node, data []any) (*yaml.Node, error) {
+	merged := &yaml.Node{Kind: yaml.SequenceNode, Style: node.Style, Anchor: node.Anchor}
+	if len(node.Content) == len(data) {
+		// Same length: update each item in place.
+		for i, item := range node.Content {
+			updated, err := p.mergeNode(item, data[i])
+			if err != nil {
+				return nil, err
+			}
+			merged.Content = append(merged.Content, updated)
+		}
+		return merged, nil
+	}
+
+	// Items were added or removed: reuse matching items.
+	next := 0
+	for _, value := range data {
+		var updated *yaml.Node
+		for j := next; j < len(node.Content) && updated == nil; j++ {
+			var decoded any
+			if err := node.Content[j].Decode(&decoded); err != nil {
+				return nil, err
+			}
+			if reflect.DeepEqual(decoded, value) {
+				updated = node.Content[j]
+				next = j + 1
+			}
+		}
+		if updated == nil {
+			var err error
+			if updated, err = newYAMLNode(value); err != nil {
+				return nil, err
+			}
+		}
+		merged.Content = append(merged.Content, updated)
+	}
+	return merged, nil
+}
+
+// encodeLines encodes node and indents the resulting lines by column spaces.
+func (p *yamlPatcher) encodeLines(node *yaml.Node, column int) ([]string, error) {
+	b := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(b)
+	encoder.SetIndent(p.indent)
+	if err := encoder.Encode(node); err != nil {
+		return nil, err
+	}
+	prefix := strings.Repeat(" ", column)
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return lines, nil
+}
+
+// yamlTokenEnd returns the index after the scalar or flow collection
+// starting at index start of line, or -1 if it does not end on the line.
+func yamlTokenEnd(line []rune, start int) int {
+	if start >= len(line) {
+		return -1
+	}
+	switch line[start] {
+	case '\'':
+		for i := start + 1; i < len(line); i++ {
+			if line[i] == '\'' {
+				if i+1 < len(line) && line[i+1] == '\'' {
+					i++ // escaped quote
+					continue
+				}
+				return i + 1
+			}
+		}
+		return -1
+	case '"':
+		for i := start + 1; i < len(line); i++ {
+			if line[i] == '\\' {
+				i++
+			} else if line[i] == '"' {
+				return i + 1
+			}
+		}
+		return -1
+	case '[', '{':
+		depth := 0
+		quote := rune(0)
+		for i := start; i < len(line); i++ {
+			c := line[i]
+			switch {
+			case quote == '"' && c == '\\':
+				i++
+			case quote != 0:
+				if c == quote {
+					quote = 0
+				}
+			case c == '\'' || c == '"':
+				quote = c
+			case c == '[' || c == '{':
+				depth++
+			case c == ']' || c == '}':
+				depth--
+				if depth == 0 {
+					return i + 1
+				}
+			}
+		}
+		return -1
+	}
+
+	// Plain scalars end at a comment or the end of the line.
+	end := len(line)
+	for i := start + 1; i < len(line); i++ {
+		if line[i] == '#' && (line[i-1] == ' ' || line[i-1] == '\t') {
+			end = i
+			break
+		}
+	}
+	for end > start && (line[end-1] == ' ' || line[end-1] == '\t') {
+		end--
+	}
+	return end
+}
+
+// setYAMLFlowStyle sets the flow style on all collections in the tree.
+func setYAMLFlowStyle(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode {
+		node.Style |= yaml.FlowStyle
+	}
+	for _, child := range node.Content {
+		setYAMLFlowStyle(child)
+	}
+}
+
+// newYAMLNode returns a new node representing data.
+func newYAMLNode(data any) (*yaml.Node, error) {
+	node := &yaml.Node{}
+	if err := node.Encode(data); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
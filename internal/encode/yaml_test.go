@@ -0,0 +1,166 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestYAMLEncoder_Patch(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		data     any
+		want     string
+	}{
+		{
+			name:     "keeps unchanged keys of a flow mapping as-is",
+			original: "foo: {y: 1, b: 2} # comment\n",
+			data: map[string]any{
+				"foo": map[string]any{"y": 1, "b": 3},
+			},
+			want: "foo: {y: 1, b: 3} # comment\n",
+		},
+		{
+			name:     "keeps the key order of a flow mapping",
+			original: "foo: {z: 1, 'y': 2}\n",
+			data: map[string]any{
+				"foo": map[string]any{"z": 1, "y": 2, "a": 3},
+			},
+			want: "foo: {z: 1, 'y': 2, a: 3}\n",
+		},
+		{
+			name:     "keeps unchanged items of a flow sequence as-is",
+			original: "foo: [y, 'n', 3]\n",
+			data: map[string]any{
+				"foo": []any{"y", "n", 3, 4},
+			},
+			want: "foo: [y, 'n', 3, 4]\n",
+		},
+		{
+			name:     "keeps the comments of a document without content",
+			original: "# Settings\n",
+			data:     map[string]any{"foo": "bar"},
+			want:     "# Settings\nfoo: bar\n",
+		},
+		{
+			name:     "keeps the comments of a document without content or a trailing newline",
+			original: "# Settings",
+			data:     map[string]any{"foo": "bar"},
+			want:     "# Settings\nfoo: bar\n",
+		},
+		{
+			name:     "leaves a document without content as-is when there is no data",
+			original: "# Settings\n",
+			data:     nil,
+			want:     "# Settings\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &YAMLEncoder{}
+			got, err := e.Patch([]byte(tt.original), tt.data)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestYAMLEncoder_Patch_RoundTrip(t *testing.T) {
+	original := "# Service config.\n" +
+		"name: api # the service name\n" +
+		"\n" +
+		"server:\n" +
+		"  host: \"0.0.0.0\"\n" +
+		"  port: 8080\n" +
+		"\n" +
+		"# Enabled features.\n" +
+		"features:\n" +
+		"  - auth\n" +
+		"  - 'metrics'\n" +
+		"tags: [a, b]\n"
+
+	tests := []struct {
+		name string
+		edit func(data map[string]any)
+		want string
+	}{
+		{
+			name: "leaves unchanged content byte-identical",
+			edit: func(data map[string]any) {},
+			want: original,
+		},
+		{
+			name: "updates a single key",
+			edit: func(data map[string]any) {
+				data["server"].(map[string]any)["port"] = 9090
+			},
+			want: "# Service config.\n" +
+				"name: api # the service name\n" +
+				"\n" +
+				"server:\n" +
+				"  host: \"0.0.0.0\"\n" +
+				"  port: 9090\n" +
+				"\n" +
+				"# Enabled features.\n" +
+				"features:\n" +
+				"  - auth\n" +
+				"  - 'metrics'\n" +
+				"tags: [a, b]\n",
+		},
+		{
+			name: "keeps comments on updated keys",
+			edit: func(data map[string]any) {
+				data["name"] = "web"
+			},
+			want: "# Service config.\n" +
+				"name: web # the service name\n" +
+				"\n" +
+				"server:\n" +
+				"  host: \"0.0.0.0\"\n" +
+				"  port: 8080\n" +
+				"\n" +
+				"# Enabled features.\n" +
+				"features:\n" +
+				"  - auth\n" +
+				"  - 'metrics'\n" +
+				"tags: [a, b]\n",
+		},
+		{
+			name: "appends keys and items",
+			edit: func(data map[string]any) {
+				data["server"].(map[string]any)["tls"] = true
+				data["features"] = append(data["features"].([]any), "tracing")
+				data["tags"] = append(data["tags"].([]any), "c")
+				data["version"] = "1.0"
+			},
+			want: "# Service config.\n" +
+				"name: api # the service name\n" +
+				"\n" +
+				"server:\n" +
+				"  host: \"0.0.0.0\"\n" +
+				"  port: 8080\n" +
+				"  tls: true\n" +
+				"\n" +
+				"# Enabled features.\n" +
+				"features:\n" +
+				"  - auth\n" +
+				"  - 'metrics'\n" +
+				"  - tracing\n" +
+				"tags: [a, b, c]\n" +
+				"version: \"1.0\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &YAMLEncoder{}
+			data, err := e.Decode([]byte(original))
+			assert.NoError(t, err)
+			tt.edit(data.(map[string]any))
+
+			got, err := e.Patch([]byte(original), data)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
@@ -98,24 +98,35 @@ func EnsureDirWritable(path string) error {
 	return nil
 }
 
-// EnsurePathRelativeToRoot ensures that the relative path exists inside the trusted root,
-// and returns it's absolute path. Returns an error if the path traverses outside the root.
-func EnsurePathRelativeToRoot(path string, root string) (string, error) {
-	path = filepath.FromSlash(path)
-
-	absRoot, err := filepath.Abs(root)
+// ResolvePath returns the absolute path for path.
+// If path exists, any symlinks are also resolved.
+func ResolvePath(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
 	if err != nil {
 		return "", err
 	}
 
 	// EvalSymlinks returns an lstat error if path does not exist.
-	if PathExists(absRoot) {
-		absRoot, err = filepath.EvalSymlinks(absRoot)
+	if PathExists(absPath) {
+		absPath, err = filepath.EvalSymlinks(absPath)
 		if err != nil {
 			return "", err
 		}
 	}
 
+	return absPath, nil
+}
+
+// EnsurePathRelativeToRoot ensures that the relative path exists inside the trusted root,
+// and returns it's absolute path. Returns an error if the path traverses outside the root.
+func EnsurePathRelativeToRoot(path string, root string) (string, error) {
+	path = filepath.FromSlash(path)
+
+	absRoot, err := ResolvePath(root)
+	if err != nil {
+		return "", err
+	}
+
 	absPath := path
 	if !filepath.IsAbs(absPath) {
 		absPath, err = filepath.Abs(filepath.Join(absRoot, path))
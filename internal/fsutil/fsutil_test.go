@@ -240,3 +240,23 @@ func TestIsSubDir(t *testing.T) {
 		})
 	}
 }
+
+func TestResolvePath(t *testing.T) {
+	testutil.InTempDir(t, func(dir string) {
+		testutil.WritePaths(t, dir, map[string]any{
+			"real/file.txt": "",
+		})
+		err := os.Symlink(filepath.Join(dir, "real"), filepath.Join(dir, "link"))
+		assert.NoError(t, err)
+
+		realDir, _ := filepath.EvalSymlinks(filepath.Join(dir, "real"))
+
+		resolved, err := ResolvePath("link")
+		assert.NoError(t, err)
+		assert.Equal(t, realDir, resolved)
+
+		resolved, err = ResolvePath("missing")
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join(testutil.CurrentDir(), "missing"), resolved)
+	})
+}
@@ -0,0 +1,69 @@
+package modify
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// Contains returns true if the arg is already present in the element.
+// It is used to determine whether modifying the element would be redundant.
+//
+//   - Slices contain the arg if they include an item equal to it
+//     (or, when the arg is also a slice, to every item in it).
+//   - Maps contain the arg if every key in the arg is present in the map
+//     and (recursively) contains the arg's value.
+//   - Strings and byte slices contain the arg if it is a substring.
+//   - All other types contain the arg if they are equal (when cast to strings).
+func Contains(element any, arg any) bool {
+	switch v := element.(type) {
+	case []byte:
+		return bytes.Contains(v, []byte(cast.ToString(arg)))
+	case string:
+		return strings.Contains(v, cast.ToString(arg))
+	case map[string]any:
+		a, ok := arg.(map[string]any)
+		if !ok {
+			return false
+		}
+		for key, value := range a {
+			existing, found := v[key]
+			if !found || !Contains(existing, value) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		items, ok := arg.([]any)
+		if !ok {
+			items = []any{arg}
+		}
+		for _, item := range items {
+			if !sliceContains(v, item) {
+				return false
+			}
+		}
+		return true
+	default:
+		return cast.ToString(element) == cast.ToString(arg)
+	}
+}
+
+// sliceContains returns true if any item in the slice contains the arg.
+// Scalar items must be equal to the arg (rather than contain it).
+func sliceContains(s []any, arg any) bool {
+	for _, item := range s {
+		switch item.(type) {
+		case map[string]any, []any:
+			if Contains(item, arg) {
+				return true
+			}
+		default:
+			if cast.ToString(item) == cast.ToString(arg) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package modify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContains(t *testing.T) {
+	tests := []struct {
+		Desc     string
+		Element  any
+		Arg      any
+		Expected bool
+	}{
+		{
+			Desc:     "bytes containing the arg",
+			Element:  []byte("foo bar baz"),
+			Arg:      []byte("bar"),
+			Expected: true,
+		},
+		{
+			Desc:     "bytes missing the arg",
+			Element:  []byte("foo bar baz"),
+			Arg:      "qux",
+			Expected: false,
+		},
+		{
+			Desc:     "string containing the arg",
+			Element:  "foo bar baz",
+			Arg:      "baz",
+			Expected: true,
+		},
+		{
+			Desc:     "equal scalars",
+			Element:  float64(1),
+			Arg:      1,
+			Expected: true,
+		},
+		{
+			Desc:     "unequal scalars",
+			Element:  true,
+			Arg:      false,
+			Expected: false,
+		},
+		{
+			Desc:     "slice containing the arg",
+			Element:  []any{"a", "b"},
+			Arg:      "b",
+			Expected: true,
+		},
+		{
+			Desc:     "slice containing every item in the arg",
+			Element:  []any{"a", "b", "c"},
+			Arg:      []any{"c", "a"},
+			Expected: true,
+		},
+		{
+			Desc:     "slice containing a substring of the arg",
+			Element:  []any{"abc"},
+			Arg:      "b",
+			Expected: false,
+		},
+		{
+			Desc:     "slice containing numbers of a different type",
+			Element:  []any{float64(1), float64(2)},
+			Arg:      []any{2},
+			Expected: true,
+		},
+		{
+			Desc:     "slice missing an item in the arg",
+			Element:  []any{"a", "b"},
+			Arg:      []any{"a", "c"},
+			Expected: false,
+		},
+		{
+			Desc: "map containing the arg",
+			Element: map[string]any{
+				"foo": "1",
+				"bar": map[string]any{"baz": []any{1, 2}},
+			},
+			Arg: map[string]any{
+				"bar": map[string]any{"baz": []any{2}},
+			},
+			Expected: true,
+		},
+		{
+			Desc: "map with a different value",
+			Element: map[string]any{
+				"foo": "1",
+			},
+			Arg: map[string]any{
+				"foo": "2",
+			},
+			Expected: false,
+		},
+		{
+			Desc: "map missing a key",
+			Element: map[string]any{
+				"foo": "1",
+			},
+			Arg: map[string]any{
+				"bar": "1",
+			},
+			Expected: false,
+		},
+		{
+			Desc:     "map and a non-map arg",
+			Element:  map[string]any{"foo": "1"},
+			Arg:      "foo",
+			Expected: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Desc, func(t *testing.T) {
+			assert.Equal(t, tt.Expected, Contains(tt.Element, tt.Arg))
+		})
+	}
+}
@@ -11,12 +11,18 @@ package modify
 // be customized via the 'merge' enum.
 //
 // Replace and delete behave consistently across all types.
+//
+// Insert-before and insert-after are only supported for text content.
+// The source is inserted on its own line(s) before or after the matching lines,
+// indented to match them.
 /*
 	ENUM(
-		append   // Append to the destination content.
-		prepend  // Prepend to the destination content.
-		replace  // Replace the destination.
-		delete   // Delete the destination content.
+		append         // Append to the destination content.
+		prepend        // Prepend to the destination content.
+		replace        // Replace the destination.
+		delete         // Delete the destination content.
+		insert-before  // Insert the source on the line(s) before the matching line(s) (text only).
+		insert-after   // Insert the source on the line(s) after the matching line(s) (text only).
 	).
 */
 type Action string
@@ -31,3 +37,8 @@ type Action string
 	).
 */
 type MergeType string
+
+// IsInsert returns true if the action inserts lines (i.e. insert-before or insert-after).
+func (x Action) IsInsert() bool {
+	return x == ActionInsertBefore || x == ActionInsertAfter
+}
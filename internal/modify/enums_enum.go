@@ -21,6 +21,10 @@ const (
 	ActionReplace Action = "replace"
 	// Delete the destination content.
 	ActionDelete Action = "delete"
+	// Insert the source on the line(s) before the matching line(s) (text only).
+	ActionInsertBefore Action = "insert-before"
+	// Insert the source on the line(s) after the matching line(s) (text only).
+	ActionInsertAfter Action = "insert-after"
 )
 
 var ErrInvalidAction = fmt.Errorf("not a valid Action, try [%s]", strings.Join(_ActionNames, ", "))
@@ -30,6 +34,8 @@ var _ActionNames = []string{
 	string(ActionPrepend),
 	string(ActionReplace),
 	string(ActionDelete),
+	string(ActionInsertBefore),
+	string(ActionInsertAfter),
 }
 
 // ActionNames returns a list of possible string values of Action.
@@ -52,10 +58,12 @@ func (x Action) IsValid() bool {
 }
 
 var _ActionValue = map[string]Action{
-	"append":  ActionAppend,
-	"prepend": ActionPrepend,
-	"replace": ActionReplace,
-	"delete":  ActionDelete,
+	"append":        ActionAppend,
+	"prepend":       ActionPrepend,
+	"replace":       ActionReplace,
+	"delete":        ActionDelete,
+	"insert-before": ActionInsertBefore,
+	"insert-after":  ActionInsertAfter,
 }
 
 // ParseAction attempts to convert a string to a Action.
@@ -114,7 +122,11 @@ numbers are added, and objects are recursively merged.
 Arrays are concatenated by default, but that behavior can
 be customized via the 'merge' enum.
 
-Replace and delete behave consistently across all types.`
+Replace and delete behave consistently across all types.
+
+Insert-before and insert-after are only supported for text content.
+The source is inserted on its own line(s) before or after the matching lines,
+indented to match them.`
 }
 
 // Enum implements the jsonschema.Enum interface.
@@ -124,6 +136,8 @@ func (x Action) Enum() []any {
 		"prepend",
 		"replace",
 		"delete",
+		"insert-before",
+		"insert-after",
 	}
 }
 
@@ -134,6 +148,8 @@ func (x Action) EnumComments() []string {
 		"Prepend to the destination content.",
 		"Replace the destination.",
 		"Delete the destination content.",
+		"Insert the source on the line(s) before the matching line(s) (text only).",
+		"Insert the source on the line(s) after the matching line(s) (text only).",
 	}
 }
 
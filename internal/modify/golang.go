@@ -0,0 +1,518 @@
+package modify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidGoTarget  = errors.New("invalid go target")
+	ErrGoTargetNotFound = errors.New("go target not found")
+)
+
+// GoTarget is a declaration (or statement) in a Go source file.
+//
+//   - "import": the import declarations.
+//   - "func:Name" (or "func:Type.Method"): the body of a function.
+//   - "struct:Name": the fields of a struct type.
+//   - "switch:Func" (or "switch:Func:tag"): the cases of the first switch
+//     statement in a function (optionally, the first with the given tag).
+type GoTarget struct {
+	Kind string
+	Name string
+	Tag  string
+}
+
+// ParseGoTarget parses a Go target pattern (i.e. "func:main").
+func ParseGoTarget(pattern string) (GoTarget, error) {
+	parts := strings.SplitN(strings.TrimSpace(pattern), ":", 3)
+	target := GoTarget{Kind: parts[0]}
+	if len(parts) > 1 {
+		target.Name = strings.TrimSpace(parts[1])
+	}
+	if len(parts) > 2 {
+		target.Tag = strings.TrimSpace(parts[2])
+	}
+	switch {
+	case target.Kind == "import" && len(parts) == 1:
+		return target, nil
+	case target.Kind == "func" && len(parts) == 2 && target.Name != "":
+		return target, nil
+	case target.Kind == "struct" && len(parts) == 2 && target.Name != "":
+		return target, nil
+	case target.Kind == "switch" && target.Name != "":
+		return target, nil
+	default:
+		return GoTarget{}, fmt.Errorf("%w: %q", ErrInvalidGoTarget, pattern)
+	}
+}
+
+// GoSource modifies the target (see [GoTarget]) in the Go source dst.
+// The src content is Go source: import specs (one per line),
+// statements, struct fields, or case clauses (depending on the target).
+//
+//   - Prepend and append add src to the start or end of the target
+//     (appended cases are added before any default case).
+//   - Replace substitutes the contents of the target with src.
+//   - Delete removes the target (or, for imports, the imports in src).
+//
+// Imports are never duplicated, and the result is formatted with gofmt.
+// Returns ErrGoTargetNotFound if the target (other than imports) does not exist.
+func GoSource(dst []byte, pattern string, action Action, src []byte, conf ModifierConf) ([]byte, error) {
+	target, err := ParseGoTarget(pattern)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", dst, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("go parse: %w", err)
+	}
+	e := &goEditor{fset: fset, file: file, src: dst}
+
+	if target.Kind == "import" {
+		err = e.modifyImports(action, src)
+	} else if node := e.find(target); node != nil {
+		err = e.modifyNode(node, action, src, conf)
+	} else {
+		err = fmt.Errorf("%w: %q", ErrGoTargetNotFound, pattern)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	formatted, err := format.Source(e.apply())
+	if err != nil {
+		return nil, fmt.Errorf("go format: %w", err)
+	}
+	return formatted, nil
+}
+
+// HasGoTarget returns true if the Go source dst contains the target.
+// For imports, it returns true only if dst contains all of the imports in src
+// (the import declarations themselves are always a valid target).
+func HasGoTarget(dst []byte, pattern string, src []byte) (bool, error) {
+	target, err := ParseGoTarget(pattern)
+	if err != nil {
+		return false, err
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", dst, parser.ParseComments)
+	if err != nil {
+		return false, fmt.Errorf("go parse: %w", err)
+	}
+	if target.Kind == "import" {
+		specs, err := goImportSpecs(src)
+		if err != nil {
+			return false, err
+		}
+		for _, spec := range specs {
+			if !goHasImport(file.Imports, spec) {
+				return false, nil
+			}
+		}
+		return len(specs) > 0, nil
+	}
+	e := &goEditor{fset: fset, file: file, src: dst}
+	return e.find(target) != nil, nil
+}
+
+// goEditor finds nodes in the syntax tree and records edits
+// to the corresponding ranges of the source.
+type goEditor struct {
+	fset  *token.FileSet
+	file  *ast.File
+	src   []byte
+	edits []goEdit
+}
+
+// goEdit replaces src[start:end] with text.
+type goEdit struct {
+	start int
+	end   int
+	text  string
+}
+
+func (e *goEditor) offset(pos token.Pos) int {
+	return e.fset.Position(pos).Offset
+}
+
+func (e *goEditor) replace(start, end token.Pos, text string) {
+	e.edits = append(e.edits, goEdit{start: e.offset(start), end: e.offset(end), text: text})
+}
+
+func (e *goEditor) insert(pos token.Pos, text string) {
+	e.replace(pos, pos, text)
+}
+
+// remove removes the lines spanned by [start, end).
+func (e *goEditor) remove(start, end token.Pos) {
+	s, t := e.offset(start), e.offset(end)
+	for s > 0 && (e.src[s-1] == ' ' || e.src[s-1] == '\t') {
+		s--
+	}
+	if i := bytes.IndexByte(e.src[t:], '\n'); i >= 0 && len(bytes.TrimSpace(e.src[t:t+i])) == 0 {
+		t += i + 1
+	}
+	e.edits = append(e.edits, goEdit{start: s, end: t})
+}
+
+// apply returns the source with all edits applied.
+func (e *goEditor) apply() []byte {
+	sort.SliceStable(e.edits, func(i, j int) bool {
+		return e.edits[i].start > e.edits[j].start
+	})
+	result := bytes.Clone(e.src)
+	for _, edit := range e.edits {
+		result = append(result[:edit.start], append([]byte(edit.text), result[edit.end:]...)...)
+	}
+	return result
+}
+
+// text returns the source of [start, end).
+func (e *goEditor) text(start, end token.Pos) string {
+	return string(e.src[e.offset(start):e.offset(end)])
+}
+
+func (e *goEditor) find(target GoTarget) ast.Node { //nolint:ireturn
+	switch target.Kind {
+	case "struct":
+		for _, decl := range e.file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if st, ok := ts.Type.(*ast.StructType); ok && ts.Name.Name == target.Name {
+					return st
+				}
+			}
+		}
+	case "func":
+		if fn := e.findFunc(target.Name); fn != nil {
+			return fn
+		}
+	case "switch":
+		fn := e.findFunc(target.Name)
+		if fn == nil || fn.Body == nil {
+			return nil
+		}
+		var found ast.Node
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			if found != nil {
+				return false
+			}
+			switch s := n.(type) {
+			case *ast.SwitchStmt:
+				if target.Tag == "" || s.Tag != nil && e.text(s.Tag.Pos(), s.Tag.End()) == target.Tag {
+					found = s
+				}
+			case *ast.TypeSwitchStmt:
+				if target.Tag == "" || e.typeSwitchTag(s) == target.Tag {
+					found = s
+				}
+			}
+			return found == nil
+		})
+		return found
+	}
+	return nil
+}
+
+// findFunc returns the function (or "Type.Method") named name.
+func (e *goEditor) findFunc(name string) *ast.FuncDecl {
+	for _, decl := range e.file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		fnName := fn.Name.Name
+		if fn.Recv != nil && len(fn.Recv.List) > 0 {
+			fnName = goReceiverName(fn.Recv.List[0].Type) + "." + fnName
+		}
+		if fnName == name {
+			return fn
+		}
+	}
+	return nil
+}
+
+// goReceiverName returns the type name of a method receiver
+// (without any pointer or type parameters).
+func goReceiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return goReceiverName(t.X)
+	case *ast.IndexExpr:
+		return goReceiverName(t.X)
+	case *ast.IndexListExpr:
+		return goReceiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	default:
+		return ""
+	}
+}
+
+// typeSwitchTag returns the source of the expression
+// being switched on (i.e. `v` in `switch x := v.(type)`).
+func (e *goEditor) typeSwitchTag(s *ast.TypeSwitchStmt) string {
+	var expr ast.Expr
+	switch a := s.Assign.(type) {
+	case *ast.ExprStmt:
+		expr = a.X
+	case *ast.AssignStmt:
+		expr = a.Rhs[0]
+	}
+	if ta, ok := expr.(*ast.TypeAssertExpr); ok {
+		return e.text(ta.X.Pos(), ta.X.End())
+	}
+	return ""
+}
+
+func (e *goEditor) modifyNode(node ast.Node, action Action, src []byte, conf ModifierConf) error {
+	// The braces enclosing the contents of the node.
+	var lbrace, rbrace, before token.Pos
+	var wrap func(string) string
+	switch n := node.(type) {
+	case *ast.FuncDecl:
+		if n.Body == nil {
+			return fmt.Errorf("%w: func %s has no body", ErrInvalidGoTarget, n.Name.Name)
+		}
+		lbrace, rbrace = n.Body.Lbrace, n.Body.Rbrace
+		wrap = func(s string) string { return "package p\nfunc _() {\n" + s + "\n}\n" }
+	case *ast.StructType:
+		lbrace, rbrace = n.Fields.Opening, n.Fields.Closing
+		wrap = func(s string) string { return "package p\ntype _ struct {\n" + s + "\n}\n" }
+	case *ast.SwitchStmt, *ast.TypeSwitchStmt:
+		body := goSwitchBody(n)
+		lbrace, rbrace, before = body.Lbrace, body.Rbrace, goDefaultCase(body)
+		// Type switch cases also parse as expressions.
+		wrap = func(s string) string { return "package p\nfunc _() {\nswitch {\n" + s + "\n}\n}\n" }
+	}
+
+	if action == ActionDelete {
+		switch n := node.(type) {
+		case *ast.StructType:
+			e.deleteTypeSpec(n)
+		case *ast.FuncDecl:
+			start := n.Pos()
+			if n.Doc != nil {
+				start = n.Doc.Pos()
+			}
+			e.remove(start, n.End())
+		default:
+			e.remove(n.Pos(), n.End())
+		}
+		return nil
+	}
+
+	// Ensure the src content is valid before splicing it in.
+	text := strings.TrimSpace(string(src))
+	if _, err := parser.ParseFile(token.NewFileSet(), "", wrap(text), parser.ParseComments); err != nil {
+		return fmt.Errorf("go parse src: %w", err)
+	}
+
+	contents := e.text(lbrace+1, rbrace)
+	switch {
+	case conf.MergeType == MergeTypeReplace || action == ActionReplace:
+		e.replace(lbrace+1, rbrace, "\n"+text+"\n")
+	case conf.MergeType == MergeTypeUpsert && goContains(contents, text):
+		return nil
+	case action == ActionPrepend:
+		e.insert(lbrace+1, "\n"+text)
+	case action == ActionAppend && before.IsValid():
+		e.insert(before, text+"\n")
+	case action == ActionAppend:
+		e.insertBefore(rbrace, text)
+	}
+	return nil
+}
+
+// insertBefore inserts text on a new line before pos.
+func (e *goEditor) insertBefore(pos token.Pos, text string) {
+	offset := e.offset(pos)
+	start := bytes.LastIndexByte(e.src[:offset], '\n') + 1
+	if start > 0 && len(bytes.TrimSpace(e.src[start:offset])) == 0 {
+		// pos is at the start of a line.
+		e.edits = append(e.edits, goEdit{start: start, end: start, text: text + "\n"})
+		return
+	}
+	e.insert(pos, "\n"+text+"\n")
+}
+
+// deleteTypeSpec removes the type declaration of st.
+func (e *goEditor) deleteTypeSpec(st *ast.StructType) {
+	for _, decl := range e.file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			if spec.(*ast.TypeSpec).Type != st {
+				continue
+			}
+			if len(gen.Specs) > 1 {
+				e.remove(spec.Pos(), spec.End())
+			} else if gen.Doc != nil {
+				e.remove(gen.Doc.Pos(), gen.End())
+			} else {
+				e.remove(gen.Pos(), gen.End())
+			}
+			return
+		}
+	}
+}
+
+func goSwitchBody(node ast.Node) *ast.BlockStmt {
+	if s, ok := node.(*ast.TypeSwitchStmt); ok {
+		return s.Body
+	}
+	return node.(*ast.SwitchStmt).Body
+}
+
+// goDefaultCase returns the position of the default case in body
+// (or an invalid position if there is none).
+func goDefaultCase(body *ast.BlockStmt) token.Pos {
+	for _, stmt := range body.List {
+		if cc, ok := stmt.(*ast.CaseClause); ok && cc.List == nil {
+			return cc.Pos()
+		}
+	}
+	return token.NoPos
+}
+
+// goContains returns true if contents contains text (ignoring whitespace).
+func goContains(contents string, text string) bool {
+	return strings.Contains(
+		strings.Join(strings.Fields(contents), ""),
+		strings.Join(strings.Fields(text), ""),
+	)
+}
+
+// goImportSpecs parses src (one import per line, i.e. `"fmt"`, `fmt`,
+// or `log "github.com/sirupsen/logrus"`) into import specs.
+func goImportSpecs(src []byte) ([]*ast.ImportSpec, error) {
+	lines := []string{}
+	for _, line := range strings.Split(string(src), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.ContainsAny(line, "\"`") {
+			line = strconv.Quote(line)
+		}
+		lines = append(lines, line)
+	}
+	file, err := parser.ParseFile(
+		token.NewFileSet(), "", "package p\nimport (\n"+strings.Join(lines, "\n")+"\n)\n", parser.ImportsOnly,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("go parse src: %w", err)
+	}
+	return file.Imports, nil
+}
+
+func goImportString(spec *ast.ImportSpec) string {
+	if spec.Name != nil {
+		return spec.Name.Name + " " + spec.Path.Value
+	}
+	return spec.Path.Value
+}
+
+func goHasImport(specs []*ast.ImportSpec, spec *ast.ImportSpec) bool {
+	for _, s := range specs {
+		if s.Path.Value == spec.Path.Value && goImportString(s) == goImportString(spec) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *goEditor) modifyImports(action Action, src []byte) error {
+	specs, err := goImportSpecs(src)
+	if err != nil {
+		return err
+	}
+
+	decls := []*ast.GenDecl{}
+	for _, decl := range e.file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			decls = append(decls, gen)
+		}
+	}
+
+	existing := e.file.Imports
+	switch action {
+	case ActionDelete:
+		for _, gen := range decls {
+			kept := 0
+			for _, spec := range gen.Specs {
+				if !goHasImport(specs, spec.(*ast.ImportSpec)) && !goHasPath(specs, spec.(*ast.ImportSpec)) {
+					kept++
+				}
+			}
+			if kept == 0 {
+				e.remove(gen.Pos(), gen.End())
+				continue
+			}
+			for _, spec := range gen.Specs {
+				if goHasPath(specs, spec.(*ast.ImportSpec)) {
+					e.remove(spec.Pos(), spec.End())
+				}
+			}
+		}
+		return nil
+	case ActionReplace:
+		for _, gen := range decls {
+			e.remove(gen.Pos(), gen.End())
+		}
+		existing = nil
+		decls = nil
+	}
+
+	added := []string{}
+	for _, spec := range specs {
+		if !goHasImport(existing, spec) {
+			existing = append(existing, spec)
+			added = append(added, goImportString(spec))
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	for _, gen := range decls {
+		if gen.Lparen.IsValid() {
+			e.insert(gen.Rparen, strings.Join(added, "\n")+"\n")
+			return nil
+		}
+	}
+	if len(decls) > 0 {
+		// Group a single import (without parens) with the added ones.
+		spec := decls[0].Specs[0]
+		added = append([]string{e.text(spec.Pos(), spec.End())}, added...)
+		e.replace(decls[0].Pos(), decls[0].End(), "import (\n"+strings.Join(added, "\n")+"\n)")
+	} else {
+		e.insert(e.file.Name.End(), "\n\nimport (\n"+strings.Join(added, "\n")+"\n)")
+	}
+	return nil
+}
+
+// goHasPath returns true if specs contains an import of the same path.
+func goHasPath(specs []*ast.ImportSpec, spec *ast.ImportSpec) bool {
+	for _, s := range specs {
+		if s.Path.Value == spec.Path.Value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,310 @@
+package modify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const goDoc = `package main
+
+import "fmt"
+
+// Config is the app config.
+type Config struct {
+	Name string // The app name.
+}
+
+type Server struct{}
+
+func (s *Server) Routes() {
+	// Routes are registered here.
+	s.handle("/")
+}
+
+func run(cmd string) {
+	switch cmd {
+	case "start":
+		fmt.Println("starting")
+	default:
+		fmt.Println("unknown")
+	}
+}
+
+func main() {
+	run("start")
+}
+`
+
+func TestParseGoTarget(t *testing.T) {
+	target, err := ParseGoTarget("switch:run:cmd")
+	assert.NoError(t, err)
+	assert.Equal(t, GoTarget{Kind: "switch", Name: "run", Tag: "cmd"}, target)
+
+	target, err = ParseGoTarget("func:Server.Routes")
+	assert.NoError(t, err)
+	assert.Equal(t, GoTarget{Kind: "func", Name: "Server.Routes"}, target)
+
+	for _, pattern := range []string{"", "import:fmt", "func", "struct:", "const:Foo"} {
+		_, err = ParseGoTarget(pattern)
+		assert.ErrorIs(t, err, ErrInvalidGoTarget, pattern)
+	}
+}
+
+func TestGoSource(t *testing.T) {
+	type args struct {
+		dst    string
+		target string
+		action Action
+		src    string
+		conf   ModifierConf
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+		err  string
+	}{
+		{
+			name: "import: groups a single import declaration with the added imports",
+			args: args{
+				dst:    goDoc,
+				target: "import",
+				action: ActionAppend,
+				src:    "os\nfmt\nlog \"github.com/sirupsen/logrus\"\n",
+			},
+			want: `package main
+
+import (
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"os"
+)
+`,
+		},
+		{
+			name: "import: adds imports to a grouped import declaration (sorted)",
+			args: args{
+				dst:    "package main\n\nimport (\n\t\"os\"\n)\n",
+				target: "import",
+				action: ActionPrepend,
+				src:    `"fmt"`,
+			},
+			want: "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n",
+		},
+		{
+			name: "import: adds an import declaration when there is none",
+			args: args{
+				dst:    "package main\n\nfunc main() {}\n",
+				target: "import",
+				action: ActionAppend,
+				src:    "fmt",
+			},
+			want: "package main\n\nimport (\n\t\"fmt\"\n)\n\nfunc main() {}\n",
+		},
+		{
+			name: "import: deletes imports",
+			args: args{
+				dst:    "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n",
+				target: "import",
+				action: ActionDelete,
+				src:    "os",
+			},
+			want: "package main\n\nimport (\n\t\"fmt\"\n)\n",
+		},
+		{
+			name: "func: appends statements to the function body",
+			args: args{
+				dst:    goDoc,
+				target: "func:Server.Routes",
+				action: ActionAppend,
+				src:    `s.handle("/users")`,
+			},
+			want: "func (s *Server) Routes() {\n" +
+				"\t// Routes are registered here.\n" +
+				"\ts.handle(\"/\")\n" +
+				"\ts.handle(\"/users\")\n" +
+				"}\n",
+		},
+		{
+			name: "func: prepends statements to the function body",
+			args: args{
+				dst:    goDoc,
+				target: "func:main",
+				action: ActionPrepend,
+				src:    `  fmt.Println("hello")`,
+			},
+			want: "func main() {\n" +
+				"\tfmt.Println(\"hello\")\n" +
+				"\trun(\"start\")\n" +
+				"}\n",
+		},
+		{
+			name: "func(upsert): is noop when src already present",
+			args: args{
+				dst:    goDoc,
+				target: "func:main",
+				action: ActionAppend,
+				src:    `run( "start" )`,
+				conf: ModifierConf{
+					MergeType: MergeTypeUpsert,
+				},
+			},
+			want: goDoc,
+		},
+		{
+			name: "struct: appends fields",
+			args: args{
+				dst:    goDoc,
+				target: "struct:Config",
+				action: ActionAppend,
+				src:    "Port int `json:\"port\"`",
+			},
+			want: "// Config is the app config.\n" +
+				"type Config struct {\n" +
+				"\tName string // The app name.\n" +
+				"\tPort int    `json:\"port\"`\n" +
+				"}\n",
+		},
+		{
+			name: "struct: replaces fields",
+			args: args{
+				dst:    goDoc,
+				target: "struct:Server",
+				action: ActionReplace,
+				src:    "addr string",
+			},
+			want: "type Server struct {\n\taddr string\n}\n",
+		},
+		{
+			name: "switch: adds cases before the default case",
+			args: args{
+				dst:    goDoc,
+				target: "switch:run:cmd",
+				action: ActionAppend,
+				src:    "case \"stop\":\nfmt.Println(\"stopping\")",
+			},
+			want: "\tswitch cmd {\n" +
+				"\tcase \"start\":\n" +
+				"\t\tfmt.Println(\"starting\")\n" +
+				"\tcase \"stop\":\n" +
+				"\t\tfmt.Println(\"stopping\")\n" +
+				"\tdefault:\n",
+		},
+		{
+			name: "switch: prepends cases to type switches",
+			args: args{
+				dst:    "package main\n\nfunc kind(v any) string {\n\tswitch v.(type) {\n\tcase int:\n\t\treturn \"int\"\n\t}\n\treturn \"\"\n}\n",
+				target: "switch:kind:v",
+				action: ActionPrepend,
+				src:    "case string:\n\treturn \"string\"\n",
+			},
+			want: "\tswitch v.(type) {\n" +
+				"\tcase string:\n" +
+				"\t\treturn \"string\"\n" +
+				"\tcase int:\n",
+		},
+		{
+			name: "delete: removes the function (including doc comments)",
+			args: args{
+				dst:    "package main\n\n// Foo does foo.\nfunc Foo() {}\n\nfunc main() {}\n",
+				target: "func:Foo",
+				action: ActionDelete,
+			},
+			want: "package main\n\nfunc main() {}\n",
+		},
+		{
+			name: "delete: removes the struct type",
+			args: args{
+				dst:    goDoc,
+				target: "struct:Config",
+				action: ActionDelete,
+			},
+			want: "import \"fmt\"\n\ntype Server struct{}\n",
+		},
+		{
+			name: "returns an error when the target is missing",
+			args: args{
+				dst:    goDoc,
+				target: "switch:main",
+				action: ActionAppend,
+				src:    "case 1:",
+			},
+			err: "go target not found",
+		},
+		{
+			name: "returns an error when the struct is missing",
+			args: args{
+				dst:    goDoc,
+				target: "struct:Missing",
+				action: ActionDelete,
+			},
+			err: "go target not found",
+		},
+		{
+			name: "returns an error when src is invalid",
+			args: args{
+				dst:    goDoc,
+				target: "func:main",
+				action: ActionAppend,
+				src:    "func {",
+			},
+			err: "go parse src",
+		},
+		{
+			name: "returns an error when dst is invalid",
+			args: args{
+				dst:    "package main\n\nfunc main() {\n",
+				target: "func:main",
+				action: ActionAppend,
+				src:    "run()",
+			},
+			err: "go parse",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := GoSource(
+				[]byte(tt.args.dst), tt.args.target, tt.args.action, []byte(tt.args.src), tt.args.conf,
+			)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Contains(t, string(actual), tt.want)
+		})
+	}
+}
+
+func TestHasGoTarget(t *testing.T) {
+	for target, want := range map[string]bool{
+		"import":             true,
+		"func:run":           true,
+		"func:Routes":        false,
+		"func:Server.Routes": true,
+		"struct:Config":      true,
+		"struct:Missing":     false,
+		"switch:run:cmd":     true,
+		"switch:run:other":   false,
+	} {
+		found, err := HasGoTarget([]byte(goDoc), target, []byte("fmt"))
+		assert.NoError(t, err)
+		assert.Equal(t, want, found, target)
+	}
+
+	// Imports are only present if all of the imports in src are.
+	for src, want := range map[string]bool{
+		"fmt":              true,
+		"\"fmt\"\nos":      false,
+		"f \"fmt\"":        false,
+		"":                 false,
+		"\"fmt\"\n\"fmt\"": true,
+	} {
+		found, err := HasGoTarget([]byte(goDoc), "import", []byte(src))
+		assert.NoError(t, err)
+		assert.Equal(t, want, found, src)
+	}
+	_, err := HasGoTarget([]byte(goDoc), "import", []byte("\"fmt"))
+	assert.ErrorContains(t, err, "go parse src")
+}
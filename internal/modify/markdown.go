@@ -0,0 +1,330 @@
+package modify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrInvalidHeadingPath      = errors.New("invalid heading path")
+	ErrMarkdownSectionNotFound = errors.New("markdown section not found")
+
+	markdownHeadingPattern   = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	markdownUnderlinePattern = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	markdownBlockPattern     = regexp.MustCompile(`^ {0,3}(?:[-*+>]|\d{1,9}[.)])(?:[ \t]|$)`)
+	markdownFencePattern     = regexp.MustCompile("^ {0,3}(```|~~~)")
+	markdownSegmentPattern   = regexp.MustCompile(`^(#*)\s*(.*)$`)
+)
+
+// MarkdownHeading is a single segment of a heading path.
+// Level is zero when the segment matches headings of any level.
+type MarkdownHeading struct {
+	Level int
+	Title string
+}
+
+// ParseMarkdownPath parses a heading path (i.e. "## Usage > ### Flags")
+// into its segments. The leading `#`s of each segment are optional.
+func ParseMarkdownPath(path string) ([]MarkdownHeading, error) {
+	headings := []MarkdownHeading{}
+	for _, segment := range strings.Split(path, ">") {
+		m := markdownSegmentPattern.FindStringSubmatch(strings.TrimSpace(segment))
+		if len(m[1]) > 6 || m[2] == "" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidHeadingPath, path)
+		}
+		headings = append(headings, MarkdownHeading{Level: len(m[1]), Title: m[2]})
+	}
+	return headings, nil
+}
+
+// MarkdownSection modifies the body of each section in dst
+// addressed by path (a heading path, see [ParseMarkdownPath]).
+//
+//   - Prepend and append add src to the start or end of the body
+//     (separated by a blank line).
+//   - Replace substitutes the body with src.
+//   - Delete removes the section (including the heading and any subsections).
+//
+// The body of a section ends at its first subsection,
+// so subsections are left as-is (unless the section is deleted).
+// Sections in fenced code blocks (or YAML front matter) are ignored.
+// Returns ErrMarkdownSectionNotFound if no section matches path.
+func MarkdownSection(dst []byte, path string, action Action, src []byte, conf ModifierConf) ([]byte, error) {
+	headings, err := ParseMarkdownPath(path)
+	if err != nil {
+		return nil, err
+	}
+	sections := findMarkdownSections(dst, headings)
+	if len(sections) == 0 {
+		return nil, fmt.Errorf("%w: %q", ErrMarkdownSectionNotFound, path)
+	}
+	// Modify from the end so that the offsets of earlier sections are unchanged.
+	sort.Slice(sections, func(i, j int) bool {
+		return sections[i].start > sections[j].start
+	})
+	result := bytes.Clone(dst)
+	for _, s := range sections {
+		result = modifyMarkdownSection(result, s, action, src, conf)
+	}
+	return result, nil
+}
+
+// HasMarkdownSection returns true if dst contains a section
+// addressed by path.
+func HasMarkdownSection(dst []byte, path string) (bool, error) {
+	headings, err := ParseMarkdownPath(path)
+	if err != nil {
+		return false, err
+	}
+	return len(findMarkdownSections(dst, headings)) > 0, nil
+}
+
+// AddMarkdownSection adds any missing sections in path to dst
+// (using body as the content of the last one). Missing sections are
+// appended to the end of their parent section. Segments without
+// a level are one level below their parent.
+func AddMarkdownSection(dst []byte, path string, body []byte) ([]byte, error) {
+	headings, err := ParseMarkdownPath(path)
+	if err != nil {
+		return nil, err
+	}
+	all := markdownHeadings(dst)
+	parent := markdownSection{end: len(dst)}
+	for i, heading := range headings {
+		children := childMarkdownSections(all, parent, heading)
+		if len(children) == 0 {
+			return insertMarkdownSections(dst, parent, headings[i:], body), nil
+		}
+		parent = children[0]
+	}
+	return dst, nil
+}
+
+type markdownSection struct {
+	level     int
+	title     string
+	start     int // offset of the heading line(s)
+	bodyStart int // offset after the heading line(s)
+	bodyEnd   int // offset of the first subsection (or end)
+	end       int // offset of the next heading at the same (or a higher) level
+}
+
+// markdownHeadings returns the (ATX and setext) headings in dst,
+// ignoring any inside fenced code blocks or YAML front matter.
+func markdownHeadings(dst []byte) []markdownSection {
+	headings := []markdownSection{}
+	fence := ""
+	frontMatter := false
+	// The lines of the current paragraph (a setext heading if underlined).
+	paragraph := []string{}
+	paragraphStart := 0
+	offset := 0
+	for i, line := range strings.SplitAfter(string(dst), "\n") {
+		start := offset
+		offset += len(line)
+		text := strings.TrimRight(line, "\r\n")
+		if i == 0 && text == "---" {
+			frontMatter = true
+			continue
+		}
+		if frontMatter {
+			frontMatter = text != "---" && text != "..."
+			continue
+		}
+		if m := markdownFencePattern.FindStringSubmatch(text); m != nil {
+			if fence == "" {
+				fence = m[1]
+			} else if fence == m[1] {
+				fence = ""
+			}
+			paragraph = nil
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+		if m := markdownHeadingPattern.FindStringSubmatch(text); m != nil {
+			headings = append(headings, markdownSection{
+				level:     len(m[1]),
+				title:     strings.TrimSpace(m[2]),
+				start:     start,
+				bodyStart: offset,
+			})
+			paragraph = nil
+			continue
+		}
+		if m := markdownUnderlinePattern.FindStringSubmatch(text); m != nil {
+			// Without a paragraph to underline, this is a thematic break.
+			if len(paragraph) > 0 {
+				level := 1
+				if m[1][0] == '-' {
+					level = 2
+				}
+				headings = append(headings, markdownSection{
+					level:     level,
+					title:     strings.Join(paragraph, " "),
+					start:     paragraphStart,
+					bodyStart: offset,
+				})
+			}
+			paragraph = nil
+			continue
+		}
+		switch {
+		case strings.TrimSpace(text) == "" || markdownBlockPattern.MatchString(text):
+			// Blank lines end a paragraph, and list items and block quotes
+			// can not be underlined (i.e. a following "---" is a thematic break).
+			paragraph = nil
+		case len(paragraph) == 0 && strings.HasPrefix(strings.ReplaceAll(text, "\t", "    "), "    "):
+			// Indented code.
+		default:
+			if len(paragraph) == 0 {
+				paragraphStart = start
+			}
+			paragraph = append(paragraph, strings.TrimSpace(text))
+		}
+	}
+	for i := range headings {
+		headings[i].end = len(dst)
+		for _, next := range headings[i+1:] {
+			if next.level <= headings[i].level {
+				headings[i].end = next.start
+				break
+			}
+		}
+		headings[i].bodyEnd = headings[i].end
+		if i+1 < len(headings) && headings[i+1].start < headings[i].end {
+			headings[i].bodyEnd = headings[i+1].start
+		}
+	}
+	return headings
+}
+
+// findMarkdownSections returns the sections in dst matching
+// the heading path (each segment nested in the previous one).
+func findMarkdownSections(dst []byte, path []MarkdownHeading) []markdownSection {
+	all := markdownHeadings(dst)
+	parents := []markdownSection{{end: len(dst)}}
+	for _, heading := range path {
+		matches := []markdownSection{}
+		for _, parent := range parents {
+			matches = append(matches, childMarkdownSections(all, parent, heading)...)
+		}
+		parents = matches
+	}
+	return parents
+}
+
+// childMarkdownSections returns the sections nested in parent
+// (at any depth) that match heading.
+func childMarkdownSections(all []markdownSection, parent markdownSection, heading MarkdownHeading) []markdownSection {
+	matches := []markdownSection{}
+	for _, s := range all {
+		if s.start < parent.bodyStart || s.start >= parent.end || s.level <= parent.level {
+			continue
+		}
+		if heading.Level > 0 && heading.Level != s.level {
+			continue
+		}
+		if strings.EqualFold(heading.Title, s.title) {
+			matches = append(matches, s)
+		}
+	}
+	return matches
+}
+
+func modifyMarkdownSection(dst []byte, s markdownSection, action Action, src []byte, conf ModifierConf) []byte {
+	if action == ActionDelete {
+		head := dst[:s.start]
+		if s.end == len(dst) {
+			// Don't leave trailing blank lines at the end of the file.
+			head = bytes.TrimRight(head, " \t\r\n")
+			if len(head) > 0 {
+				head = concatBytes(head, []byte("\n"))
+			}
+		}
+		return concatBytes(head, dst[s.end:])
+	}
+
+	// Paragraphs are separated by a blank line.
+	atEnd := s.bodyEnd == len(dst)
+	body := trimBlankLines(dst[s.bodyStart:s.bodyEnd])
+	src = trimBlankLines(src)
+	separator := []byte("\n\n")
+	switch action {
+	case ActionPrepend:
+		body = PrependBytes(concatBytes(body, separator), concatBytes(src, separator), conf)
+	case ActionAppend:
+		body = AppendBytes(concatBytes(separator, body), concatBytes(separator, src), conf)
+	case ActionReplace:
+		body = src
+	}
+	body = trimBlankLines(body)
+
+	result := bytes.Clone(dst[:s.bodyStart])
+	if !bytes.HasSuffix(result, []byte("\n")) {
+		result = append(result, '\n')
+	}
+	if len(body) > 0 {
+		result = append(result, '\n')
+		result = append(result, body...)
+		result = append(result, '\n')
+	}
+	if !atEnd {
+		result = append(result, '\n')
+	}
+	return append(result, dst[s.bodyEnd:]...)
+}
+
+// insertMarkdownSections inserts headings (nested under parent)
+// at the end of parent.
+func insertMarkdownSections(dst []byte, parent markdownSection, headings []MarkdownHeading, body []byte) []byte {
+	result := bytes.Clone(dst[:parent.end])
+	result = bytes.TrimRight(result, " \t\r\n")
+	if len(result) > 0 {
+		result = append(result, "\n\n"...)
+	}
+	level := parent.level
+	for i, heading := range headings {
+		if heading.Level > 0 {
+			level = heading.Level
+		} else {
+			level++
+		}
+		if i > 0 {
+			result = append(result, '\n')
+		}
+		result = append(result, strings.Repeat("#", level)+" "+heading.Title+"\n"...)
+	}
+	if body = trimBlankLines(body); len(body) > 0 {
+		result = append(result, '\n')
+		result = append(result, body...)
+		result = append(result, '\n')
+	}
+	if parent.end < len(dst) {
+		result = append(result, '\n')
+	}
+	return append(result, dst[parent.end:]...)
+}
+
+// trimBlankLines removes leading blank lines and trailing whitespace.
+func trimBlankLines(b []byte) []byte {
+	b = bytes.TrimRight(b, " \t\r\n")
+	for {
+		i := bytes.IndexByte(b, '\n')
+		if i < 0 || len(bytes.TrimSpace(b[:i])) > 0 {
+			return b
+		}
+		b = b[i+1:]
+	}
+}
+
+func concatBytes(a, b []byte) []byte {
+	result := make([]byte, 0, len(a)+len(b))
+	result = append(result, a...)
+	return append(result, b...)
+}
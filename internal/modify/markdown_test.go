@@ -0,0 +1,255 @@
+package modify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const markdownDoc = "# Example\n" +
+	"\n" +
+	"Intro.\n" +
+	"\n" +
+	"## Usage\n" +
+	"\n" +
+	"Run it.\n" +
+	"\n" +
+	"### Flags\n" +
+	"\n" +
+	"- `--help`\n" +
+	"\n" +
+	"```sh\n" +
+	"# Not a heading\n" +
+	"```\n" +
+	"\n" +
+	"## License\n" +
+	"\n" +
+	"MIT\n"
+
+func TestParseMarkdownPath(t *testing.T) {
+	headings, err := ParseMarkdownPath("## Usage > Flags")
+	assert.NoError(t, err)
+	assert.Equal(t, []MarkdownHeading{
+		{Level: 2, Title: "Usage"},
+		{Level: 0, Title: "Flags"},
+	}, headings)
+
+	_, err = ParseMarkdownPath("## Usage > ")
+	assert.ErrorIs(t, err, ErrInvalidHeadingPath)
+	_, err = ParseMarkdownPath("####### Usage")
+	assert.ErrorIs(t, err, ErrInvalidHeadingPath)
+}
+
+func TestMarkdownSection(t *testing.T) {
+	type args struct {
+		dst    string
+		path   string
+		action Action
+		src    string
+		conf   ModifierConf
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+		err  error
+	}{
+		{
+			name: "prepend: adds src to the start of the section body",
+			args: args{
+				dst:    markdownDoc,
+				path:   "## Usage",
+				action: ActionPrepend,
+				src:    "Install it.\n",
+			},
+			want: "# Example\n\nIntro.\n\n" +
+				"## Usage\n\nInstall it.\n\nRun it.\n\n" +
+				"### Flags\n\n- `--help`\n\n```sh\n# Not a heading\n```\n\n" +
+				"## License\n\nMIT\n",
+		},
+		{
+			name: "append: adds src to the end of the section body",
+			args: args{
+				dst:    markdownDoc,
+				path:   "## Usage > ### Flags",
+				action: ActionAppend,
+				src:    "- `--version`",
+			},
+			want: "# Example\n\nIntro.\n\n" +
+				"## Usage\n\nRun it.\n\n" +
+				"### Flags\n\n- `--help`\n\n```sh\n# Not a heading\n```\n\n- `--version`\n\n" +
+				"## License\n\nMIT\n",
+		},
+		{
+			name: "append(upsert): is noop when src already present",
+			args: args{
+				dst:    markdownDoc,
+				path:   "License",
+				action: ActionAppend,
+				src:    "MIT",
+				conf: ModifierConf{
+					MergeType: MergeTypeUpsert,
+				},
+			},
+			want: markdownDoc,
+		},
+		{
+			name: "append: adds src before the first subsection",
+			args: args{
+				dst:    markdownDoc,
+				path:   "## Usage",
+				action: ActionAppend,
+				src:    "Or build it.",
+			},
+			want: "# Example\n\nIntro.\n\n" +
+				"## Usage\n\nRun it.\n\nOr build it.\n\n" +
+				"### Flags\n\n- `--help`\n\n```sh\n# Not a heading\n```\n\n" +
+				"## License\n\nMIT\n",
+		},
+		{
+			name: "replace: replaces the section body (keeping subsections)",
+			args: args{
+				dst:    markdownDoc,
+				path:   "usage",
+				action: ActionReplace,
+				src:    "See the docs.",
+			},
+			want: "# Example\n\nIntro.\n\n" +
+				"## Usage\n\nSee the docs.\n\n" +
+				"### Flags\n\n- `--help`\n\n```sh\n# Not a heading\n```\n\n" +
+				"## License\n\nMIT\n",
+		},
+		{
+			name: "replace: adds a body to sections that start with a subsection",
+			args: args{
+				dst:    "# Example\n## Usage\n",
+				path:   "# Example",
+				action: ActionReplace,
+				src:    "Intro.",
+			},
+			want: "# Example\n\nIntro.\n\n## Usage\n",
+		},
+		{
+			name: "delete: removes the section (including subsections)",
+			args: args{
+				dst:    markdownDoc,
+				path:   "## Usage",
+				action: ActionDelete,
+			},
+			want: "# Example\n\nIntro.\n\n" +
+				"## License\n\nMIT\n",
+		},
+		{
+			name: "delete: removes the section",
+			args: args{
+				dst:    markdownDoc,
+				path:   "## License",
+				action: ActionDelete,
+			},
+			want: "# Example\n\nIntro.\n\n" +
+				"## Usage\n\nRun it.\n\n" +
+				"### Flags\n\n- `--help`\n\n```sh\n# Not a heading\n```\n",
+		},
+		{
+			name: "replace: replaces the body of setext sections",
+			args: args{
+				dst:    "Example\n=======\n\nUsage\n-----\n\nRun it.\n\nLicense\n-------\n\nMIT\n",
+				path:   "# Example > ## Usage",
+				action: ActionReplace,
+				src:    "See the docs.",
+			},
+			want: "Example\n=======\n\nUsage\n-----\n\nSee the docs.\n\nLicense\n-------\n\nMIT\n",
+		},
+		{
+			name: "delete: removes setext sections (including multi-line headings)",
+			args: args{
+				dst:    "# Example\n\nKnown\nissues\n------\n\n- None\n\n## License\n\nMIT\n",
+				path:   "## Known issues",
+				action: ActionDelete,
+			},
+			want: "# Example\n\n## License\n\nMIT\n",
+		},
+		{
+			name: "replace: ignores thematic breaks and front matter",
+			args: args{
+				dst:    "---\ntitle: Example\n---\n\n# Example\n\n- item\n---\n\nIntro.\n",
+				path:   "# Example",
+				action: ActionReplace,
+				src:    "Intro.",
+			},
+			want: "---\ntitle: Example\n---\n\n# Example\n\nIntro.\n",
+		},
+		{
+			name: "returns an error when the section is missing",
+			args: args{
+				dst:    markdownDoc,
+				path:   "## Flags > Not a heading",
+				action: ActionReplace,
+				src:    "foo",
+			},
+			err: ErrMarkdownSectionNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := MarkdownSection(
+				[]byte(tt.args.dst), tt.args.path, tt.args.action, []byte(tt.args.src), tt.args.conf,
+			)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(actual))
+		})
+	}
+}
+
+func TestAddMarkdownSection(t *testing.T) {
+	tests := []struct {
+		name string
+		dst  string
+		path string
+		body string
+		want string
+	}{
+		{
+			name: "is noop when the section exists",
+			dst:  markdownDoc,
+			path: "## Usage > ### Flags",
+			body: "foo",
+			want: markdownDoc,
+		},
+		{
+			name: "adds missing sections to the end of the parent",
+			dst:  markdownDoc,
+			path: "## Usage > ### Examples",
+			body: "None yet.",
+			want: "# Example\n\nIntro.\n\n" +
+				"## Usage\n\nRun it.\n\n" +
+				"### Flags\n\n- `--help`\n\n```sh\n# Not a heading\n```\n\n" +
+				"### Examples\n\nNone yet.\n\n" +
+				"## License\n\nMIT\n",
+		},
+		{
+			name: "nests sections without a level under their parent",
+			dst:  "# Changelog\n",
+			path: "# Changelog > Unreleased > Added",
+			want: "# Changelog\n\n## Unreleased\n\n### Added\n",
+		},
+		{
+			name: "adds sections to empty documents",
+			dst:  "",
+			path: "## Usage",
+			body: "Run it.\n",
+			want: "## Usage\n\nRun it.\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := AddMarkdownSection([]byte(tt.dst), tt.path, []byte(tt.body))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(actual))
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package modify
+
+import (
+	"strings"
+
+	"github.com/beevik/etree"
+)
+
+const xmlIndent = "  "
+
+// XMLElement modifies the dst element using the src tokens
+// (elements and comments).
+//
+//   - Prepend and append insert src as the first or last children of dst.
+//   - Replace substitutes dst with src.
+//   - Delete removes dst.
+//
+// Inserted tokens are indented to match the surrounding elements.
+func XMLElement(dst *etree.Element, action Action, src []etree.Token, conf ModifierConf) {
+	switch action {
+	case ActionPrepend:
+		PrependXMLElement(dst, src, conf)
+	case ActionAppend:
+		AppendXMLElement(dst, src, conf)
+	case ActionReplace:
+		ReplaceXMLElement(dst, src)
+	case ActionDelete:
+		DeleteXMLElement(dst)
+	}
+}
+
+func PrependXMLElement(dst *etree.Element, src []etree.Token, conf ModifierConf) {
+	src = mergeXMLChildren(dst, src, conf)
+	children := dst.ChildElements()
+	if len(children) == 0 {
+		insertXMLChildren(dst, src)
+		return
+	}
+	first := children[0]
+	indent := xmlLeadingIndent(first)
+	indented := xmlIsIndented(first)
+	index := first.Index()
+	for _, token := range src {
+		dst.InsertChildAt(index, copyXMLToken(token, indent))
+		index++
+		if indented {
+			dst.InsertChildAt(index, etree.NewCharData("\n"+indent))
+			index++
+		}
+	}
+}
+
+func AppendXMLElement(dst *etree.Element, src []etree.Token, conf ModifierConf) {
+	src = mergeXMLChildren(dst, src, conf)
+	children := dst.ChildElements()
+	if len(children) == 0 {
+		insertXMLChildren(dst, src)
+		return
+	}
+	last := children[len(children)-1]
+	indent := xmlLeadingIndent(last)
+	indented := xmlIsIndented(last)
+	index := last.Index() + 1
+	for _, token := range src {
+		if indented {
+			dst.InsertChildAt(index, etree.NewCharData("\n"+indent))
+			index++
+		}
+		dst.InsertChildAt(index, copyXMLToken(token, indent))
+		index++
+	}
+}
+
+func ReplaceXMLElement(dst *etree.Element, src []etree.Token) {
+	parent := dst.Parent()
+	if parent == nil {
+		return
+	}
+	indent := xmlLeadingIndent(dst)
+	indented := xmlIsIndented(dst)
+	index := dst.Index()
+	parent.RemoveChildAt(index)
+	for i, token := range src {
+		if i > 0 && indented {
+			parent.InsertChildAt(index, etree.NewCharData("\n"+indent))
+			index++
+		}
+		parent.InsertChildAt(index, copyXMLToken(token, indent))
+		index++
+	}
+}
+
+func DeleteXMLElement(dst *etree.Element) {
+	parent := dst.Parent()
+	if parent == nil {
+		return
+	}
+	index := dst.Index()
+	parent.RemoveChildAt(index)
+	// Also remove the indentation preceding the element.
+	if index > 0 {
+		if cd, ok := parent.Child[index-1].(*etree.CharData); ok && cd.IsWhitespace() {
+			parent.RemoveChildAt(index - 1)
+		}
+	}
+}
+
+// mergeXMLChildren returns the src tokens to add to dst (per the merge type).
+// Replace removes the existing children of dst, and upsert skips any
+// src elements that are already children of dst.
+func mergeXMLChildren(dst *etree.Element, src []etree.Token, conf ModifierConf) []etree.Token {
+	switch conf.MergeType {
+	case MergeTypeReplace:
+		for _, child := range dst.ChildElements() {
+			DeleteXMLElement(child)
+		}
+		return src
+	case MergeTypeUpsert:
+		existing := NewSet()
+		for _, child := range dst.ChildElements() {
+			existing.Add(xmlString(child))
+		}
+		merged := []etree.Token{}
+		for _, token := range src {
+			if e, ok := token.(*etree.Element); ok && existing.Contains(xmlString(e)) {
+				continue
+			}
+			merged = append(merged, token)
+		}
+		return merged
+	default: // case MergeTypeConcat:
+		return src
+	}
+}
+
+// insertXMLChildren adds src to dst, which has no child elements.
+func insertXMLChildren(dst *etree.Element, src []etree.Token) {
+	if len(src) == 0 {
+		return
+	}
+	if strings.TrimSpace(dst.Text()) != "" {
+		// Mixed content: append without any indentation.
+		for _, token := range src {
+			dst.AddChild(copyXMLToken(token, ""))
+		}
+		return
+	}
+	for len(dst.Child) > 0 {
+		dst.RemoveChildAt(0)
+	}
+	outer := xmlLeadingIndent(dst)
+	inner := outer + xmlIndentUnit(dst)
+	for _, token := range src {
+		dst.AddChild(etree.NewCharData("\n" + inner))
+		dst.AddChild(copyXMLToken(token, inner))
+	}
+	dst.AddChild(etree.NewCharData("\n" + outer))
+}
+
+// copyXMLToken returns a copy of token, with each line indented by indent.
+func copyXMLToken(token etree.Token, indent string) etree.Token {
+	switch t := token.(type) {
+	case *etree.Element:
+		e := t.Copy()
+		reindentXMLElement(e, indent)
+		return e
+	case *etree.Comment:
+		return etree.NewComment(t.Data)
+	default:
+		return etree.NewCharData("")
+	}
+}
+
+func reindentXMLElement(e *etree.Element, indent string) {
+	for _, child := range e.Child {
+		switch c := child.(type) {
+		case *etree.CharData:
+			if c.IsWhitespace() && strings.Contains(c.Data, "\n") {
+				c.SetData(strings.ReplaceAll(c.Data, "\n", "\n"+indent))
+			}
+		case *etree.Element:
+			reindentXMLElement(c, indent)
+		}
+	}
+}
+
+// xmlLeadingIndent returns the whitespace between e
+// and the preceding newline (or an empty string).
+func xmlLeadingIndent(e *etree.Element) string {
+	parent := e.Parent()
+	index := e.Index()
+	if parent == nil || index == 0 {
+		return ""
+	}
+	cd, ok := parent.Child[index-1].(*etree.CharData)
+	if !ok || !cd.IsWhitespace() {
+		return ""
+	}
+	if i := strings.LastIndex(cd.Data, "\n"); i >= 0 {
+		return cd.Data[i+1:]
+	}
+	return ""
+}
+
+// xmlIsIndented returns true if e is on its own line.
+func xmlIsIndented(e *etree.Element) bool {
+	parent := e.Parent()
+	index := e.Index()
+	if parent == nil || index == 0 {
+		return false
+	}
+	cd, ok := parent.Child[index-1].(*etree.CharData)
+	return ok && cd.IsWhitespace() && strings.Contains(cd.Data, "\n")
+}
+
+// xmlIndentUnit returns the whitespace used to indent each level
+// of the document containing e (defaulting to two spaces).
+func xmlIndentUnit(e *etree.Element) string {
+	for ancestor := e; ancestor != nil; ancestor = ancestor.Parent() {
+		outer := xmlLeadingIndent(ancestor)
+		for _, child := range ancestor.ChildElements() {
+			inner := xmlLeadingIndent(child)
+			if xmlIsIndented(child) && len(inner) > len(outer) && strings.HasPrefix(inner, outer) {
+				return inner[len(outer):]
+			}
+		}
+	}
+	return xmlIndent
+}
+
+// xmlString returns e serialized without any indentation.
+func xmlString(e *etree.Element) string {
+	doc := etree.NewDocumentWithRoot(e.Copy())
+	doc.Unindent()
+	s, _ := doc.WriteToString()
+	return s
+}
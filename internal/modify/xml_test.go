@@ -0,0 +1,135 @@
+package modify
+
+import (
+	"testing"
+
+	"github.com/beevik/etree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestXMLElement(t *testing.T) {
+	type args struct {
+		dst    string
+		path   string
+		action Action
+		src    string
+		conf   ModifierConf
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "prepend: inserts src before the first child of dst",
+			args: args{
+				dst:    "<a>\n  <b/>\n</a>",
+				path:   "/a",
+				action: ActionPrepend,
+				src:    "<c/>",
+			},
+			want: "<a>\n  <c/>\n  <b/>\n</a>",
+		},
+		{
+			name: "prepend: indents src when dst has no children",
+			args: args{
+				dst:    "<root>\n    <a/>\n</root>",
+				path:   "/root/a",
+				action: ActionPrepend,
+				src:    "<c>\n  <d/>\n</c>",
+			},
+			want: "<root>\n    <a>\n        <c>\n          <d/>\n        </c>\n    </a>\n</root>",
+		},
+		{
+			name: "append: inserts src after the last child of dst",
+			args: args{
+				dst:    "<a>\n  <b/>\n</a>",
+				path:   "/a",
+				action: ActionAppend,
+				src:    "<!-- c --><c/>",
+			},
+			want: "<a>\n  <b/>\n  <!-- c -->\n  <c/>\n</a>",
+		},
+		{
+			name: "append: keeps unindented children on the same line",
+			args: args{
+				dst:    "<a><b/></a>",
+				path:   "/a",
+				action: ActionAppend,
+				src:    "<c/>",
+			},
+			want: "<a><b/><c/></a>",
+		},
+		{
+			name: "append(upsert): skips src elements already in dst",
+			args: args{
+				dst:    "<a>\n  <b x=\"1\"/>\n</a>",
+				path:   "/a",
+				action: ActionAppend,
+				src:    "<b x=\"1\"/><b x=\"2\"/>",
+				conf: ModifierConf{
+					MergeType: MergeTypeUpsert,
+				},
+			},
+			want: "<a>\n  <b x=\"1\"/>\n  <b x=\"2\"/>\n</a>",
+		},
+		{
+			name: "append(replace): replaces the children of dst with src",
+			args: args{
+				dst:    "<a>\n  <b/>\n  <c/>\n</a>",
+				path:   "/a",
+				action: ActionAppend,
+				src:    "<d/>",
+				conf: ModifierConf{
+					MergeType: MergeTypeReplace,
+				},
+			},
+			want: "<a>\n  <d/>\n</a>",
+		},
+		{
+			name: "replace: replaces dst with src",
+			args: args{
+				dst:    "<root>\n  <a/>\n</root>",
+				path:   "/root/a",
+				action: ActionReplace,
+				src:    "<b/><c/>",
+			},
+			want: "<root>\n  <b/>\n  <c/>\n</root>",
+		},
+		{
+			name: "delete: deletes dst and ignores src",
+			args: args{
+				dst:    "<root>\n  <a/>\n  <b/>\n</root>",
+				path:   "/root/a",
+				action: ActionDelete,
+				src:    "<c/>",
+			},
+			want: "<root>\n  <b/>\n</root>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := etree.NewDocument()
+			require.NoError(t, doc.ReadFromString(tt.args.dst))
+			dst := doc.FindElement(tt.args.path)
+			require.NotNil(t, dst)
+
+			frag := etree.NewDocument()
+			require.NoError(t, frag.ReadFromString("<fragment>"+tt.args.src+"</fragment>"))
+			src := []etree.Token{}
+			for _, token := range frag.Root().Child {
+				switch token.(type) {
+				case *etree.Element, *etree.Comment:
+					src = append(src, token)
+				}
+			}
+
+			XMLElement(dst, tt.args.action, src, tt.args.conf)
+
+			actual, err := doc.WriteToString()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, actual)
+		})
+	}
+}
@@ -1,7 +1,11 @@
 package pkg
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io/fs"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -65,6 +69,47 @@ func (p *Package) SetOrigin(value string) {
 	p.Metadata["Origin"] = value
 }
 
+// IsRemote returns true if the package was installed from
+// somewhere other than the local filesystem (i.e. a git repo or URL).
+func (p *Package) IsRemote() bool {
+	origin := p.Origin()
+	return origin != "" && !strings.HasPrefix(origin, "file://")
+}
+
+// Version returns the optional version of the package.
+func (p *Package) Version() string {
+	return p.MetadataString("version")
+}
+
+// Checksum returns a SHA-256 digest of every file in the package dir.
+// Identifies the exact content of a package regardless of version.
+func (p *Package) Checksum() (string, error) {
+	hash := sha256.New()
+	err := filepath.WalkDir(p.Path(), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(p.Path(), path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		// Include the path so that renames change the checksum.
+		hash.Write([]byte(filepath.ToSlash(rel)))
+		hash.Write([]byte{0})
+		hash.Write(content)
+		hash.Write([]byte{0})
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("checksum error: %w", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
 // Path returns the filesystem path of the package.
 func (p *Package) Path() string {
 	return p.path
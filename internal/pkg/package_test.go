@@ -111,6 +111,49 @@ func TestPackage_Origin(t *testing.T) {
 	assert.Equal(t, "~/packages/foo", p.Origin())
 }
 
+func TestPackage_IsRemote(t *testing.T) {
+	p := &Package{
+		Metadata: map[string]any{},
+	}
+	assert.False(t, p.IsRemote())
+	p.SetOrigin("file:///home/user/packages/foo")
+	assert.False(t, p.IsRemote())
+	p.SetOrigin("git::https://github.com/user/packages.git//foo")
+	assert.True(t, p.IsRemote())
+}
+
+func TestPackage_Version(t *testing.T) {
+	p := &Package{
+		Metadata: map[string]any{},
+	}
+	assert.Equal(t, "", p.Version())
+	p.Metadata["version"] = "1.2.3"
+	assert.Equal(t, "1.2.3", p.Version())
+}
+
+func TestPackage_Checksum(t *testing.T) {
+	aaa, err := LoadPackage(packageFixtureDir("nested/aaa"), DefaultMetaFile)
+	assert.NoError(t, err)
+	bbb, err := LoadPackage(packageFixtureDir("nested/bbb"), DefaultMetaFile)
+	assert.NoError(t, err)
+
+	sum1, err := aaa.Checksum()
+	assert.NoError(t, err)
+	assert.Len(t, sum1, 64)
+
+	sum2, err := aaa.Checksum()
+	assert.NoError(t, err)
+	assert.Equal(t, sum1, sum2, "should be deterministic")
+
+	sum3, err := bbb.Checksum()
+	assert.NoError(t, err)
+	assert.NotEqual(t, sum1, sum3)
+
+	missing := &Package{path: packageFixtureDir("missing")}
+	_, err = missing.Checksum()
+	assert.ErrorContains(t, err, "checksum error")
+}
+
 func TestPackage_MetadataLookup(t *testing.T) {
 	tests := []struct {
 		Desc     string
@@ -0,0 +1,75 @@
+package stamp
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cast"
+)
+
+const (
+	// AnswersStdin is the answers file path used to read answers from stdin
+	// (or write them to stdout).
+	AnswersStdin = "-"
+)
+
+// WriteAnswers writes answers to path in a format that can be read by [ReadAnswers].
+// The file is written as JSON or YAML (as determined by the file extension).
+// If path is [AnswersStdin], then the answers are written as YAML to stdout.
+func WriteAnswers(path string, answers map[string]any, stdout io.Writer) error {
+	fileType := FileTypeYaml
+	if ft, _ := ParseFileTypeFromPath(path); ft == FileTypeJson {
+		fileType = ft
+	}
+	content, err := fileType.Encoder().Encode(answers)
+	if err != nil {
+		return fmt.Errorf("answers encode: %w", err)
+	}
+
+	if path == AnswersStdin {
+		_, err = stdout.Write(content)
+	} else {
+		err = os.WriteFile(path, content, DstFileMode)
+	}
+	if err != nil {
+		return fmt.Errorf("answers write: %w", err)
+	}
+	return nil
+}
+
+// ReadAnswers returns the value data in the answers file at path.
+// The file may be JSON or YAML (as determined by the file extension),
+// and must contain a map of value keys to data.
+// If path is [AnswersStdin], then the answers are read from stdin.
+func ReadAnswers(path string, stdin io.Reader) (map[string]any, error) {
+	var (
+		content  []byte
+		fileType = FileTypeYaml // YAML is a superset of JSON, so a safe default.
+		err      error
+	)
+	if path == AnswersStdin {
+		content, err = io.ReadAll(stdin)
+	} else {
+		content, err = os.ReadFile(path)
+		if ft, _ := ParseFileTypeFromPath(path); ft == FileTypeJson {
+			fileType = ft
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("answers read: %w", err)
+	}
+
+	data, err := fileType.Encoder().Decode(content)
+	if err != nil {
+		return nil, fmt.Errorf("answers decode: %w", err)
+	}
+	if data == nil {
+		return map[string]any{}, nil
+	}
+	answers, err := cast.ToStringMapE(data)
+	if err != nil {
+		return nil, fmt.Errorf("answers decode: expected a map of value keys, got %T", data)
+	}
+	return answers, nil
+}
@@ -0,0 +1,132 @@
+package stamp
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/twelvelabs/termite/testutil"
+)
+
+func TestReadAnswers(t *testing.T) {
+	tests := []struct {
+		Desc     string
+		Path     string
+		Stdin    string
+		Files    map[string]any
+		Expected map[string]any
+		Err      string
+	}{
+		{
+			Desc: "reads YAML files",
+			Path: "answers.yaml",
+			Files: map[string]any{
+				"answers.yaml": "Name: foo\nCount: 2\nTags: [a, b]\n",
+			},
+			Expected: map[string]any{
+				"Name":  "foo",
+				"Count": 2,
+				"Tags":  []any{"a", "b"},
+			},
+		},
+		{
+			Desc: "reads JSON files",
+			Path: "answers.json",
+			Files: map[string]any{
+				"answers.json": `{"Name": "foo", "Enabled": true}`,
+			},
+			Expected: map[string]any{
+				"Name":    "foo",
+				"Enabled": true,
+			},
+		},
+		{
+			Desc:  "reads JSON from stdin",
+			Path:  "-",
+			Stdin: `{"Name": "foo"}`,
+			Expected: map[string]any{
+				"Name": "foo",
+			},
+		},
+		{
+			Desc:     "treats empty files as no answers",
+			Path:     "-",
+			Stdin:    "",
+			Expected: map[string]any{},
+		},
+		{
+			Desc: "returns an error when the file is missing",
+			Path: "missing.yaml",
+			Err:  "answers read:",
+		},
+		{
+			Desc: "returns an error when the file is invalid",
+			Path: "answers.json",
+			Files: map[string]any{
+				"answers.json": `{"Name": `,
+			},
+			Err: "answers decode:",
+		},
+		{
+			Desc:  "returns an error when the file does not contain a map",
+			Path:  "-",
+			Stdin: "- foo\n- bar\n",
+			Err:   "expected a map of value keys",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Desc, func(t *testing.T) {
+			testutil.InTempDir(t, func(tmpDir string) {
+				testutil.WritePaths(t, tmpDir, tt.Files)
+
+				path := tt.Path
+				if path != AnswersStdin {
+					path = filepath.Join(tmpDir, path)
+				}
+				actual, err := ReadAnswers(path, strings.NewReader(tt.Stdin))
+
+				if tt.Err == "" {
+					assert.NoError(t, err)
+				} else {
+					assert.ErrorContains(t, err, tt.Err)
+				}
+				assert.Equal(t, tt.Expected, actual)
+			})
+		})
+	}
+}
+
+func TestWriteAnswers(t *testing.T) {
+	answers := map[string]any{
+		"Name":    "foo",
+		"Enabled": true,
+		"Tags":    []string{"a", "b"},
+	}
+	replayed := map[string]any{
+		"Name":    "foo",
+		"Enabled": true,
+		"Tags":    []any{"a", "b"},
+	}
+
+	testutil.InTempDir(t, func(tmpDir string) {
+		for _, name := range []string{"answers.yaml", "answers.json"} {
+			path := filepath.Join(tmpDir, name)
+			assert.NoError(t, WriteAnswers(path, answers, nil))
+
+			actual, err := ReadAnswers(path, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, replayed, actual, "%s should round trip", name)
+		}
+		testutil.AssertPaths(t, tmpDir, map[string]any{
+			"answers.json": "{\n    \"Enabled\": true,\n    \"Name\": \"foo\",\n    \"Tags\": [\n        \"a\",\n        \"b\"\n    ]\n}",
+		})
+	})
+
+	stdout := &strings.Builder{}
+	assert.NoError(t, WriteAnswers(AnswersStdin, answers, stdout))
+	assert.Equal(t, "Enabled: true\nName: foo\nTags:\n  - a\n  - b\n", stdout.String())
+
+	err := WriteAnswers(filepath.Join("missing", "dir", "answers.yaml"), answers, nil)
+	assert.ErrorContains(t, err, "answers write:")
+}
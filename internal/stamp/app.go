@@ -11,6 +11,7 @@ import (
 	"github.com/twelvelabs/termite/ui"
 
 	"github.com/twelvelabs/stamp/internal/fsutil"
+	"github.com/twelvelabs/stamp/internal/vfs"
 )
 
 type ctxKey string
@@ -25,6 +26,7 @@ type App struct {
 	UI     *ui.UserInterface
 	Store  *Store
 	Meta   *AppMeta
+	FS     vfs.FS
 
 	ctx context.Context //nolint: containedctx
 }
@@ -70,6 +72,7 @@ func NewApp(meta *AppMeta) (*App, error) {
 		UI:     ui.NewUserInterface(ios),
 		Store:  store,
 		Meta:   meta,
+		FS:     vfs.NewOS(),
 	}
 
 	return app, nil
@@ -89,6 +92,7 @@ func NewTestApp() *App {
 		UI:     ui.NewUserInterface(ios).WithStubbing(),
 		Store:  store,
 		Meta:   meta,
+		FS:     vfs.NewOS(),
 	}
 
 	return app
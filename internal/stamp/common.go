@@ -9,6 +9,8 @@ import (
 )
 
 var (
+	ErrAborted      = errors.New("aborted by user")
+	ErrNoInput      = errors.New("input required")
 	ErrPathNotFound = errors.New("path not found")
 )
 
@@ -11,10 +11,14 @@ import (
 )
 
 type Config struct {
-	Debug     bool           `yaml:"debug"       env:"STAMP_DEBUG"`
-	Defaults  map[string]any `yaml:"defaults"    default:"{}"`
-	DryRun    bool           `yaml:"dry_run"     env:"STAMP_DRY_RUN"`
-	StorePath string         `yaml:"store_path"  env:"STAMP_STORE_PATH"  default:"~/.stamp/packages"`
+	Debug      bool           `yaml:"debug"       env:"STAMP_DEBUG"`
+	Defaults   map[string]any `yaml:"defaults"    default:"{}"`
+	DryRun     bool           `yaml:"dry_run"     env:"STAMP_DRY_RUN"`
+	Jobs       int            `yaml:"jobs"        env:"STAMP_JOBS"        default:"1"`
+	NoInput    bool           `yaml:"no_input"    env:"STAMP_NO_INPUT"`
+	NoRollback bool           `yaml:"no_rollback" env:"STAMP_NO_ROLLBACK"`
+	Output     OutputFormat   `yaml:"output"      env:"STAMP_OUTPUT"      default:"text"`
+	StorePath  string         `yaml:"store_path"  env:"STAMP_STORE_PATH"  default:"~/.stamp/packages"`
 }
 
 // NewDefaultConfig returns a new, default config.
@@ -1,6 +1,7 @@
 package stamp
 
 import (
+	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -8,7 +9,9 @@ import (
 
 	"github.com/swaggest/jsonschema-go"
 
+	"github.com/twelvelabs/stamp/internal/diffutil"
 	"github.com/twelvelabs/stamp/internal/mdutil"
+	"github.com/twelvelabs/stamp/internal/vfs"
 )
 
 const (
@@ -16,6 +19,28 @@ const (
 	DstFileMode os.FileMode = 0666
 )
 
+// Choices presented to the user when resolving a conflict.
+const (
+	ConflictChoiceOverwrite    = "Overwrite"
+	ConflictChoiceSkip         = "Skip"
+	ConflictChoiceDiff         = "Show diff"
+	ConflictChoiceOverwriteAll = "Overwrite all remaining"
+	ConflictChoiceSkipAll      = "Skip all remaining"
+	ConflictChoiceAbort        = "Abort"
+)
+
+// ConflictChoices returns the choices for resolving a conflict (in display order).
+func ConflictChoices() []string {
+	return []string{
+		ConflictChoiceOverwrite,
+		ConflictChoiceSkip,
+		ConflictChoiceDiff,
+		ConflictChoiceOverwriteAll,
+		ConflictChoiceSkipAll,
+		ConflictChoiceAbort,
+	}
+}
+
 type CreateTask struct {
 	Common `mapstructure:",squash"`
 
@@ -113,12 +138,19 @@ func (t *CreateTask) TypeKey() string {
 	return t.Type
 }
 
+func (t *CreateTask) dstPaths(values map[string]any) []string {
+	if path := t.Dst.lockPath(values); path != "" {
+		return []string{path}
+	}
+	return nil
+}
+
 func (t *CreateTask) Execute(ctx *TaskContext, values map[string]any) error {
-	if err := t.Dst.SetValues(values); err != nil {
+	if err := t.Dst.SetValues(ctx.FS, values); err != nil {
 		ctx.Logger.Failure("fail", t.Dst.RelativePath())
 		return err
 	}
-	if err := t.Src.SetValues(values); err != nil {
+	if err := t.Src.SetValues(ctx.FS, values); err != nil {
 		ctx.Logger.Failure("fail", t.Dst.RelativePath())
 		return err
 	}
@@ -127,7 +159,8 @@ func (t *CreateTask) Execute(ctx *TaskContext, values map[string]any) error {
 		// src is a dir; walk and call dispatch on each file
 		srcRoot := strings.TrimSuffix(t.Src.Path(), "/")
 		dstRoot := strings.TrimSuffix(t.Dst.Path(), "/")
-		return filepath.Walk(srcRoot, func(srcPath string, srcPathInfo fs.FileInfo, err error) error {
+		group := newTaskGroup(ctx)
+		err := vfs.WalkDir(ctx.FS, srcRoot, func(srcPath string, srcPathEntry fs.DirEntry, err error) error {
 			if err != nil {
 				return err
 			}
@@ -135,21 +168,28 @@ func (t *CreateTask) Execute(ctx *TaskContext, values map[string]any) error {
 			dstPath := filepath.Join(dstRoot, strings.TrimPrefix(srcPath, srcRoot))
 
 			// If the src path is a dir, create the dst dir and move on.
-			if srcPathInfo.IsDir() {
+			if srcPathEntry.IsDir() {
 				return t.createDstDir(ctx, dstPath)
 			}
 
-			// Otherwise create new Source and Destination structs and dispatch.
-			src, err := t.Src.ForPath(srcPath, values)
-			if err != nil {
-				return err
-			}
-			dst, err := t.Dst.ForPath(dstPath, values)
-			if err != nil {
-				return err
-			}
-			return t.dispatch(ctx, src, dst)
+			// Otherwise create new Source and Destination structs and dispatch
+			// (possibly concurrently with the other files in the dir).
+			return group.Go([]string{dstPath}, func(ctx *TaskContext) error {
+				src, err := t.Src.ForPath(srcPath, values)
+				if err != nil {
+					return err
+				}
+				dst, err := t.Dst.ForPath(dstPath, values)
+				if err != nil {
+					return err
+				}
+				return t.dispatch(ctx, src, dst)
+			})
 		})
+		if waitErr := group.Wait(); err == nil {
+			err = waitErr
+		}
+		return err
 	}
 
 	// src is a single file (or inline content)
@@ -166,18 +206,39 @@ func (t *CreateTask) dispatch(ctx *TaskContext, src Source, dst Destination) err
 		return t.keep(ctx, src, dst)
 	case ConflictConfigReplace:
 		return t.replace(ctx, src, dst)
+	case ConflictConfigMerge:
+		return t.merge(ctx, src, dst)
 	default: // ConflictConfigPrompt
-		return t.prompt(ctx, src, dst)
+		// The user may have already chosen to overwrite or skip all remaining conflicts
+		// (so when running concurrently, wait for any earlier prompts to be answered).
+		ctx.waitTurn()
+		switch ctx.ConflictOverride {
+		case ConflictConfigKeep:
+			return t.keep(ctx, src, dst)
+		case ConflictConfigReplace:
+			return t.replace(ctx, src, dst)
+		default:
+			return t.prompt(ctx, src, dst)
+		}
 	}
 }
 
 // create is called to create a non-existing dst file.
 func (t *CreateTask) create(ctx *TaskContext, src Source, dst Destination) error {
-	if err := t.createDst(ctx, src, dst); err != nil {
+	diff, err := t.createDst(ctx, src, dst)
+	if err != nil {
 		ctx.Logger.Failure("fail", dst.RelativePath())
 		return err
 	}
+	if dst.Conflict == ConflictConfigMerge {
+		// Store the generated version for use as a merge base in future runs.
+		if err := t.recordGenerated(ctx, src, dst); err != nil {
+			ctx.Logger.Failure("fail", dst.RelativePath())
+			return err
+		}
+	}
 	ctx.Logger.Success("create", dst.RelativePath())
+	ctx.Logger.Diff(diff)
 	return nil
 }
 
@@ -193,45 +254,153 @@ func (t *CreateTask) replace(ctx *TaskContext, src Source, dst Destination) erro
 		ctx.Logger.Failure("fail", dst.RelativePath())
 		return err
 	}
-	if err := t.createDst(ctx, src, dst); err != nil {
+	diff, err := t.createDst(ctx, src, dst)
+	if err != nil {
 		ctx.Logger.Failure("fail", dst.RelativePath())
 		return err
 	}
 	ctx.Logger.Success("replace", dst.RelativePath())
+	ctx.Logger.Diff(diff)
+	return nil
+}
+
+// merge is called to merge the src content into an existing dst file.
+func (t *CreateTask) merge(ctx *TaskContext, src Source, dst Destination) error {
+	diff, conflicted, err := t.mergeDst(ctx, src, dst)
+	if err != nil {
+		ctx.Logger.Failure("fail", dst.RelativePath())
+		return err
+	}
+	if conflicted {
+		ctx.Logger.Warning("conflict", "%s has merge conflicts", dst.RelativePath())
+	} else {
+		ctx.Logger.Success("merge", dst.RelativePath())
+	}
+	ctx.Logger.Diff(diff)
 	return nil
 }
 
 // prompt is called to prompt the user for how to resolve a dst file conflict.
 // delegates to keep or replace depending on their response.
+// The "all remaining" choices are stored in the context so that
+// subsequent conflicts (including those in sub-generators) are not prompted.
 func (t *CreateTask) prompt(ctx *TaskContext, src Source, dst Destination) error {
 	ctx.Logger.Warning("conflict", "%s already exists", dst.RelativePath())
-	overwrite, err := ctx.UI.Confirm("Overwrite", false)
-	if err != nil {
-		return err
+	if ctx.NoInput {
+		ctx.Logger.Failure("fail", dst.RelativePath())
+		return fmt.Errorf("%w: unable to resolve conflict for %s", ErrNoInput, dst.RelativePath())
 	}
-	if overwrite {
-		return t.replace(ctx, src, dst)
+	for {
+		choice, err := ctx.UI.Select("Resolve conflict", ConflictChoices(), ConflictChoiceSkip)
+		if err != nil {
+			return err
+		}
+		switch choice {
+		case ConflictChoiceOverwrite:
+			return t.replace(ctx, src, dst)
+		case ConflictChoiceDiff:
+			diff, err := dst.Diff(src.Content())
+			if err != nil {
+				return err
+			}
+			ctx.Logger.Diff(diff)
+			continue // re-prompt
+		case ConflictChoiceOverwriteAll:
+			ctx.ConflictOverride = ConflictConfigReplace
+			return t.replace(ctx, src, dst)
+		case ConflictChoiceSkipAll:
+			ctx.ConflictOverride = ConflictConfigKeep
+			return t.keep(ctx, src, dst)
+		case ConflictChoiceAbort:
+			ctx.Logger.Failure("abort", dst.RelativePath())
+			return ErrAborted
+		default: // ConflictChoiceSkip
+			return t.keep(ctx, src, dst)
+		}
 	}
-	return t.keep(ctx, src, dst)
 }
 
 func (t *CreateTask) createDstDir(ctx *TaskContext, path string) error {
 	if ctx.DryRun {
 		return nil
 	}
-	return os.MkdirAll(path, DstDirMode)
+	if err := ctx.Journal.Record(path); err != nil {
+		return err
+	}
+	return ctx.FS.MkdirAll(path, DstDirMode)
 }
 
-func (t *CreateTask) createDst(ctx *TaskContext, src Source, dst Destination) error {
+// createDst writes the src content to dst.
+// During a dry run, dst is left untouched and a diff
+// of the pending changes is returned instead.
+func (t *CreateTask) createDst(ctx *TaskContext, src Source, dst Destination) (string, error) {
+	if ctx.DryRun {
+		return dst.Diff(src.Content())
+	}
+	if err := ctx.Journal.Record(dst.Path()); err != nil {
+		return "", err
+	}
+	return "", dst.Write(src.Content())
+}
+
+// mergeDst performs a three-way merge between the last generated version of dst,
+// the current dst content (which may have been edited by the user),
+// and the newly rendered src content. The result is written to dst
+// and returned bool is true if the merge had conflicts.
+// During a dry run, dst is left untouched and a diff
+// of the pending changes is returned instead.
+func (t *CreateTask) mergeDst(ctx *TaskContext, src Source, dst Destination) (string, bool, error) {
+	generated, err := dst.Encode(src.Content())
+	if err != nil {
+		return "", false, err
+	}
+	current, err := ctx.FS.ReadFile(dst.Path())
+	if err != nil {
+		return "", false, fmt.Errorf("dst path read: %w", err)
+	}
+	base, err := NewState(ctx.FS, dst.RootPath()).ReadGenerated(dst.RootRelativePath())
+	if err != nil {
+		return "", false, err
+	}
+
+	merged, conflicted := diffutil.Merge3(base, current, generated, "local", "generated")
+
+	if ctx.DryRun {
+		diff, err := dst.diff(merged, false)
+		return diff, conflicted, err
+	}
+	if err := ctx.Journal.Record(dst.Path()); err != nil {
+		return "", false, err
+	}
+	if err := dst.WriteBytes(merged); err != nil {
+		return "", false, err
+	}
+	return "", conflicted, t.recordGenerated(ctx, src, dst)
+}
+
+// recordGenerated stores the rendered src content in the destination state dir
+// so that it can be used as the merge base the next time the generator is run.
+func (t *CreateTask) recordGenerated(ctx *TaskContext, src Source, dst Destination) error {
 	if ctx.DryRun {
 		return nil
 	}
-	return dst.Write(src.Content())
+	generated, err := dst.Encode(src.Content())
+	if err != nil {
+		return err
+	}
+	state := NewState(ctx.FS, dst.RootPath())
+	if err := ctx.Journal.Record(state.GeneratedPath(dst.RootRelativePath())); err != nil {
+		return err
+	}
+	return state.WriteGenerated(dst.RootRelativePath(), generated)
 }
 
 func (t *CreateTask) deleteDst(ctx *TaskContext, _ Source, dst Destination) error {
 	if ctx.DryRun {
 		return nil
 	}
+	if err := ctx.Journal.RecordTree(dst.Path()); err != nil {
+		return err
+	}
 	return dst.Delete()
 }
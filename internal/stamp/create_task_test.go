@@ -155,6 +155,46 @@ func TestCreateTask_Execute(t *testing.T) { //nolint:maintidx
 			},
 			Err: "",
 		},
+		{
+			Desc: "generates dotenv files as-is unless the content type is set",
+			TaskData: map[string]any{
+				"type": "create",
+				"src": map[string]any{
+					"content": "# Database\nDB_USER=app\nDB_HOST=localhost\n",
+				},
+				"dst": map[string]any{
+					"path": ".env.example",
+				},
+			},
+			Values: map[string]any{
+				"SrcPath": templatesDir,
+				"DstPath": ".",
+			},
+			EndFiles: map[string]any{
+				".env.example": "# Database\nDB_USER=app\nDB_HOST=localhost\n",
+			},
+			Err: "",
+		},
+		{
+			Desc: "generates cfg files as-is unless the content type is set",
+			TaskData: map[string]any{
+				"type": "create",
+				"src": map[string]any{
+					"content": "global\n    daemon\n",
+				},
+				"dst": map[string]any{
+					"path": "haproxy.cfg",
+				},
+			},
+			Values: map[string]any{
+				"SrcPath": templatesDir,
+				"DstPath": ".",
+			},
+			EndFiles: map[string]any{
+				"haproxy.cfg": "global\n    daemon\n",
+			},
+			Err: "",
+		},
 		{
 			Desc:   "does not create a file during a dry run",
 			DryRun: true,
@@ -281,7 +321,32 @@ func TestCreateTask_Execute(t *testing.T) { //nolint:maintidx
 		},
 
 		{
-			Desc: "[conflict:prompt] will prompt to overwrite and replace the file if user confirms",
+			Desc: "[conflict:replace] will replace structured files with the encoded src",
+			StartFiles: map[string]any{
+				"config.json": "{\n  // Existing comment\n  \"b\": 1,\n  \"a\": 2\n}\n",
+			},
+			TaskData: map[string]any{
+				"type": "create",
+				"src": map[string]any{
+					"content": map[string]any{"a": 1, "b": 2},
+				},
+				"dst": map[string]any{
+					"path":     "config.json",
+					"conflict": "replace",
+				},
+			},
+			Values: map[string]any{
+				"SrcPath": templatesDir,
+				"DstPath": ".",
+			},
+			EndFiles: map[string]any{
+				"config.json": "{\n    \"a\": 1,\n    \"b\": 2\n}",
+			},
+			Err: "",
+		},
+
+		{
+			Desc: "[conflict:prompt] will prompt and replace the file if user chooses overwrite",
 			StartFiles: map[string]any{
 				"README.md": "Pre-existing content",
 			},
@@ -302,8 +367,8 @@ func TestCreateTask_Execute(t *testing.T) { //nolint:maintidx
 			},
 			Setup: func(app *App) {
 				app.UI.RegisterStub(
-					ui.MatchConfirm("Overwrite"),
-					ui.RespondBool(true),
+					ui.MatchSelect("Resolve conflict"),
+					ui.RespondString(ConflictChoiceOverwrite),
 				)
 			},
 			EndFiles: map[string]any{
@@ -312,7 +377,7 @@ func TestCreateTask_Execute(t *testing.T) { //nolint:maintidx
 			Err: "",
 		},
 		{
-			Desc: "[conflict:prompt] will prompt to overwrite and keep the file if user responds no",
+			Desc: "[conflict:prompt] will prompt and keep the file if user chooses skip",
 			StartFiles: map[string]any{
 				"README.md": "Pre-existing content",
 			},
@@ -333,8 +398,8 @@ func TestCreateTask_Execute(t *testing.T) { //nolint:maintidx
 			},
 			Setup: func(app *App) {
 				app.UI.RegisterStub(
-					ui.MatchConfirm("Overwrite"),
-					ui.RespondBool(false),
+					ui.MatchSelect("Resolve conflict"),
+					ui.RespondString(ConflictChoiceSkip),
 				)
 			},
 			EndFiles: map[string]any{
@@ -364,7 +429,7 @@ func TestCreateTask_Execute(t *testing.T) { //nolint:maintidx
 			},
 			Setup: func(app *App) {
 				app.UI.RegisterStub(
-					ui.MatchConfirm("Overwrite"),
+					ui.MatchSelect("Resolve conflict"),
 					ui.RespondError(errors.New("boom")),
 				)
 			},
@@ -373,6 +438,100 @@ func TestCreateTask_Execute(t *testing.T) { //nolint:maintidx
 			},
 			Err: "boom",
 		},
+		{
+			Desc: "[conflict:prompt] will show a diff and re-prompt if user chooses show diff",
+			StartFiles: map[string]any{
+				"README.md": "Pre-existing content",
+			},
+			TaskData: map[string]any{
+				"type": "create",
+				"src": map[string]any{
+					"path": "README.md",
+				},
+				"dst": map[string]any{
+					"path":     "README.md",
+					"conflict": "prompt",
+				},
+			},
+			Values: map[string]any{
+				"ProjectName": "My Project",
+				"SrcPath":     templatesDir,
+				"DstPath":     ".",
+			},
+			Setup: func(app *App) {
+				app.UI.RegisterStub(
+					ui.MatchSelect("Resolve conflict"),
+					ui.RespondString(ConflictChoiceDiff),
+				)
+				app.UI.RegisterStub(
+					ui.MatchSelect("Resolve conflict"),
+					ui.RespondString(ConflictChoiceOverwrite),
+				)
+			},
+			EndFiles: map[string]any{
+				"README.md": "# My Project\n",
+			},
+			Err: "",
+		},
+		{
+			Desc: "[conflict:prompt] will return an error if user chooses abort",
+			StartFiles: map[string]any{
+				"README.md": "Pre-existing content",
+			},
+			TaskData: map[string]any{
+				"type": "create",
+				"src": map[string]any{
+					"path": "README.md",
+				},
+				"dst": map[string]any{
+					"path":     "README.md",
+					"conflict": "prompt",
+				},
+			},
+			Values: map[string]any{
+				"ProjectName": "My Project",
+				"SrcPath":     templatesDir,
+				"DstPath":     ".",
+			},
+			Setup: func(app *App) {
+				app.UI.RegisterStub(
+					ui.MatchSelect("Resolve conflict"),
+					ui.RespondString(ConflictChoiceAbort),
+				)
+			},
+			EndFiles: map[string]any{
+				"README.md": "Pre-existing content",
+			},
+			Err: "aborted by user",
+		},
+		{
+			Desc: "[conflict:prompt] will return an error instead of prompting when input is disabled",
+			StartFiles: map[string]any{
+				"README.md": "Pre-existing content",
+			},
+			TaskData: map[string]any{
+				"type": "create",
+				"src": map[string]any{
+					"path": "README.md",
+				},
+				"dst": map[string]any{
+					"path":     "README.md",
+					"conflict": "prompt",
+				},
+			},
+			Values: map[string]any{
+				"ProjectName": "My Project",
+				"SrcPath":     templatesDir,
+				"DstPath":     ".",
+			},
+			Setup: func(app *App) {
+				app.Config.NoInput = true
+			},
+			EndFiles: map[string]any{
+				"README.md": "Pre-existing content",
+			},
+			Err: "input required: unable to resolve conflict for README.md",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.Desc, func(t *testing.T) {
@@ -406,3 +565,178 @@ func TestCreateTask_Execute(t *testing.T) { //nolint:maintidx
 		})
 	}
 }
+
+func TestCreateTask_Execute_DryRunLogsDiff(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"README.md": "Pre-existing content\n",
+		})
+
+		app := NewTestApp()
+		app.Config.DryRun = true
+
+		task, err := NewTask(map[string]any{
+			"type": "create",
+			"src": map[string]any{
+				"content": "New content\n",
+			},
+			"dst": map[string]any{
+				"path":     "{{ .Name }}",
+				"conflict": "replace",
+			},
+			"each": "README.md, LICENSE",
+		})
+		require.NoError(t, err)
+
+		ctx := NewTaskContext(app)
+		for _, name := range task.Iterator(map[string]any{}) {
+			err = task.Execute(ctx, map[string]any{"DstPath": ".", "Name": name})
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, ""+
+			"✓ [DRY RUN][   replace]: README.md\n"+
+			"--- a/README.md\n"+
+			"+++ b/README.md\n"+
+			"@@ -1 +1 @@\n"+
+			"-Pre-existing content\n"+
+			"+New content\n"+
+			"✓ [DRY RUN][    create]: LICENSE\n"+
+			"--- /dev/null\n"+
+			"+++ b/LICENSE\n"+
+			"@@ -0,0 +1 @@\n"+
+			"+New content\n",
+			app.IO.Out.String(),
+		)
+		testutil.AssertPaths(t, tmpDir, map[string]any{
+			"README.md": "Pre-existing content\n",
+			"LICENSE":   false,
+		})
+	})
+}
+
+func TestCreateTask_Execute_RemembersConflictChoices(t *testing.T) {
+	tests := []struct {
+		Desc     string
+		Choice   string
+		EndFiles map[string]any
+	}{
+		{
+			Desc:   "overwrite all replaces subsequent conflicts without prompting",
+			Choice: ConflictChoiceOverwriteAll,
+			EndFiles: map[string]any{
+				"aaa.txt": "New",
+				"bbb.txt": "New",
+				"ccc.txt": "Keep me",
+			},
+		},
+		{
+			Desc:   "skip all keeps subsequent conflicts without prompting",
+			Choice: ConflictChoiceSkipAll,
+			EndFiles: map[string]any{
+				"aaa.txt": "Old",
+				"bbb.txt": "Old",
+				"ccc.txt": "Keep me",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Desc, func(t *testing.T) {
+			testutil.InTempDir(t, func(tmpDir string) {
+				testutil.WritePaths(t, tmpDir, map[string]any{
+					"aaa.txt": "Old",
+					"bbb.txt": "Old",
+					"ccc.txt": "Keep me",
+				})
+
+				app := NewTestApp()
+				app.UI.RegisterStub(
+					ui.MatchSelect("Resolve conflict"),
+					ui.RespondString(tt.Choice),
+				)
+				defer app.UI.VerifyStubs(t)
+
+				ts := NewTaskSet()
+				ts.DstPath = tmpDir
+				for _, data := range []map[string]any{
+					{
+						"type": "create",
+						"src":  map[string]any{"content": "New"},
+						"dst":  map[string]any{"path": "{{ ._Item }}"},
+						"each": "aaa.txt, bbb.txt",
+					},
+					{
+						"type": "create",
+						"src":  map[string]any{"content": "New"},
+						"dst":  map[string]any{"path": "ccc.txt", "conflict": "keep"},
+					},
+				} {
+					task, err := NewTask(data)
+					require.NoError(t, err)
+					ts.Add(task)
+				}
+
+				ctx := NewTaskContext(app)
+				err := ts.Execute(ctx, map[string]any{})
+				assert.NoError(t, err)
+
+				testutil.AssertPaths(t, tmpDir, tt.EndFiles)
+			})
+		})
+	}
+}
+
+func TestCreateTask_Execute_Merge(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		run := func(content string) {
+			t.Helper()
+			task, err := NewTask(map[string]any{
+				"type": "create",
+				"src": map[string]any{
+					"content": content,
+				},
+				"dst": map[string]any{
+					"path":     "docs/README.md",
+					"conflict": "merge",
+				},
+			})
+			require.NoError(t, err)
+			ctx := NewTaskContext(NewTestApp())
+			err = task.Execute(ctx, map[string]any{"DstPath": tmpDir})
+			require.NoError(t, err)
+		}
+
+		// Initial run should create the file and record the generated version.
+		run("# Title\n\nIntro\n\nFooter\n")
+		testutil.AssertPaths(t, tmpDir, map[string]any{
+			"docs/README.md":                  "# Title\n\nIntro\n\nFooter\n",
+			".stamp/generated/docs/README.md": "# Title\n\nIntro\n\nFooter\n",
+		})
+
+		// User edits the file, generator changes a different section.
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"docs/README.md": "# My Title\n\nIntro\n\nFooter\n",
+		})
+		run("# Title\n\nIntro\n\nNew Footer\n")
+		testutil.AssertPaths(t, tmpDir, map[string]any{
+			"docs/README.md":                  "# My Title\n\nIntro\n\nNew Footer\n",
+			".stamp/generated/docs/README.md": "# Title\n\nIntro\n\nNew Footer\n",
+		})
+
+		// User and generator change the same section.
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"docs/README.md": "# My Title\n\nMy Intro\n\nNew Footer\n",
+		})
+		run("# Title\n\nGenerated Intro\n\nNew Footer\n")
+		testutil.AssertPaths(t, tmpDir, map[string]any{
+			"docs/README.md": "# My Title\n\n" +
+				"<<<<<<< local\n" +
+				"My Intro\n" +
+				"=======\n" +
+				"Generated Intro\n" +
+				">>>>>>> generated\n" +
+				"\nNew Footer\n",
+			".stamp/generated/docs/README.md": "# Title\n\nGenerated Intro\n\nNew Footer\n",
+		})
+	})
+}
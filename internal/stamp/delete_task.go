@@ -22,18 +22,27 @@ func (t *DeleteTask) TypeKey() string {
 	return t.Type
 }
 
+func (t *DeleteTask) dstPaths(values map[string]any) []string {
+	if path := t.Dst.lockPath(values); path != "" {
+		return []string{path}
+	}
+	return nil
+}
+
 func (t *DeleteTask) Execute(ctx *TaskContext, values map[string]any) error {
-	err := t.Dst.SetValues(values)
+	err := t.Dst.SetValues(ctx.FS, values)
 	if err != nil {
 		return err
 	}
 
 	if t.Dst.Exists() {
-		if err := t.deleteDst(ctx); err != nil {
+		diff, err := t.deleteDst(ctx)
+		if err != nil {
 			ctx.Logger.Failure("fail", t.Dst.RelativePath())
 			return err
 		}
 		ctx.Logger.Success("delete", t.Dst.RelativePath())
+		ctx.Logger.Diff(diff)
 		return nil
 	} else if t.Dst.Missing == MissingConfigError {
 		ctx.Logger.Failure("fail", t.Dst.RelativePath())
@@ -43,9 +52,18 @@ func (t *DeleteTask) Execute(ctx *TaskContext, values map[string]any) error {
 	return nil
 }
 
-func (t *DeleteTask) deleteDst(ctx *TaskContext) error {
+// deleteDst removes the destination path.
+// During a dry run, the path is left untouched and a diff
+// of the pending changes is returned instead (files only).
+func (t *DeleteTask) deleteDst(ctx *TaskContext) (string, error) {
 	if ctx.DryRun {
-		return nil
+		if t.Dst.IsDir() {
+			return "", nil
+		}
+		return t.Dst.DeleteDiff()
+	}
+	if err := ctx.Journal.RecordTree(t.Dst.Path()); err != nil {
+		return "", err
 	}
-	return t.Dst.Delete()
+	return "", t.Dst.Delete()
 }
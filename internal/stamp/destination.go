@@ -1,6 +1,7 @@
 package stamp
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,7 +11,10 @@ import (
 	"github.com/swaggest/jsonschema-go"
 	"github.com/twelvelabs/termite/render"
 
+	"github.com/twelvelabs/stamp/internal/diffutil"
+	"github.com/twelvelabs/stamp/internal/encode"
 	"github.com/twelvelabs/stamp/internal/fsutil"
+	"github.com/twelvelabs/stamp/internal/vfs"
 )
 
 type Destination struct {
@@ -22,8 +26,11 @@ type Destination struct {
 
 	content     any
 	contentType FileType
+	encoded     []byte
+	fs          vfs.FS
 	mode        os.FileMode
 	path        string
+	root        string
 }
 
 // PrepareJSONSchema implements the jsonschema.Preparer interface.
@@ -94,30 +101,54 @@ func (d *Destination) RelativePath() string {
 	return fsutil.TryRelative(d.path)
 }
 
+// RootPath returns the absolute path to the destination root dir (i.e. DstPath).
+func (d *Destination) RootPath() string {
+	return d.root
+}
+
+// RootRelativePath returns the file path relative to the destination root dir.
+func (d *Destination) RootRelativePath() string {
+	rel, err := filepath.Rel(d.root, d.path)
+	if err != nil {
+		return d.path
+	}
+	return rel
+}
+
 // Exists returns true if the file path exists.
 func (d *Destination) Exists() bool {
-	return fsutil.PathExists(d.path)
+	return vfs.Exists(d.fs, d.path)
 }
 
 // IsDir returns true if the file path is a directory.
 func (d *Destination) IsDir() bool {
-	return fsutil.PathIsDir(d.path)
+	return vfs.IsDir(d.fs, d.path)
 }
 
-// SetValues calculates destination properties using the given values.
-func (d *Destination) SetValues(values map[string]any) error {
-	var err error
-
-	// Render and validate path.
-	d.path, err = d.PathTpl.RenderRequired(values)
-	if err != nil {
-		return fmt.Errorf("dst path render: %w", err)
+// lockPath returns the absolute (but unresolved) destination path
+// for the given values, or an empty string if it can not be rendered.
+// Used to order concurrent tasks that modify the same path.
+func (d *Destination) lockPath(values map[string]any) string {
+	path, err := d.PathTpl.Render(values)
+	if err != nil || path == "" {
+		return ""
+	}
+	path = filepath.FromSlash(path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cast.ToString(values["DstPath"]), path)
 	}
-	d.path, err = fsutil.EnsurePathRelativeToRoot(
-		d.path, cast.ToString(values["DstPath"]),
-	)
+	path, err = filepath.Abs(path)
 	if err != nil {
-		return fmt.Errorf("dst path validate: %w", err)
+		return ""
+	}
+	return path
+}
+
+// SetValues calculates destination properties using the given values.
+// The file is read from (and later written to) fsys.
+func (d *Destination) SetValues(fsys vfs.FS, values map[string]any) error {
+	if err := d.SetPathValues(fsys, values); err != nil {
+		return err
 	}
 
 	// Render and parse content type.
@@ -147,7 +178,7 @@ func (d *Destination) SetValues(values map[string]any) error {
 
 	// Decode content.
 	if d.Exists() && !d.IsDir() {
-		content, err := os.ReadFile(d.path)
+		content, err := d.fs.ReadFile(d.path)
 		if err != nil {
 			return fmt.Errorf("dst path read: %w", err)
 		}
@@ -156,53 +187,144 @@ func (d *Destination) SetValues(values map[string]any) error {
 		if err != nil {
 			return fmt.Errorf("dst path decode: %w", err)
 		}
+		d.encoded = content
 	}
 
 	return nil
 }
 
+// SetPathValues renders and validates the destination path only.
+// The content type and mode are ignored, and the file is not read
+// (i.e. for tasks that treat the path as opaque, like move).
+func (d *Destination) SetPathValues(fsys vfs.FS, values map[string]any) error {
+	var err error
+	d.fs = fsys
+
+	d.path, err = d.PathTpl.RenderRequired(values)
+	if err != nil {
+		return fmt.Errorf("dst path render: %w", err)
+	}
+	d.root, err = fsutil.ResolvePath(cast.ToString(values["DstPath"]))
+	if err != nil {
+		return fmt.Errorf("dst root resolve: %w", err)
+	}
+	d.path, err = fsutil.EnsurePathRelativeToRoot(d.path, d.root)
+	if err != nil {
+		return fmt.Errorf("dst path validate: %w", err)
+	}
+	return nil
+}
+
+// Encode encodes data using the destination content type.
+func (d *Destination) Encode(data any) ([]byte, error) {
+	buf, err := d.contentType.Encoder().Encode(data)
+	if err != nil {
+		return nil, fmt.Errorf("dst encode: %w", err)
+	}
+	return buf, nil
+}
+
+// PatchContent encodes data as an update to the existing file content.
+// When the content type supports it, the formatting of the existing file
+// (comments, key order, etc) is preserved.
+func (d *Destination) PatchContent(data any) ([]byte, error) {
+	encoder := d.contentType.Encoder()
+	patcher, ok := encoder.(encode.Patcher)
+	if !ok || len(bytes.TrimSpace(d.encoded)) == 0 {
+		return d.Encode(data)
+	}
+	buf, err := patcher.Patch(d.encoded, data)
+	if err != nil {
+		return nil, fmt.Errorf("dst encode: %w", err)
+	}
+	return buf, nil
+}
+
 // Write encodes data and writes the resulting bytes
 // to the destination file.
 func (d *Destination) Write(data any) error {
 	// Encode to byte array.
-	buf, err := d.contentType.Encoder().Encode(data)
+	buf, err := d.Encode(data)
 	if err != nil {
-		return fmt.Errorf("dst encode: %w", err)
+		return err
+	}
+
+	if err := d.WriteBytes(buf); err != nil {
+		return err
 	}
 
+	// Set new content.
+	d.content = data
+	d.encoded = buf
+
+	return nil
+}
+
+// WriteBytes writes buf to the destination file as-is (without encoding).
+func (d *Destination) WriteBytes(buf []byte) error {
 	// Ensure base dirs.
-	if err := os.MkdirAll(filepath.Dir(d.path), DstDirMode); err != nil {
+	if err := d.fs.MkdirAll(filepath.Dir(d.path), DstDirMode); err != nil {
 		return err
 	}
 
 	// Write file
-	f, err := os.Create(d.path)
-	if err != nil {
-		return fmt.Errorf("dst create: %w", err)
-	}
-	defer f.Close()
-	_, err = f.Write(buf)
-	if err != nil {
+	if err := d.fs.WriteFile(d.path, buf, DstFileMode); err != nil {
 		return fmt.Errorf("dst write: %w", err)
 	}
 
 	// Set permissions (if configured).
 	if d.mode != 0 {
-		err = os.Chmod(d.path, d.mode)
+		err := d.fs.Chmod(d.path, d.mode)
 		if err != nil {
 			return fmt.Errorf("dst chmod: %w", err)
 		}
 	}
 
-	// Set new content.
-	d.content = data
-
 	return nil
 }
 
 // Delete removes the destination file.
 func (d *Destination) Delete() error {
-	return os.RemoveAll(d.path)
+	return d.fs.RemoveAll(d.path)
+}
+
+// Diff returns a unified diff between the current file and
+// what would be written if data were passed to Write.
+// New files are diffed against /dev/null.
+func (d *Destination) Diff(data any) (string, error) {
+	buf, err := d.Encode(data)
+	if err != nil {
+		return "", err
+	}
+	return d.diff(buf, false)
+}
+
+// DeleteDiff returns a unified diff between the current file
+// and /dev/null.
+func (d *Destination) DeleteDiff() (string, error) {
+	return d.diff(nil, true)
+}
+
+func (d *Destination) diff(updated []byte, deleted bool) (string, error) {
+	name := filepath.ToSlash(d.RelativePath())
+	from := "a/" + name
+	to := "b/" + name
+
+	var current []byte
+	if d.Exists() && !d.IsDir() {
+		buf, err := d.fs.ReadFile(d.path)
+		if err != nil {
+			return "", fmt.Errorf("dst path read: %w", err)
+		}
+		current = buf
+	} else {
+		from = diffutil.DevNull
+	}
+	if deleted {
+		to = diffutil.DevNull
+	}
+
+	return diffutil.Unified(current, updated, from, to)
 }
 
 // ForPath returns a new Destination for the given path and values.
@@ -214,7 +336,7 @@ func (d *Destination) ForPath(path string, values map[string]any) (Destination,
 		ModeTpl:        d.ModeTpl,
 		PathTpl:        *render.MustCompile(path),
 	}
-	if err := dst.SetValues(values); err != nil {
+	if err := dst.SetValues(d.fs, values); err != nil {
 		return dst, fmt.Errorf("for path: %w", err)
 	}
 	return dst, nil
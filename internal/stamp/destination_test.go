@@ -10,6 +10,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/twelvelabs/termite/render"
 	"github.com/twelvelabs/termite/testutil"
+
+	"github.com/twelvelabs/stamp/internal/vfs"
 )
 
 func TestDestination_ForPath(t *testing.T) {
@@ -24,7 +26,7 @@ func TestDestination_ForPath(t *testing.T) {
 		"DstPath": "testdata",
 	}
 
-	err := dst.SetValues(values)
+	err := dst.SetValues(vfs.NewOS(), values)
 	assert.NoError(t, err)
 	assert.Equal(t, "templates", filepath.Base(dst.Path()))
 	assert.Equal(t, FileTypeText, dst.ContentType())
@@ -46,7 +48,7 @@ func TestDestination_ForPath(t *testing.T) {
 func TestDestination_FilesystemMethods(t *testing.T) {
 	var err error
 
-	dst := Destination{}
+	dst := Destination{fs: vfs.NewOS()}
 	values := map[string]any{}
 
 	dst, err = dst.ForPath("unknown", values)
@@ -258,7 +260,7 @@ func TestDestination_SetValues(t *testing.T) {
 					tt.setup(t, dir)
 				}
 
-				err := tt.dest.SetValues(tt.values)
+				err := tt.dest.SetValues(vfs.NewOS(), tt.values)
 
 				if tt.err == "" {
 					assert.NoError(t, err)
@@ -358,7 +360,7 @@ func TestDestination_Write(t *testing.T) {
 					tt.setup(t, dir)
 				}
 
-				err := tt.dest.SetValues(tt.values)
+				err := tt.dest.SetValues(vfs.NewOS(), tt.values)
 				assert.NoError(t, err)
 
 				err = tt.dest.Write(tt.data)
@@ -385,7 +387,7 @@ func TestDestination_Delete(t *testing.T) {
 		dest := Destination{
 			PathTpl: *render.MustCompile(`example.txt`),
 		}
-		err := dest.SetValues(map[string]any{
+		err := dest.SetValues(vfs.NewOS(), map[string]any{
 			"DstPath": ".",
 		})
 		assert.NoError(t, err)
@@ -397,3 +399,39 @@ func TestDestination_Delete(t *testing.T) {
 		assert.NoFileExists(t, dest.Path())
 	})
 }
+
+func TestDestination_Diff(t *testing.T) {
+	testutil.InTempDir(t, func(dir string) {
+		testutil.WritePaths(t, dir, map[string]any{
+			"example.txt": "Hello\n",
+		})
+
+		dest := Destination{
+			PathTpl: *render.MustCompile(`example.txt`),
+		}
+		err := dest.SetValues(vfs.NewOS(), map[string]any{
+			"DstPath": ".",
+		})
+		assert.NoError(t, err)
+
+		diff, err := dest.Diff("Hello\nWorld\n")
+		assert.NoError(t, err)
+		assert.Equal(t, "--- a/example.txt\n+++ b/example.txt\n@@ -1 +1,2 @@\n Hello\n+World\n", diff)
+
+		diff, err = dest.DeleteDiff()
+		assert.NoError(t, err)
+		assert.Equal(t, "--- a/example.txt\n+++ /dev/null\n@@ -1 +0,0 @@\n-Hello\n", diff)
+
+		missing := Destination{
+			PathTpl: *render.MustCompile(`missing.txt`),
+		}
+		err = missing.SetValues(vfs.NewOS(), map[string]any{
+			"DstPath": ".",
+		})
+		assert.NoError(t, err)
+
+		diff, err = missing.Diff("Hello\n")
+		assert.NoError(t, err)
+		assert.Equal(t, "--- /dev/null\n+++ b/missing.txt\n@@ -0,0 +1 @@\n+Hello\n", diff)
+	})
+}
@@ -1,27 +1,28 @@
 package stamp
 
-import (
-	"path/filepath"
-	"strings"
-
-	"github.com/twelvelabs/stamp/internal/encode"
-)
-
 // cspell: words: createtask updatetask
 //go:generate go-enum -f=$GOFILE -t ../enums.tmpl --marshal --names --nocomments
 
 // Determines what to do when creating a new file and
 // the destination path already exists.
 //
+// When using `merge`, the generated version of each file is recorded
+// in the `.stamp/` directory of the destination and used as the merge base
+// the next time the generator is run. It should be committed alongside the project.
+// If no version has been recorded yet (i.e. the first merge),
+// only the lines that differ from the existing file get conflict markers.
+//
 // > [!IMPORTANT]
-// > Only used in [create] tasks.
+// > Only used in [create] and [move] tasks.
 //
 // [create]: https://github.com/twelvelabs/stamp/tree/main/docs/create_task.md
+// [move]: https://github.com/twelvelabs/stamp/tree/main/docs/move_task.md
 /*
 	ENUM(
 		keep     // Keep the existing path. The task becomes a noop.
 		replace  // Replace the existing path.
-		prompt   // Prompt the user.
+		prompt   // Prompt the user to overwrite, skip, or view a diff.
+		merge    // Three-way merge with the last generated version. Collisions get conflict markers.
 	).
 */
 type ConflictConfig string
@@ -35,14 +36,30 @@ type ConflictConfig string
 */
 type MatchSource string
 
+// Determines which matching lines to insert the source content next to.
+//
+// > [!IMPORTANT]
+// > Only used with the insert-before and insert-after [actions].
+//
+// [actions]: https://github.com/twelvelabs/stamp/tree/main/docs/action.md
+/*
+	ENUM(
+		first  // The first matching line.
+		last   // The last matching line.
+		all    // Every matching line.
+	).
+*/
+type MatchOccurrence string
+
 // Determines what to do when updating an existing file and
 // the destination path is missing.
 //
 // > [!IMPORTANT]
-// > Only used in [update] and [delete] tasks.
+// > Only used in [update], [delete], and [move] tasks.
 //
 // [update]: https://github.com/twelvelabs/stamp/tree/main/docs/update_task.md
 // [delete]: https://github.com/twelvelabs/stamp/tree/main/docs/delete_task.md
+// [move]: https://github.com/twelvelabs/stamp/tree/main/docs/move_task.md
 /*
 	ENUM(
 		ignore  // Do nothing. The task becomes a noop.
@@ -52,74 +69,31 @@ type MatchSource string
 */
 type MissingConfig string
 
-// Determines the visibility of the generator.
+// Describes how a file in the destination was changed by a generator run.
 /*
 	ENUM(
-		public   // Callable anywhere.
-		hidden   // Public, but hidden in the generator list.
-		private  // Only callable as a sub-generator. Never displayed.
+		create  // The file did not exist prior to the run.
+		update  // The file existed and was modified.
+		delete  // The file existed and was removed.
 	).
 */
-type VisibilityType string
+type FileAction string
 
-// Specifies the content type of the file.
-// Inferred from the file extension by default.
-//
-// When the content type is JSON or YAML, the file will be
-// parsed into a data structure before use.
-// When updating files, the content type determines
-// the behavior of the [match.pattern] attribute.
-//
-// [match.pattern]: https://github.com/twelvelabs/stamp/tree/main/docs/match.md#pattern
+// Determines how generator runs report task actions.
 /*
 	ENUM(
-		json
-		yaml
-		text
+		text  // Human readable, colorized log lines.
+		json  // One JSON event per line.
 	).
 */
-type FileType string
-
-// Encoder returns the encoder for this content type.
-func (ft FileType) Encoder() encode.Encoder { //nolint:ireturn
-	switch ft {
-	case FileTypeJson:
-		return &encode.JSONEncoder{}
-	case FileTypeYaml:
-		return &encode.YAMLEncoder{}
-	default: // FileTypeText
-		return &encode.TextEncoder{}
-	}
-}
+type OutputFormat string
 
-// IsStructured returns true if the receiver is JSON or YAML.
-func (ft FileType) IsStructured() bool {
-	switch ft {
-	case FileTypeJson, FileTypeYaml:
-		return true
-	default:
-		return false
-	}
-}
-
-// ParseFileTypeWithFallback parses value into a FileType or,
-// if value is empty, attempts to infer the FileType from the given path.
-func ParseFileTypeWithFallback(value string, path string) (FileType, error) {
-	if value != "" {
-		return ParseFileType(value)
-	}
-	return ParseFileTypeFromPath(path)
-}
-
-// ParseFileTypeFromPath returns the correct file type for the given path.
-func ParseFileTypeFromPath(path string) (FileType, error) {
-	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
-	switch ext {
-	case "json":
-		return FileTypeJson, nil
-	case "yaml", "yml":
-		return FileTypeYaml, nil
-	default:
-		return FileTypeText, nil
-	}
-}
+// Determines the visibility of the generator.
+/*
+	ENUM(
+		public   // Callable anywhere.
+		hidden   // Public, but hidden in the generator list.
+		private  // Only callable as a sub-generator. Never displayed.
+	).
+*/
+type VisibilityType string
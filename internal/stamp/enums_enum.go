@@ -17,8 +17,10 @@ const (
 	ConflictConfigKeep ConflictConfig = "keep"
 	// Replace the existing path.
 	ConflictConfigReplace ConflictConfig = "replace"
-	// Prompt the user.
+	// Prompt the user to overwrite, skip, or view a diff.
 	ConflictConfigPrompt ConflictConfig = "prompt"
+	// Three-way merge with the last generated version. Collisions get conflict markers.
+	ConflictConfigMerge ConflictConfig = "merge"
 )
 
 var ErrInvalidConflictConfig = fmt.Errorf("not a valid ConflictConfig, try [%s]", strings.Join(_ConflictConfigNames, ", "))
@@ -27,6 +29,7 @@ var _ConflictConfigNames = []string{
 	string(ConflictConfigKeep),
 	string(ConflictConfigReplace),
 	string(ConflictConfigPrompt),
+	string(ConflictConfigMerge),
 }
 
 // ConflictConfigNames returns a list of possible string values of ConflictConfig.
@@ -52,6 +55,7 @@ var _ConflictConfigValue = map[string]ConflictConfig{
 	"keep":    ConflictConfigKeep,
 	"replace": ConflictConfigReplace,
 	"prompt":  ConflictConfigPrompt,
+	"merge":   ConflictConfigMerge,
 }
 
 // ParseConflictConfig attempts to convert a string to a ConflictConfig.
@@ -105,10 +109,17 @@ func (x ConflictConfig) Description() string {
 	return `Determines what to do when creating a new file and
 the destination path already exists.
 
+When using 'merge', the generated version of each file is recorded
+in the '.stamp/' directory of the destination and used as the merge base
+the next time the generator is run. It should be committed alongside the project.
+If no version has been recorded yet (i.e. the first merge),
+only the lines that differ from the existing file get conflict markers.
+
 > [!IMPORTANT]
-> Only used in [create] tasks.
+> Only used in [create] and [move] tasks.
 
-[create]: https://github.com/twelvelabs/stamp/tree/main/docs/create_task.md`
+[create]: https://github.com/twelvelabs/stamp/tree/main/docs/create_task.md
+[move]: https://github.com/twelvelabs/stamp/tree/main/docs/move_task.md`
 }
 
 // Enum implements the jsonschema.Enum interface.
@@ -117,6 +128,7 @@ func (x ConflictConfig) Enum() []any {
 		"keep",
 		"replace",
 		"prompt",
+		"merge",
 	}
 }
 
@@ -125,65 +137,69 @@ func (x ConflictConfig) EnumComments() []string {
 	return []string{
 		"Keep the existing path. The task becomes a noop.",
 		"Replace the existing path.",
-		"Prompt the user.",
+		"Prompt the user to overwrite, skip, or view a diff.",
+		"Three-way merge with the last generated version. Collisions get conflict markers.",
 	}
 }
 
 const (
-	FileTypeJson FileType = "json"
-	FileTypeYaml FileType = "yaml"
-	FileTypeText FileType = "text"
+	// The file did not exist prior to the run.
+	FileActionCreate FileAction = "create"
+	// The file existed and was modified.
+	FileActionUpdate FileAction = "update"
+	// The file existed and was removed.
+	FileActionDelete FileAction = "delete"
 )
 
-var ErrInvalidFileType = fmt.Errorf("not a valid FileType, try [%s]", strings.Join(_FileTypeNames, ", "))
+var ErrInvalidFileAction = fmt.Errorf("not a valid FileAction, try [%s]", strings.Join(_FileActionNames, ", "))
 
-var _FileTypeNames = []string{
-	string(FileTypeJson),
-	string(FileTypeYaml),
-	string(FileTypeText),
+var _FileActionNames = []string{
+	string(FileActionCreate),
+	string(FileActionUpdate),
+	string(FileActionDelete),
 }
 
-// FileTypeNames returns a list of possible string values of FileType.
-func FileTypeNames() []string {
-	tmp := make([]string, len(_FileTypeNames))
-	copy(tmp, _FileTypeNames)
+// FileActionNames returns a list of possible string values of FileAction.
+func FileActionNames() []string {
+	tmp := make([]string, len(_FileActionNames))
+	copy(tmp, _FileActionNames)
 	return tmp
 }
 
 // String implements the Stringer interface.
-func (x FileType) String() string {
+func (x FileAction) String() string {
 	return string(x)
 }
 
 // IsValid provides a quick way to determine if the typed value is
 // part of the allowed enumerated values
-func (x FileType) IsValid() bool {
-	_, err := ParseFileType(string(x))
+func (x FileAction) IsValid() bool {
+	_, err := ParseFileAction(string(x))
 	return err == nil
 }
 
-var _FileTypeValue = map[string]FileType{
-	"json": FileTypeJson,
-	"yaml": FileTypeYaml,
-	"text": FileTypeText,
+var _FileActionValue = map[string]FileAction{
+	"create": FileActionCreate,
+	"update": FileActionUpdate,
+	"delete": FileActionDelete,
 }
 
-// ParseFileType attempts to convert a string to a FileType.
-func ParseFileType(name string) (FileType, error) {
-	if x, ok := _FileTypeValue[name]; ok {
+// ParseFileAction attempts to convert a string to a FileAction.
+func ParseFileAction(name string) (FileAction, error) {
+	if x, ok := _FileActionValue[name]; ok {
 		return x, nil
 	}
-	return FileType(""), fmt.Errorf("%s is %w", name, ErrInvalidFileType)
+	return FileAction(""), fmt.Errorf("%s is %w", name, ErrInvalidFileAction)
 }
 
 // MarshalText implements the text marshaller method.
-func (x FileType) MarshalText() ([]byte, error) {
+func (x FileAction) MarshalText() ([]byte, error) {
 	return []byte(string(x)), nil
 }
 
 // UnmarshalText implements the text unmarshaller method.
-func (x *FileType) UnmarshalText(text []byte) error {
-	tmp, err := ParseFileType(string(text))
+func (x *FileAction) UnmarshalText(text []byte) error {
+	tmp, err := ParseFileAction(string(text))
 	if err != nil {
 		return err
 	}
@@ -195,19 +211,19 @@ func (x *FileType) UnmarshalText(text []byte) error {
 // (allocating a larger slice if necessary) and returns the updated slice.
 //
 // Implementations must not retain b, nor mutate any bytes within b[:len(b)].
-func (x *FileType) AppendText(b []byte) ([]byte, error) {
+func (x *FileAction) AppendText(b []byte) ([]byte, error) {
 	return append(b, x.String()...), nil
 }
 
 var (
-	_ jsonschema.Described = FileType("")
-	_ jsonschema.Enum      = FileType("")
-	_ jsonschema.Preparer  = FileType("")
+	_ jsonschema.Described = FileAction("")
+	_ jsonschema.Enum      = FileAction("")
+	_ jsonschema.Preparer  = FileAction("")
 )
 
 // PrepareJSONSchema implements the jsonschema.Preparer interface.
-func (x FileType) PrepareJSONSchema(schema *jsonschema.Schema) error {
-	schema.WithTitle("FileType")
+func (x FileAction) PrepareJSONSchema(schema *jsonschema.Schema) error {
+	schema.WithTitle("FileAction")
 	schema.WithDescription(x.Description())
 	schema.WithEnum(x.Enum()...)
 	schema.WithExtraPropertiesItem("enumDescriptions", x.EnumComments())
@@ -215,33 +231,141 @@ func (x FileType) PrepareJSONSchema(schema *jsonschema.Schema) error {
 }
 
 // Enum implements the jsonschema.Described interface.
-func (x FileType) Description() string {
-	return `Specifies the content type of the file.
-Inferred from the file extension by default.
+func (x FileAction) Description() string {
+	return `Describes how a file in the destination was changed by a generator run.`
+}
+
+// Enum implements the jsonschema.Enum interface.
+func (x FileAction) Enum() []any {
+	return []any{
+		"create",
+		"update",
+		"delete",
+	}
+}
+
+// EnumComments returns the comment associated with each enum.
+func (x FileAction) EnumComments() []string {
+	return []string{
+		"The file did not exist prior to the run.",
+		"The file existed and was modified.",
+		"The file existed and was removed.",
+	}
+}
 
-When the content type is JSON or YAML, the file will be
-parsed into a data structure before use.
-When updating files, the content type determines
-the behavior of the [match.pattern] attribute.
+const (
+	// The first matching line.
+	MatchOccurrenceFirst MatchOccurrence = "first"
+	// The last matching line.
+	MatchOccurrenceLast MatchOccurrence = "last"
+	// Every matching line.
+	MatchOccurrenceAll MatchOccurrence = "all"
+)
 
-[match.pattern]: https://github.com/twelvelabs/stamp/tree/main/docs/match.md#pattern`
+var ErrInvalidMatchOccurrence = fmt.Errorf("not a valid MatchOccurrence, try [%s]", strings.Join(_MatchOccurrenceNames, ", "))
+
+var _MatchOccurrenceNames = []string{
+	string(MatchOccurrenceFirst),
+	string(MatchOccurrenceLast),
+	string(MatchOccurrenceAll),
+}
+
+// MatchOccurrenceNames returns a list of possible string values of MatchOccurrence.
+func MatchOccurrenceNames() []string {
+	tmp := make([]string, len(_MatchOccurrenceNames))
+	copy(tmp, _MatchOccurrenceNames)
+	return tmp
+}
+
+// String implements the Stringer interface.
+func (x MatchOccurrence) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x MatchOccurrence) IsValid() bool {
+	_, err := ParseMatchOccurrence(string(x))
+	return err == nil
+}
+
+var _MatchOccurrenceValue = map[string]MatchOccurrence{
+	"first": MatchOccurrenceFirst,
+	"last":  MatchOccurrenceLast,
+	"all":   MatchOccurrenceAll,
+}
+
+// ParseMatchOccurrence attempts to convert a string to a MatchOccurrence.
+func ParseMatchOccurrence(name string) (MatchOccurrence, error) {
+	if x, ok := _MatchOccurrenceValue[name]; ok {
+		return x, nil
+	}
+	return MatchOccurrence(""), fmt.Errorf("%s is %w", name, ErrInvalidMatchOccurrence)
+}
+
+// MarshalText implements the text marshaller method.
+func (x MatchOccurrence) MarshalText() ([]byte, error) {
+	return []byte(string(x)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *MatchOccurrence) UnmarshalText(text []byte) error {
+	tmp, err := ParseMatchOccurrence(string(text))
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+// AppendText appends the textual representation of itself to the end of b
+// (allocating a larger slice if necessary) and returns the updated slice.
+//
+// Implementations must not retain b, nor mutate any bytes within b[:len(b)].
+func (x *MatchOccurrence) AppendText(b []byte) ([]byte, error) {
+	return append(b, x.String()...), nil
+}
+
+var (
+	_ jsonschema.Described = MatchOccurrence("")
+	_ jsonschema.Enum      = MatchOccurrence("")
+	_ jsonschema.Preparer  = MatchOccurrence("")
+)
+
+// PrepareJSONSchema implements the jsonschema.Preparer interface.
+func (x MatchOccurrence) PrepareJSONSchema(schema *jsonschema.Schema) error {
+	schema.WithTitle("MatchOccurrence")
+	schema.WithDescription(x.Description())
+	schema.WithEnum(x.Enum()...)
+	schema.WithExtraPropertiesItem("enumDescriptions", x.EnumComments())
+	return nil
+}
+
+// Enum implements the jsonschema.Described interface.
+func (x MatchOccurrence) Description() string {
+	return `Determines which matching lines to insert the source content next to.
+
+> [!IMPORTANT]
+> Only used with the insert-before and insert-after [actions].
+
+[actions]: https://github.com/twelvelabs/stamp/tree/main/docs/action.md`
 }
 
 // Enum implements the jsonschema.Enum interface.
-func (x FileType) Enum() []any {
+func (x MatchOccurrence) Enum() []any {
 	return []any{
-		"json",
-		"yaml",
-		"text",
+		"first",
+		"last",
+		"all",
 	}
 }
 
 // EnumComments returns the comment associated with each enum.
-func (x FileType) EnumComments() []string {
+func (x MatchOccurrence) EnumComments() []string {
 	return []string{
-		"",
-		"",
-		"",
+		"The first matching line.",
+		"The last matching line.",
+		"Every matching line.",
 	}
 }
 
@@ -444,10 +568,11 @@ func (x MissingConfig) Description() string {
 the destination path is missing.
 
 > [!IMPORTANT]
-> Only used in [update] and [delete] tasks.
+> Only used in [update], [delete], and [move] tasks.
 
 [update]: https://github.com/twelvelabs/stamp/tree/main/docs/update_task.md
-[delete]: https://github.com/twelvelabs/stamp/tree/main/docs/delete_task.md`
+[delete]: https://github.com/twelvelabs/stamp/tree/main/docs/delete_task.md
+[move]: https://github.com/twelvelabs/stamp/tree/main/docs/move_task.md`
 }
 
 // Enum implements the jsonschema.Enum interface.
@@ -468,6 +593,111 @@ func (x MissingConfig) EnumComments() []string {
 	}
 }
 
+const (
+	// Human readable, colorized log lines.
+	OutputFormatText OutputFormat = "text"
+	// One JSON event per line.
+	OutputFormatJson OutputFormat = "json"
+)
+
+var ErrInvalidOutputFormat = fmt.Errorf("not a valid OutputFormat, try [%s]", strings.Join(_OutputFormatNames, ", "))
+
+var _OutputFormatNames = []string{
+	string(OutputFormatText),
+	string(OutputFormatJson),
+}
+
+// OutputFormatNames returns a list of possible string values of OutputFormat.
+func OutputFormatNames() []string {
+	tmp := make([]string, len(_OutputFormatNames))
+	copy(tmp, _OutputFormatNames)
+	return tmp
+}
+
+// String implements the Stringer interface.
+func (x OutputFormat) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x OutputFormat) IsValid() bool {
+	_, err := ParseOutputFormat(string(x))
+	return err == nil
+}
+
+var _OutputFormatValue = map[string]OutputFormat{
+	"text": OutputFormatText,
+	"json": OutputFormatJson,
+}
+
+// ParseOutputFormat attempts to convert a string to a OutputFormat.
+func ParseOutputFormat(name string) (OutputFormat, error) {
+	if x, ok := _OutputFormatValue[name]; ok {
+		return x, nil
+	}
+	return OutputFormat(""), fmt.Errorf("%s is %w", name, ErrInvalidOutputFormat)
+}
+
+// MarshalText implements the text marshaller method.
+func (x OutputFormat) MarshalText() ([]byte, error) {
+	return []byte(string(x)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *OutputFormat) UnmarshalText(text []byte) error {
+	tmp, err := ParseOutputFormat(string(text))
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+// AppendText appends the textual representation of itself to the end of b
+// (allocating a larger slice if necessary) and returns the updated slice.
+//
+// Implementations must not retain b, nor mutate any bytes within b[:len(b)].
+func (x *OutputFormat) AppendText(b []byte) ([]byte, error) {
+	return append(b, x.String()...), nil
+}
+
+var (
+	_ jsonschema.Described = OutputFormat("")
+	_ jsonschema.Enum      = OutputFormat("")
+	_ jsonschema.Preparer  = OutputFormat("")
+)
+
+// PrepareJSONSchema implements the jsonschema.Preparer interface.
+func (x OutputFormat) PrepareJSONSchema(schema *jsonschema.Schema) error {
+	schema.WithTitle("OutputFormat")
+	schema.WithDescription(x.Description())
+	schema.WithEnum(x.Enum()...)
+	schema.WithExtraPropertiesItem("enumDescriptions", x.EnumComments())
+	return nil
+}
+
+// Enum implements the jsonschema.Described interface.
+func (x OutputFormat) Description() string {
+	return `Determines how generator runs report task actions.`
+}
+
+// Enum implements the jsonschema.Enum interface.
+func (x OutputFormat) Enum() []any {
+	return []any{
+		"text",
+		"json",
+	}
+}
+
+// EnumComments returns the comment associated with each enum.
+func (x OutputFormat) EnumComments() []string {
+	return []string{
+		"Human readable, colorized log lines.",
+		"One JSON event per line.",
+	}
+}
+
 const (
 	// Callable anywhere.
 	VisibilityTypePublic VisibilityType = "public"
@@ -50,6 +50,26 @@ func TestMatchSource(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestMatchOccurrence(t *testing.T) {
+	name := MatchOccurrenceNames()[0]
+	enum := MatchOccurrence(name)
+
+	assert.Equal(t, true, enum.IsValid())
+	assert.Equal(t, name, enum.String())
+
+	buf, err := enum.MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(name), buf)
+
+	err = (&enum).UnmarshalText(buf)
+	assert.NoError(t, err)
+	err = (&enum).UnmarshalText([]byte{})
+	assert.Error(t, err)
+
+	err = enum.PrepareJSONSchema(&jsonschema.Schema{})
+	assert.NoError(t, err)
+}
+
 func TestMissingConfig(t *testing.T) {
 	name := MissingConfigNames()[0]
 	enum := MissingConfig(name)
@@ -70,9 +90,9 @@ func TestMissingConfig(t *testing.T) {
 	assert.NoError(t, err)
 }
 
-func TestVisibilityType(t *testing.T) {
-	name := VisibilityTypeNames()[0]
-	enum := VisibilityType(name)
+func TestFileAction(t *testing.T) {
+	name := FileActionNames()[0]
+	enum := FileAction(name)
 
 	assert.Equal(t, true, enum.IsValid())
 	assert.Equal(t, name, enum.String())
@@ -90,9 +110,9 @@ func TestVisibilityType(t *testing.T) {
 	assert.NoError(t, err)
 }
 
-func TestFileType(t *testing.T) {
-	name := FileTypeNames()[0]
-	enum := FileType(name)
+func TestOutputFormat(t *testing.T) {
+	name := OutputFormatNames()[0]
+	enum := OutputFormat(name)
 
 	assert.Equal(t, true, enum.IsValid())
 	assert.Equal(t, name, enum.String())
@@ -110,48 +130,22 @@ func TestFileType(t *testing.T) {
 	assert.NoError(t, err)
 }
 
-func TestParseFileTypeFromPath(t *testing.T) {
-	tests := []struct {
-		path      string
-		expected  FileType
-		assertion assert.ErrorAssertionFunc
-	}{
-		{
-			path:      "example.json",
-			expected:  FileTypeJson,
-			assertion: assert.NoError,
-		},
-		{
-			path:      "example.yaml",
-			expected:  FileTypeYaml,
-			assertion: assert.NoError,
-		},
-		{
-			path:      "example.yml",
-			expected:  FileTypeYaml,
-			assertion: assert.NoError,
-		},
-		{
-			path:      "example.text",
-			expected:  FileTypeText,
-			assertion: assert.NoError,
-		},
-		{
-			path:      "example.nope",
-			expected:  FileTypeText,
-			assertion: assert.NoError,
-		},
-		{
-			path:      "example",
-			expected:  FileTypeText,
-			assertion: assert.NoError,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.path, func(t *testing.T) {
-			actual, err := ParseFileTypeFromPath(tt.path)
-			tt.assertion(t, err)
-			assert.Equal(t, tt.expected, actual)
-		})
-	}
+func TestVisibilityType(t *testing.T) {
+	name := VisibilityTypeNames()[0]
+	enum := VisibilityType(name)
+
+	assert.Equal(t, true, enum.IsValid())
+	assert.Equal(t, name, enum.String())
+
+	buf, err := enum.MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(name), buf)
+
+	err = (&enum).UnmarshalText(buf)
+	assert.NoError(t, err)
+	err = (&enum).UnmarshalText([]byte{})
+	assert.Error(t, err)
+
+	err = enum.PrepareJSONSchema(&jsonschema.Schema{})
+	assert.NoError(t, err)
 }
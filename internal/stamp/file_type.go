@@ -0,0 +1,164 @@
+package stamp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/swaggest/jsonschema-go"
+
+	"github.com/twelvelabs/stamp/internal/encode"
+)
+
+// FileType is the name of a format registered with [encode.RegisterFormat].
+type FileType string
+
+const (
+	FileTypeDotenv   FileType = "dotenv"
+	FileTypeGo       FileType = "go"
+	FileTypeIni      FileType = "ini"
+	FileTypeJson     FileType = "json"
+	FileTypeMarkdown FileType = "markdown"
+	FileTypeText     FileType = "text"
+	FileTypeToml     FileType = "toml"
+	FileTypeXml      FileType = "xml"
+	FileTypeYaml     FileType = "yaml"
+)
+
+var ErrInvalidFileType = errors.New("not a valid FileType")
+
+var (
+	_ jsonschema.Described = FileType("")
+	_ jsonschema.Enum      = FileType("")
+	_ jsonschema.Preparer  = FileType("")
+)
+
+// FileTypeNames returns a list of possible string values of FileType.
+func FileTypeNames() []string {
+	names := []string{}
+	for _, f := range encode.RegisteredFormats() {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// ParseFileType attempts to convert a string to a FileType.
+func ParseFileType(name string) (FileType, error) {
+	if _, err := encode.GetFormat(name); err != nil {
+		return FileType(""), fmt.Errorf(
+			"%s is %w, try [%s]", name, ErrInvalidFileType, strings.Join(FileTypeNames(), ", "),
+		)
+	}
+	return FileType(name), nil
+}
+
+// ParseFileTypeWithFallback parses value into a FileType or,
+// if value is empty, attempts to infer the FileType from the given path.
+func ParseFileTypeWithFallback(value string, path string) (FileType, error) {
+	if value != "" {
+		return ParseFileType(value)
+	}
+	return ParseFileTypeFromPath(path)
+}
+
+// ParseFileTypeFromPath returns the correct file type for the given path
+// (based on the file extension). Defaults to text.
+func ParseFileTypeFromPath(path string) (FileType, error) {
+	if f, err := encode.GetFormatForPath(path); err == nil {
+		return FileType(f.Name), nil
+	}
+	return FileTypeText, nil
+}
+
+// String implements the Stringer interface.
+func (ft FileType) String() string {
+	return string(ft)
+}
+
+// IsValid returns true if the receiver is a registered format.
+func (ft FileType) IsValid() bool {
+	_, err := encode.GetFormat(string(ft))
+	return err == nil
+}
+
+// Encoder returns the encoder for this content type
+// (or the text encoder if the content type is not registered).
+func (ft FileType) Encoder() encode.Encoder { //nolint:ireturn
+	if f, err := encode.GetFormat(string(ft)); err == nil {
+		return f.Encoder
+	}
+	return &encode.TextEncoder{}
+}
+
+// IsStructured returns true if the receiver is decoded into
+// a data structure (i.e. JSON, YAML, TOML, dotenv, or INI).
+func (ft FileType) IsStructured() bool {
+	f, err := encode.GetFormat(string(ft))
+	return err == nil && f.Structured
+}
+
+// MarshalText implements the text marshaller method.
+func (ft FileType) MarshalText() ([]byte, error) {
+	return []byte(string(ft)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (ft *FileType) UnmarshalText(text []byte) error {
+	tmp, err := ParseFileType(string(text))
+	if err != nil {
+		return err
+	}
+	*ft = tmp
+	return nil
+}
+
+// PrepareJSONSchema implements the jsonschema.Preparer interface.
+func (ft FileType) PrepareJSONSchema(schema *jsonschema.Schema) error {
+	schema.WithTitle("FileType")
+	schema.WithDescription(ft.Description())
+	schema.WithEnum(ft.Enum()...)
+	schema.WithExtraPropertiesItem("enumDescriptions", ft.EnumComments())
+	return nil
+}
+
+// Description implements the jsonschema.Described interface.
+func (ft FileType) Description() string {
+	return `Specifies the content type of the file.
+Inferred from the file extension by default.
+For backward compatibility, dotenv, INI, markdown, and go are never inferred
+(existing files with those extensions are still written as-is)
+and must be set explicitly.
+
+When the content type is structured (JSON, YAML, TOML, dotenv, or INI),
+the file will be parsed into a data structure before use.
+JSON files may contain comments and trailing commas (i.e. JSONC).
+When the content type is XML, the file will be parsed into
+a document and updates are targeted with XPath expressions.
+When the content type is markdown, updates are targeted
+with a heading path (i.e. "## Usage > ### Flags").
+When the content type is go, updates are targeted with a declaration
+(i.e. "import", "func:main", "struct:Config", or "switch:run")
+and the result is formatted with gofmt.
+When updating files, the content type determines
+the behavior of the [match.pattern] attribute.
+
+[match.pattern]: https://github.com/twelvelabs/stamp/tree/main/docs/match.md#pattern`
+}
+
+// Enum implements the jsonschema.Enum interface.
+func (ft FileType) Enum() []any {
+	enum := []any{}
+	for _, name := range FileTypeNames() {
+		enum = append(enum, name)
+	}
+	return enum
+}
+
+// EnumComments returns the description of each registered format.
+func (ft FileType) EnumComments() []string {
+	comments := []string{}
+	for _, f := range encode.RegisteredFormats() {
+		comments = append(comments, f.Description)
+	}
+	return comments
+}
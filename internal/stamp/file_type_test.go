@@ -0,0 +1,147 @@
+package stamp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/swaggest/jsonschema-go"
+
+	"github.com/twelvelabs/stamp/internal/encode"
+)
+
+func TestFileType(t *testing.T) {
+	name := FileTypeNames()[0]
+	enum := FileType(name)
+
+	assert.Equal(t, true, enum.IsValid())
+	assert.Equal(t, name, enum.String())
+
+	buf, err := enum.MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(name), buf)
+
+	err = (&enum).UnmarshalText(buf)
+	assert.NoError(t, err)
+	err = (&enum).UnmarshalText([]byte{})
+	assert.Error(t, err)
+
+	err = enum.PrepareJSONSchema(&jsonschema.Schema{})
+	assert.NoError(t, err)
+}
+
+func TestParseFileTypeFromPath(t *testing.T) {
+	tests := []struct {
+		path      string
+		expected  FileType
+		assertion assert.ErrorAssertionFunc
+	}{
+		{
+			path:      "example.json",
+			expected:  FileTypeJson,
+			assertion: assert.NoError,
+		},
+		{
+			path:      "example.jsonc",
+			expected:  FileTypeJson,
+			assertion: assert.NoError,
+		},
+		{
+			path:      "example.yaml",
+			expected:  FileTypeYaml,
+			assertion: assert.NoError,
+		},
+		{
+			path:      "example.yml",
+			expected:  FileTypeYaml,
+			assertion: assert.NoError,
+		},
+		{
+			path:      "example.toml",
+			expected:  FileTypeToml,
+			assertion: assert.NoError,
+		},
+		{
+			path:      "pom.xml",
+			expected:  FileTypeXml,
+			assertion: assert.NoError,
+		},
+		{
+			path:      "Example.csproj",
+			expected:  FileTypeXml,
+			assertion: assert.NoError,
+		},
+		{
+			path:      ".env",
+			expected:  FileTypeText,
+			assertion: assert.NoError,
+		},
+		{
+			path:      "config/.env.example",
+			expected:  FileTypeText,
+			assertion: assert.NoError,
+		},
+		{
+			path:      "setup.cfg",
+			expected:  FileTypeText,
+			assertion: assert.NoError,
+		},
+		{
+			path:      "main.go",
+			expected:  FileTypeText,
+			assertion: assert.NoError,
+		},
+		{
+			path:      "example.text",
+			expected:  FileTypeText,
+			assertion: assert.NoError,
+		},
+		{
+			path:      "example.nope",
+			expected:  FileTypeText,
+			assertion: assert.NoError,
+		},
+		{
+			path:      "example",
+			expected:  FileTypeText,
+			assertion: assert.NoError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			actual, err := ParseFileTypeFromPath(tt.path)
+			tt.assertion(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestFileType_RegisteredFormats(t *testing.T) {
+	format := encode.Format{
+		Name:       "properties",
+		Extensions: []string{"properties"},
+		Encoder:    &encode.TextEncoder{},
+	}
+
+	_, err := ParseFileType("properties")
+	assert.ErrorIs(t, err, ErrInvalidFileType)
+
+	encode.RegisterFormat(format)
+	defer encode.UnregisterFormat(format)
+
+	ft, err := ParseFileType("properties")
+	assert.NoError(t, err)
+	assert.Equal(t, format.Encoder, ft.Encoder())
+	assert.Equal(t, false, ft.IsStructured())
+	assert.Contains(t, ft.Enum(), "properties")
+
+	ft, err = ParseFileTypeFromPath("app.properties")
+	assert.NoError(t, err)
+	assert.Equal(t, FileType("properties"), ft)
+
+	// Can't re-register another one w/ the same name.
+	assert.Panics(t, func() {
+		encode.RegisterFormat(encode.Format{
+			Name: "properties",
+		})
+	})
+}
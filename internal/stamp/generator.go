@@ -137,6 +137,12 @@ func NewGenerator(store *Store, pkg *pkg.Package) (*Generator, error) {
 		}
 	}
 
+	// Only the root package records where it was installed from,
+	// so nested generators inherit its origin.
+	if pkg.Root().IsRemote() {
+		gen.setRemote()
+	}
+
 	for _, vm := range gen.valueMetadata() {
 		v, err := value.NewValue(vm)
 		if err != nil {
@@ -192,6 +198,32 @@ type Generator struct {
 	Tasks      *TaskSet
 }
 
+// setRemote marks the receiver as installed from a remote origin,
+// so that its run tasks (and those of any sub-generators) require confirmation.
+func (g *Generator) setRemote() {
+	for _, t := range g.Tasks.All() {
+		switch t := t.(type) {
+		case *RunTask:
+			t.remote = true
+		case *GeneratorTask:
+			t.remote = true
+		}
+	}
+}
+
+// Execute runs the generator's tasks and records a manifest
+// and journal of the run in the destination dir.
+// Callers are responsible for rolling back ctx if an error is returned.
+func (g *Generator) Execute(ctx *TaskContext, values map[string]any) error {
+	if err := g.Tasks.Execute(ctx, values); err != nil {
+		return err
+	}
+	if _, err := RecordManifest(ctx, g, values); err != nil {
+		return err
+	}
+	return RecordJournal(ctx, g, values)
+}
+
 func (g *Generator) IsHidden() bool {
 	return g.Visibility == VisibilityTypeHidden
 }
@@ -11,6 +11,7 @@ import (
 type GeneratorMetadata struct {
 	Name        string         `mapstructure:"name" required:"true"`
 	Description string         `mapstructure:"description"`
+	Version     string         `mapstructure:"version"`
 	Visibility  VisibilityType `mapstructure:"visibility" default:"public"`
 	Values      []value.Value  `mapstructure:"values"`
 	Tasks       []TaskSchema   `mapstructure:"tasks"`
@@ -97,6 +98,14 @@ func (m *GeneratorMetadata) PrepareJSONSchema(schema *jsonschema.Schema) error {
 				"The full description is used when viewing generator help/usage text.",
 		)
 
+	schema.Properties["version"].TypeObject.
+		WithTitle("Version").
+		WithDescription(
+			"An optional generator version. " +
+				"Recorded in the manifest of each project the generator is run against.",
+		).
+		WithExamples("1.0.0")
+
 	schema.Properties["visibility"].TypeObject.
 		WithTitle("Visibility").
 		WithDescription("How the generator may be viewed or invoked.")
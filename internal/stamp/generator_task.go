@@ -14,6 +14,10 @@ type GeneratorTask struct {
 	Name   string         `mapstructure:"name"   title:"Name" required:"true" description:"The name of the generator to execute." validate:"required"`         //nolint: lll
 	Values map[string]any `mapstructure:"values" title:"Values"               description:"Optional key/value pairs to pass to the generator." default:"{}"`   //nolint: lll
 	Type   string         `mapstructure:"type"   title:"Type" required:"true" description:"Executes another generator." const:"generator" default:"generator"` //nolint: lll
+
+	// Set for tasks belonging to generators installed from a remote origin.
+	// The sub-generator is treated as remote too.
+	remote bool
 }
 
 // PrepareJSONSchema implements the jsonschema.Preparer interface.
@@ -67,6 +71,9 @@ func (t *GeneratorTask) Execute(ctx *TaskContext, values map[string]any) error {
 	if err != nil {
 		return err
 	}
+	if t.remote {
+		gen.setRemote()
+	}
 
 	renderedValues, err := render.Map(t.Values, values)
 	if err != nil {
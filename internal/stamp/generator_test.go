@@ -5,9 +5,11 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/twelvelabs/termite/testutil"
 
 	"github.com/twelvelabs/stamp/internal/pkg"
+	"github.com/twelvelabs/stamp/internal/vfs"
 )
 
 func TestNewGenerator(t *testing.T) {
@@ -135,6 +137,131 @@ func TestGenerator_AddsValuesFromDelegatedGenerators(t *testing.T) {
 	})
 }
 
+func TestNewGenerator_MarksRunTasksFromRemoteOrigins(t *testing.T) {
+	store := NewTestStore()
+	for origin, remote := range map[string]bool{
+		"":                                 false,
+		"file:///packages/foo":             false,
+		"git::https://example.com/foo.git": true,
+	} {
+		p := &pkg.Package{
+			Metadata: map[string]any{
+				"origin": origin,
+				"tasks": []any{
+					map[string]any{
+						"type":    "run",
+						"command": "true",
+					},
+				},
+			},
+		}
+		gen, err := NewGenerator(store, p)
+		assert.NoError(t, err)
+		assert.Equal(t, remote, gen.Tasks.All()[0].(*RunTask).remote, origin)
+	}
+}
+
+func TestNewGenerator_MarksNestedRunTasksFromRemoteOrigins(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"store/remote/generator.yaml": "name: remote\n" +
+				"origin: git::https://example.com/remote.git\n" +
+				"tasks:\n" +
+				"  - type: generator\n" +
+				"    name: remote:child\n",
+			"store/remote/child/generator.yaml": "name: remote:child\n" +
+				"tasks:\n" +
+				"  - type: run\n" +
+				"    command: \"true\"\n",
+		})
+		app := NewTestApp()
+		app.Store = NewStore(filepath.Join(tmpDir, "store"))
+		app.Config.NoInput = true
+
+		// Loaded directly, the nested generator inherits the root package origin.
+		child, err := app.Store.Load("remote:child")
+		require.NoError(t, err)
+		assert.True(t, child.Tasks.All()[0].(*RunTask).remote)
+
+		// Run via the root generator, the command still requires confirmation.
+		gen, err := app.Store.Load("remote")
+		require.NoError(t, err)
+		values := gen.Values.GetAll()
+		values["DstPath"] = tmpDir
+		err = gen.Tasks.Execute(NewTaskContext(app), values)
+		assert.ErrorIs(t, err, ErrNoInput)
+	})
+}
+
+func TestGeneratorTask_MarksSubGeneratorsOfRemoteGenerators(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"store/local/generator.yaml": "name: local\n" +
+				"tasks:\n" +
+				"  - type: run\n" +
+				"    command: \"true\"\n",
+		})
+		app := NewTestApp()
+		app.Store = NewStore(filepath.Join(tmpDir, "store"))
+		app.Config.NoInput = true
+
+		gen, err := NewGenerator(app.Store, &pkg.Package{
+			Metadata: map[string]any{
+				"origin": "git::https://example.com/remote.git",
+				"tasks": []any{
+					map[string]any{
+						"type": "generator",
+						"name": "local",
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		values := gen.Values.GetAll()
+		values["DstPath"] = tmpDir
+		err = gen.Tasks.Execute(NewTaskContext(app), values)
+		assert.ErrorIs(t, err, ErrNoInput, "sub-generators of remote generators are remote")
+	})
+}
+
+func TestGenerator_ExecuteWithMemoryFS(t *testing.T) {
+	store := NewTestStore() // must call before changing dirs
+	app := NewTestApp()
+	app.Store = store
+
+	testutil.InTempDir(t, func(tmpDir string) {
+		gen, err := store.Load("file")
+		assert.NoError(t, err)
+
+		fsys := vfs.NewMemory(vfs.NewOS())
+		app.FS = fsys
+		ctx := NewTaskContext(app)
+
+		values := gen.Values.GetAll()
+		values["FileName"] = "hello.txt"
+		values["DstPath"] = tmpDir
+		assert.NoError(t, gen.Execute(ctx, values))
+
+		// Written to memory (along with the state dir), but not to disk.
+		path := filepath.Join(tmpDir, "hello.txt")
+		assert.True(t, vfs.Exists(fsys, path))
+		assert.True(t, vfs.Exists(fsys, NewState(fsys, tmpDir).JournalPath()))
+		assert.Equal(t, []FileChange{
+			{Path: "hello.txt", Action: FileActionCreate},
+		}, ctx.Journal.Changes(tmpDir))
+		testutil.AssertPaths(t, tmpDir, map[string]any{
+			"hello.txt": false,
+			".stamp":    false,
+		})
+
+		assert.NoError(t, fsys.Commit())
+		testutil.AssertPaths(t, tmpDir, map[string]any{
+			"hello.txt": "",
+		})
+		assert.FileExists(t, NewState(vfs.NewOS(), tmpDir).JournalPath())
+	})
+}
+
 func TestGenerator_Description(t *testing.T) {
 	gen := &Generator{
 		Package: &pkg.Package{
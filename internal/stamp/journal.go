@@ -0,0 +1,237 @@
+package stamp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/twelvelabs/stamp/internal/vfs"
+)
+
+// NewJournal returns a new, empty Journal for paths in fsys.
+func NewJournal(fsys vfs.FS) *Journal {
+	return &Journal{
+		fs:      fsys,
+		entries: []*JournalEntry{},
+		index:   map[string]*JournalEntry{},
+	}
+}
+
+// Journal records the original state of destination paths
+// prior to them being mutated by a task.
+// Used to restore the destination dir if a generator fails part way through
+// and, once saved to the state dir, to undo the last successful run.
+type Journal struct {
+	mu      sync.Mutex
+	fs      vfs.FS
+	entries []*JournalEntry
+	index   map[string]*JournalEntry
+}
+
+// JournalEntry is a snapshot of a single path prior to mutation.
+type JournalEntry struct {
+	Path    string
+	Existed bool
+	IsDir   bool
+	Link    string
+	Mode    os.FileMode
+	Content []byte
+	// Written is true if a task recorded the path in order to create or change it
+	// (as opposed to it being recorded as an ancestor, or as part of a tree).
+	Written bool
+
+	// Checksum of the path as of the end of the run.
+	// Only set on journals read from the state dir (see [State.ReadJournal]).
+	Checksum string
+}
+
+// Entries returns all entries in the order they were recorded.
+func (j *Journal) Entries() []*JournalEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]*JournalEntry{}, j.entries...)
+}
+
+// Reset removes all recorded entries.
+func (j *Journal) Reset() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries = []*JournalEntry{}
+	j.index = map[string]*JournalEntry{}
+}
+
+// Record snapshots the current state of path prior to a task
+// creating or changing it.
+//
+// Only the first call for a given path is recorded (subsequent calls are noops),
+// since the journal only cares about the state prior to the run.
+// Any missing ancestor dirs are also recorded so that they can be removed
+// on rollback. Existing dirs are recorded without their contents
+// (use [Journal.RecordTree] before removing a dir).
+func (j *Journal) Record(path string) error {
+	return j.recordPath(path, false)
+}
+
+// RecordTree is like [Journal.Record], but also snapshots
+// everything beneath path. Used before a dir is removed or moved.
+func (j *Journal) RecordTree(path string) error {
+	return j.recordPath(path, true)
+}
+
+func (j *Journal) recordPath(path string, tree bool) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	path = filepath.Clean(path)
+
+	// Record missing ancestors, outermost first.
+	missing := []string{}
+	for dir := filepath.Dir(path); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if _, err := j.fs.Lstat(dir); !errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		missing = append([]string{dir}, missing...)
+	}
+	for _, dir := range missing {
+		if err := j.record(dir, false); err != nil {
+			return err
+		}
+	}
+
+	if err := j.record(path, tree); err != nil {
+		return err
+	}
+	j.index[path].Written = true
+	return nil
+}
+
+func (j *Journal) record(path string, tree bool) error {
+	if _, ok := j.index[path]; ok {
+		return nil
+	}
+
+	entry := &JournalEntry{
+		Path: path,
+	}
+	info, err := j.fs.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		j.add(entry)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("journal record: %w", err)
+	}
+
+	entry.Existed = true
+	entry.Mode = info.Mode().Perm()
+
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		entry.Link, err = j.fs.Readlink(path)
+		if err != nil {
+			return fmt.Errorf("journal record: %w", err)
+		}
+		j.add(entry)
+	case info.IsDir():
+		entry.IsDir = true
+		j.add(entry)
+		if !tree {
+			return nil
+		}
+		children, err := j.fs.ReadDir(path)
+		if err != nil {
+			return fmt.Errorf("journal record: %w", err)
+		}
+		for _, child := range children {
+			if err := j.record(filepath.Join(path, child.Name()), true); err != nil {
+				return err
+			}
+		}
+	default:
+		entry.Content, err = j.fs.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("journal record: %w", err)
+		}
+		j.add(entry)
+	}
+
+	return nil
+}
+
+// FileChange is a file in the destination that was changed by a run.
+type FileChange struct {
+	// Path is slash separated and relative to the destination root.
+	Path   string
+	Action FileAction
+}
+
+// Changes returns the files beneath root that differ from when they
+// were recorded, sorted by path. Dirs and the state dir are ignored.
+func (j *Journal) Changes(root string) []FileChange {
+	changes := []FileChange{}
+	for _, e := range j.Entries() {
+		rel, ok := manifestRel(root, e.Path)
+		if !ok || e.IsDir || vfs.IsDir(j.fs, e.Path) {
+			continue
+		}
+		exists := vfs.Exists(j.fs, e.Path)
+		switch {
+		case !e.Existed && exists:
+			changes = append(changes, FileChange{Path: rel, Action: FileActionCreate})
+		case e.Existed && !exists:
+			changes = append(changes, FileChange{Path: rel, Action: FileActionDelete})
+		case e.Existed && exists:
+			if content, err := j.fs.ReadFile(e.Path); err == nil && bytes.Equal(content, e.Content) {
+				continue
+			}
+			changes = append(changes, FileChange{Path: rel, Action: FileActionUpdate})
+		}
+	}
+	sort.Slice(changes, func(i, k int) bool {
+		return changes[i].Path < changes[k].Path
+	})
+	return changes
+}
+
+func (j *Journal) add(entry *JournalEntry) {
+	j.entries = append(j.entries, entry)
+	j.index[entry.Path] = entry
+}
+
+// Restore returns the path in fsys to the state it was in when recorded.
+func (e *JournalEntry) Restore(fsys vfs.FS) error {
+	if !e.Existed {
+		return fsys.RemoveAll(e.Path)
+	}
+
+	if e.IsDir {
+		if info, err := fsys.Lstat(e.Path); err == nil && !info.IsDir() {
+			if err := fsys.Remove(e.Path); err != nil {
+				return err
+			}
+		}
+		if err := fsys.MkdirAll(e.Path, e.Mode); err != nil {
+			return err
+		}
+		return fsys.Chmod(e.Path, e.Mode)
+	}
+
+	// Whatever is at path now (file, link, or dir) needs to go.
+	if err := fsys.RemoveAll(e.Path); err != nil {
+		return err
+	}
+	if err := fsys.MkdirAll(filepath.Dir(e.Path), DstDirMode); err != nil {
+		return err
+	}
+	if e.Link != "" {
+		return fsys.Symlink(e.Link, e.Path)
+	}
+	if err := fsys.WriteFile(e.Path, e.Content, e.Mode); err != nil {
+		return err
+	}
+	return fsys.Chmod(e.Path, e.Mode)
+}
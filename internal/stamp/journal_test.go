@@ -0,0 +1,207 @@
+package stamp
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twelvelabs/termite/testutil"
+
+	"github.com/twelvelabs/stamp/internal/vfs"
+)
+
+func TestJournal_Record(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"aaa/bbb.txt": "bbb",
+			"aaa/ccc.txt": "ccc",
+		})
+
+		j := NewJournal(vfs.NewOS())
+		require.NoError(t, j.Record(filepath.Join(tmpDir, "aaa")))
+		require.NoError(t, j.Record(filepath.Join(tmpDir, "aaa", "bbb.txt")))
+		require.NoError(t, j.Record(filepath.Join(tmpDir, "xxx", "yyy", "zzz.txt")))
+
+		paths := []string{}
+		for _, e := range j.Entries() {
+			rel, _ := filepath.Rel(tmpDir, e.Path)
+			paths = append(paths, rel)
+		}
+		assert.Equal(t, []string{
+			"aaa",
+			"aaa/bbb.txt",
+			"xxx",
+			"xxx/yyy",
+			"xxx/yyy/zzz.txt",
+		}, paths, "existing dirs should be recorded without their contents, ancestors first, and without dupes")
+
+		entries := j.Entries()
+		assert.True(t, entries[0].Existed)
+		assert.True(t, entries[0].IsDir)
+		assert.True(t, entries[1].Existed)
+		assert.Equal(t, []byte("bbb"), entries[1].Content)
+		assert.True(t, entries[1].Written)
+		assert.False(t, entries[2].Existed)
+		assert.False(t, entries[2].Written, "ancestors are not written")
+		assert.False(t, entries[4].Existed)
+
+		j.Reset()
+		assert.Empty(t, j.Entries())
+	})
+}
+
+func TestJournal_RecordTree(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"aaa/bbb.txt":     "bbb",
+			"aaa/ccc/ddd.txt": "ddd",
+		})
+
+		j := NewJournal(vfs.NewOS())
+		require.NoError(t, j.RecordTree(filepath.Join(tmpDir, "aaa")))
+		require.NoError(t, j.Record(filepath.Join(tmpDir, "aaa", "bbb.txt")))
+
+		paths := []string{}
+		written := []string{}
+		for _, e := range j.Entries() {
+			rel, _ := filepath.Rel(tmpDir, e.Path)
+			paths = append(paths, rel)
+			if e.Written {
+				written = append(written, rel)
+			}
+		}
+		assert.Equal(t, []string{
+			"aaa",
+			"aaa/bbb.txt",
+			"aaa/ccc",
+			"aaa/ccc/ddd.txt",
+		}, paths, "dirs should be recorded recursively")
+		assert.Equal(t, []string{
+			"aaa",
+			"aaa/bbb.txt",
+		}, written, "re-recording a path should mark it as written")
+
+		require.NoError(t, os.RemoveAll(filepath.Join(tmpDir, "aaa")))
+		entries := j.Entries()
+		for i := len(entries) - 1; i >= 0; i-- {
+			require.NoError(t, entries[i].Restore(vfs.NewOS()))
+		}
+		testutil.AssertPaths(t, tmpDir, map[string]any{
+			"aaa/bbb.txt":     "bbb",
+			"aaa/ccc/ddd.txt": "ddd",
+		})
+	})
+}
+
+func TestJournal_Changes(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"deleted.txt":   "deleted",
+			"unchanged.txt": "unchanged",
+			"updated.txt":   "before",
+		})
+
+		j := NewJournal(vfs.NewOS())
+		for _, rel := range []string{"deleted.txt", "unchanged.txt", "updated.txt", "docs/created.txt", ".stamp/journal.yaml"} {
+			require.NoError(t, j.Record(filepath.Join(tmpDir, rel)))
+		}
+		require.NoError(t, os.Remove(filepath.Join(tmpDir, "deleted.txt")))
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"updated.txt":         "after",
+			"docs/created.txt":    "created",
+			".stamp/journal.yaml": "ignored",
+		})
+
+		assert.Equal(t, []FileChange{
+			{Path: "deleted.txt", Action: FileActionDelete},
+			{Path: "docs/created.txt", Action: FileActionCreate},
+			{Path: "updated.txt", Action: FileActionUpdate},
+		}, j.Changes(tmpDir))
+	})
+}
+
+func TestTaskContext_Rollback(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"README.md":        "Pre-existing content",
+			"docs/guide.md":    "Guide",
+			"scripts/build.sh": "#!/bin/bash",
+		})
+		require.NoError(t, os.Chmod(filepath.Join(tmpDir, "scripts", "build.sh"), 0755))
+
+		app := NewTestApp()
+		ctx := NewTaskContext(app)
+
+		ts := NewTaskSet()
+		ts.DstPath = tmpDir
+		for _, data := range []map[string]any{
+			{
+				"type": "create",
+				"src":  map[string]any{"content": "Replaced"},
+				"dst":  map[string]any{"path": "README.md", "conflict": "replace"},
+			},
+			{
+				"type": "create",
+				"src":  map[string]any{"content": "New"},
+				"dst":  map[string]any{"path": "new/nested/file.txt"},
+			},
+			{
+				"type": "update",
+				"src":  map[string]any{"content": "echo 'hi'"},
+				"dst":  map[string]any{"path": "scripts/build.sh", "mode": "0644"},
+			},
+			{
+				"type": "delete",
+				"dst":  map[string]any{"path": "docs"},
+			},
+		} {
+			task, err := NewTask(data)
+			require.NoError(t, err)
+			ts.Add(task)
+		}
+		ts.Add(NewTaskMock(true, errors.New("boom"), nil))
+
+		err := ts.Execute(ctx, map[string]any{})
+		assert.ErrorContains(t, err, "boom")
+
+		testutil.AssertPaths(t, tmpDir, map[string]any{
+			"README.md":           "Replaced",
+			"new/nested/file.txt": "New",
+			"scripts/build.sh":    []any{"echo 'hi'", 0644},
+			"docs/":               false,
+		})
+
+		err = ctx.Rollback()
+		assert.NoError(t, err)
+
+		testutil.AssertPaths(t, tmpDir, map[string]any{
+			"README.md":        "Pre-existing content",
+			"new/":             false,
+			"scripts/build.sh": []any{"#!/bin/bash", 0755},
+			"docs/guide.md":    "Guide",
+		})
+		assert.Empty(t, ctx.Journal.Entries())
+	})
+}
+
+func TestTaskContext_RollbackDoesNothingOnDryRun(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		app := NewTestApp()
+		app.Config.DryRun = true
+		ctx := NewTaskContext(app)
+
+		task, err := NewTask(map[string]any{
+			"type": "create",
+			"src":  map[string]any{"content": "New"},
+			"dst":  map[string]any{"path": "file.txt"},
+		})
+		require.NoError(t, err)
+
+		err = task.Execute(ctx, map[string]any{"DstPath": tmpDir})
+		assert.NoError(t, err)
+		assert.Empty(t, ctx.Journal.Entries())
+	})
+}
@@ -0,0 +1,243 @@
+package stamp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+const (
+	JSONEventAction  = "action"
+	JSONEventSummary = "summary"
+)
+
+// JSONEvent is a single line of [JSONLogger] output.
+type JSONEvent struct {
+	Event   string `json:"event"`
+	DryRun  bool   `json:"dry_run"`
+	Error   string `json:"error,omitempty"`
+	Elapsed int64  `json:"duration_ms"`
+
+	// Action events.
+	Action    string `json:"action,omitempty"`
+	Level     string `json:"level,omitempty"`
+	Path      string `json:"path,omitempty"`
+	Message   string `json:"message,omitempty"`
+	Diff      string `json:"diff,omitempty"`
+	TaskType  string `json:"task_type,omitempty"`
+	TaskIndex *int   `json:"task_index,omitempty"`
+	Iteration *int   `json:"iteration,omitempty"`
+
+	// Summary events.
+	Success *bool          `json:"success,omitempty"`
+	Actions map[string]int `json:"actions,omitempty"`
+	Tasks   *int           `json:"tasks,omitempty"`
+}
+
+// jsonTaskFrame tracks a task execution in progress.
+type jsonTaskFrame struct {
+	taskType  string
+	index     int
+	iteration *int
+	started   time.Time
+	events    []*JSONEvent
+}
+
+var _ Logger = &JSONLogger{}
+
+// NewJSONLogger returns a new JSONLogger.
+func NewJSONLogger(w io.Writer, dryRun bool) *JSONLogger {
+	return &JSONLogger{
+		w:       w,
+		dryRun:  dryRun,
+		now:     time.Now,
+		actions: map[string]int{},
+	}
+}
+
+// JSONLogger writes task actions as newline delimited JSON events
+// (followed by a summary event at the end of the run).
+//
+// Events are buffered until their task finishes so that
+// any error returned by the task can be included.
+type JSONLogger struct {
+	w      io.Writer
+	dryRun bool
+	now    func() time.Time
+
+	started time.Time
+	frames  []*jsonTaskFrame
+	actions map[string]int
+	tasks   int
+}
+
+// Info logs an informational action.
+func (l *JSONLogger) Info(action string, line string, args ...any) {
+	l.log("info", action, line, args...)
+}
+
+// Success logs an action that completed successfully.
+func (l *JSONLogger) Success(action string, line string, args ...any) {
+	l.log("success", action, line, args...)
+}
+
+// Warning logs an action that needs the user's attention.
+func (l *JSONLogger) Warning(action string, line string, args ...any) {
+	l.log("warning", action, line, args...)
+}
+
+// Failure logs an action that failed.
+func (l *JSONLogger) Failure(action string, line string, args ...any) {
+	l.log("failure", action, line, args...)
+}
+
+// Diff adds the diff to the previous action event.
+func (l *JSONLogger) Diff(diff string) {
+	frame := l.frame()
+	if frame == nil || len(frame.events) == 0 {
+		return
+	}
+	frame.events[len(frame.events)-1].Diff = diff
+}
+
+// TaskStarted begins buffering events for the task.
+func (l *JSONLogger) TaskStarted(task Task, index int, iteration int) {
+	l.start()
+	l.tasks++
+
+	frame := &jsonTaskFrame{
+		taskType: task.TypeKey(),
+		index:    index,
+		started:  l.now(),
+	}
+	if iteration != NoIteration {
+		frame.iteration = &iteration
+	}
+	l.frames = append(l.frames, frame)
+}
+
+// TaskFinished writes the events buffered for the task.
+// If err is non-nil, it is added to any failure events
+// (or a new "fail" event if the task did not log one).
+func (l *JSONLogger) TaskFinished(err error) {
+	frame := l.frame()
+	if frame == nil {
+		return
+	}
+	l.frames = l.frames[:len(l.frames)-1]
+
+	if err != nil {
+		failed := false
+		for _, e := range frame.events {
+			if e.Level == "failure" {
+				e.Error = err.Error()
+				failed = true
+			}
+		}
+		if !failed {
+			l.actions["fail"]++
+			frame.events = append(frame.events, l.newEvent(frame, "failure", "fail", ""))
+			frame.events[len(frame.events)-1].Error = err.Error()
+		}
+	}
+	for _, e := range frame.events {
+		l.write(e)
+	}
+}
+
+// Summary writes the final summary event.
+func (l *JSONLogger) Summary(err error) {
+	l.start()
+	success := err == nil
+	tasks := l.tasks
+	event := &JSONEvent{
+		Event:   JSONEventSummary,
+		DryRun:  l.dryRun,
+		Elapsed: l.now().Sub(l.started).Milliseconds(),
+		Success: &success,
+		Actions: l.actions,
+		Tasks:   &tasks,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	l.write(event)
+}
+
+func (l *JSONLogger) log(level, action, line string, args ...any) {
+	l.start()
+	l.actions[action]++
+
+	// Lines may include a trailing newline (see TaskLogger.ensureNewline).
+	line = strings.TrimSuffix(line, "\n")
+
+	// See the path convention in the Logger docs.
+	path := line
+	if len(args) > 0 {
+		path = cast.ToString(args[0])
+	}
+
+	frame := l.frame()
+	event := l.newEvent(frame, level, action, path)
+	event.Message = line
+	if len(args) > 0 {
+		event.Message = fmt.Sprintf(line, args...)
+	}
+	if frame == nil {
+		// Logged outside of a task (i.e. during rollback).
+		l.write(event)
+		return
+	}
+	frame.events = append(frame.events, event)
+}
+
+func (l *JSONLogger) newEvent(frame *jsonTaskFrame, level, action, path string) *JSONEvent {
+	event := &JSONEvent{
+		Event:  JSONEventAction,
+		DryRun: l.dryRun,
+		Action: action,
+		Level:  level,
+		Path:   path,
+	}
+	if frame != nil {
+		index := frame.index
+		event.TaskType = frame.taskType
+		event.TaskIndex = &index
+		event.Iteration = frame.iteration
+		event.Elapsed = l.now().Sub(frame.started).Milliseconds()
+	}
+	return event
+}
+
+// frame returns the innermost task in progress (or nil).
+func (l *JSONLogger) frame() *jsonTaskFrame {
+	if len(l.frames) == 0 {
+		return nil
+	}
+	return l.frames[len(l.frames)-1]
+}
+
+// start records the start time of the run on first use.
+func (l *JSONLogger) start() {
+	if l.started.IsZero() {
+		l.started = l.now()
+	}
+}
+
+// withTime calls fn with the clock fixed at t (i.e. when replaying
+// calls that were buffered while tasks ran concurrently).
+func (l *JSONLogger) withTime(t time.Time, fn func()) {
+	now := l.now
+	l.now = func() time.Time { return t }
+	defer func() { l.now = now }()
+	fn()
+}
+
+func (l *JSONLogger) write(event *JSONEvent) {
+	buf, _ := json.Marshal(event) // only contains marshalable types
+	fmt.Fprintf(l.w, "%s\n", buf)
+}
@@ -0,0 +1,188 @@
+package stamp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twelvelabs/termite/testutil"
+)
+
+// newTestJSONLogger returns a JSONLogger whose clock advances
+// by one second each time it is read.
+func newTestJSONLogger(dryRun bool) (*JSONLogger, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	logger := NewJSONLogger(buf, dryRun)
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	logger.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	return logger, buf
+}
+
+func decodeJSONEvents(t *testing.T, buf fmt.Stringer) []map[string]any {
+	t.Helper()
+	events := []map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		event := map[string]any{}
+		require.NoError(t, json.Unmarshal([]byte(line), &event))
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestJSONLogger(t *testing.T) {
+	logger, buf := newTestJSONLogger(true)
+	task := &CreateTask{Type: "create"}
+
+	logger.TaskStarted(task, 0, NoIteration)
+	logger.Success("create", "README.md")
+	logger.Diff("+hello\n")
+	logger.TaskFinished(nil)
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"), "events should be written when the task finishes")
+
+	logger.TaskStarted(task, 1, 2)
+	logger.Warning("conflict", "%s already exists\n", "docs/100%.md")
+	logger.Failure("fail", "docs/100%.md")
+	logger.TaskFinished(errors.New("boom"))
+
+	logger.Warning("rollback", "README.md")
+	logger.Summary(errors.New("boom"))
+
+	assert.Equal(t, []map[string]any{
+		{
+			"event":       "action",
+			"dry_run":     true,
+			"action":      "create",
+			"level":       "success",
+			"path":        "README.md",
+			"message":     "README.md",
+			"diff":        "+hello\n",
+			"task_type":   "create",
+			"task_index":  0.0,
+			"duration_ms": 1000.0,
+		},
+		{
+			"event":       "action",
+			"dry_run":     true,
+			"action":      "conflict",
+			"level":       "warning",
+			"path":        "docs/100%.md",
+			"message":     "docs/100%.md already exists",
+			"task_type":   "create",
+			"task_index":  1.0,
+			"iteration":   2.0,
+			"duration_ms": 1000.0,
+		},
+		{
+			"event":       "action",
+			"dry_run":     true,
+			"action":      "fail",
+			"level":       "failure",
+			"path":        "docs/100%.md",
+			"message":     "docs/100%.md",
+			"error":       "boom",
+			"task_type":   "create",
+			"task_index":  1.0,
+			"iteration":   2.0,
+			"duration_ms": 2000.0,
+		},
+		{
+			"event":       "action",
+			"dry_run":     true,
+			"action":      "rollback",
+			"level":       "warning",
+			"path":        "README.md",
+			"message":     "README.md",
+			"duration_ms": 0.0,
+		},
+		{
+			"event":       "summary",
+			"dry_run":     true,
+			"success":     false,
+			"error":       "boom",
+			"tasks":       2.0,
+			"duration_ms": 6000.0,
+			"actions": map[string]any{
+				"create":   1.0,
+				"conflict": 1.0,
+				"fail":     1.0,
+				"rollback": 1.0,
+			},
+		},
+	}, decodeJSONEvents(t, buf))
+}
+
+func TestJSONLogger_AddsFailEventForUnloggedErrors(t *testing.T) {
+	logger, buf := newTestJSONLogger(false)
+
+	logger.TaskStarted(&GeneratorTask{Type: "generator"}, 3, NoIteration)
+	logger.TaskFinished(errors.New("boom"))
+
+	events := decodeJSONEvents(t, buf)
+	require.Len(t, events, 1)
+	assert.Equal(t, "fail", events[0]["action"])
+	assert.Equal(t, "boom", events[0]["error"])
+	assert.Equal(t, "generator", events[0]["task_type"])
+	assert.Equal(t, 3.0, events[0]["task_index"])
+}
+
+func TestJSONLogger_BufferedDurations(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	buf := &bytes.Buffer{}
+	logger := NewJSONLogger(buf, false)
+	logger.now = clock
+	buffered := &bufferedLogger{out: logger, now: clock}
+
+	buffered.TaskStarted(&GeneratorTask{Type: "generator"}, 0, NoIteration)
+	now = now.Add(20 * time.Millisecond)
+	buffered.Success("generate", "foo")
+	buffered.TaskFinished(nil)
+	now = now.Add(50 * time.Millisecond)
+	buffered.flush()
+
+	events := decodeJSONEvents(t, buf)
+	require.Len(t, events, 1)
+	assert.Equal(t, 20.0, events[0]["duration_ms"], "should be measured when the event happened")
+}
+
+func TestJSONLogger_TaskSet(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		app := NewTestApp()
+		app.Config.Output = OutputFormatJson
+		ctx := NewTaskContext(app)
+		require.IsType(t, &JSONLogger{}, ctx.Logger)
+
+		ts := NewTaskSet()
+		ts.DstPath = tmpDir
+		task, err := NewTask(map[string]any{
+			"type": "create",
+			"each": "foo, bar",
+			"src":  map[string]any{"content": "{{ ._Item }}"},
+			"dst":  map[string]any{"path": "{{ ._Item }}.txt"},
+		})
+		require.NoError(t, err)
+		ts.Add(task)
+
+		require.NoError(t, ts.Execute(ctx, map[string]any{}))
+		ctx.Logger.Summary(nil)
+
+		events := decodeJSONEvents(t, app.IO.Out)
+		require.Len(t, events, 3)
+		assert.Equal(t, "foo.txt", events[0]["path"])
+		assert.Equal(t, 0.0, events[0]["iteration"])
+		assert.Equal(t, "bar.txt", events[1]["path"])
+		assert.Equal(t, 1.0, events[1]["iteration"])
+		assert.Equal(t, "summary", events[2]["event"])
+		assert.Equal(t, true, events[2]["success"])
+	})
+}
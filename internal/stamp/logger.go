@@ -0,0 +1,55 @@
+package stamp
+
+const (
+	// NoIteration is the iteration passed to [Logger.TaskStarted]
+	// for tasks that do not use `each`.
+	NoIteration = -1
+)
+
+// Logger reports the actions taken by tasks.
+//
+// By convention, the line passed to the action methods is either the
+// destination path or a format string whose first arg is the path.
+type Logger interface {
+	// Info logs an informational action.
+	Info(action string, line string, args ...any)
+	// Success logs an action that completed successfully.
+	Success(action string, line string, args ...any)
+	// Warning logs an action that needs the user's attention.
+	Warning(action string, line string, args ...any)
+	// Failure logs an action that failed.
+	Failure(action string, line string, args ...any)
+	// Diff logs the changes made by the previous action.
+	Diff(diff string)
+
+	// TaskStarted is called before each execution of a task.
+	// The index is the position of the task in its [TaskSet],
+	// and iteration is the `_Index` value (or [NoIteration]).
+	TaskStarted(task Task, index int, iteration int)
+	// TaskFinished is called after each execution of a task.
+	TaskFinished(err error)
+	// Summary is called once the run (and any rollback) has finished.
+	Summary(err error)
+}
+
+// NewLogger returns the logger for the configured output format.
+func NewLogger(app *App) Logger { //nolint:ireturn
+	if app.Config.Output == OutputFormatJson {
+		return NewJSONLogger(app.IO.Out, app.Config.DryRun)
+	}
+	return NewTaskLogger(app.UI, app.Config.DryRun)
+}
+
+var _ Logger = &NopLogger{}
+
+// NopLogger discards everything logged to it.
+type NopLogger struct{}
+
+func (l *NopLogger) Info(_ string, _ string, _ ...any)    {}
+func (l *NopLogger) Success(_ string, _ string, _ ...any) {}
+func (l *NopLogger) Warning(_ string, _ string, _ ...any) {}
+func (l *NopLogger) Failure(_ string, _ string, _ ...any) {}
+func (l *NopLogger) Diff(_ string)                        {}
+func (l *NopLogger) TaskStarted(_ Task, _ int, _ int)     {}
+func (l *NopLogger) TaskFinished(_ error)                 {}
+func (l *NopLogger) Summary(_ error)                      {}
@@ -0,0 +1,346 @@
+package stamp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cast"
+	"gopkg.in/yaml.v3"
+
+	"github.com/twelvelabs/stamp/internal/fsutil"
+	"github.com/twelvelabs/stamp/internal/vfs"
+)
+
+var (
+	// Values that are specific to the machine the generator was run on
+	// and should not be recorded in the manifest.
+	manifestIgnoredValues = []string{"DstPath", "SrcPath"}
+)
+
+// Manifest records how a destination dir was generated:
+// which generator was run, with which values, and the files it produced.
+// Stored in the destination state dir (see [State.ManifestPath]).
+type Manifest struct {
+	Generator ManifestGenerator `yaml:"generator"`
+	Values    map[string]any    `yaml:"values"`
+	Files     []ManifestFile    `yaml:"files"`
+}
+
+// ManifestGenerator identifies the generator used to produce a destination dir.
+type ManifestGenerator struct {
+	Name     string `yaml:"name"`
+	Origin   string `yaml:"origin,omitempty"`
+	Version  string `yaml:"version,omitempty"`
+	Checksum string `yaml:"checksum"`
+}
+
+// ManifestFile is a file produced by the generator.
+type ManifestFile struct {
+	Path     string `yaml:"path"`
+	Checksum string `yaml:"checksum"`
+}
+
+// NewManifest returns a manifest for a completed run of gen.
+// Files are those recorded in the journal that still exist in the root dir.
+func NewManifest(gen *Generator, values map[string]any, root string, journal *Journal) (*Manifest, error) {
+	fsys := journal.fs
+	checksum, err := gen.Checksum()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{
+		Generator: ManifestGenerator{
+			Name:     gen.Name(),
+			Origin:   gen.Origin(),
+			Version:  gen.Version(),
+			Checksum: checksum,
+		},
+		Values: map[string]any{},
+		Files:  []ManifestFile{},
+	}
+
+	for k, v := range values {
+		m.Values[k] = v
+	}
+	for _, k := range manifestIgnoredValues {
+		delete(m.Values, k)
+	}
+
+	for _, rel := range journalFiles(root, journal) {
+		path := filepath.Join(root, rel)
+		if !vfs.Exists(fsys, path) || vfs.IsDir(fsys, path) {
+			continue // deleted during the run
+		}
+		sum, err := fileChecksum(fsys, path)
+		if err != nil {
+			return nil, err
+		}
+		m.Files = append(m.Files, ManifestFile{
+			Path:     rel,
+			Checksum: sum,
+		})
+	}
+
+	return m, nil
+}
+
+// File returns the entry for the given path (relative to the root dir) or nil.
+func (m *Manifest) File(rel string) *ManifestFile {
+	for i, f := range m.Files {
+		if f.Path == filepath.ToSlash(rel) {
+			return &m.Files[i]
+		}
+	}
+	return nil
+}
+
+// Changes compares the receiver (the manifest from a previous run)
+// with the journal of the latest run against the same root dir.
+//
+// Returns the paths that were changed upstream (i.e. modified by the latest run),
+// and those that were changed locally (i.e. modified by the user
+// in between the two runs).
+// Symlinks in root are resolved to match the paths in the journal.
+func (m *Manifest) Changes(root string, journal *Journal) ([]string, []string, error) {
+	root, err := fsutil.ResolvePath(root)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Determine the pre-run checksum for each path the latest run touched.
+	before := map[string]string{}
+	for _, e := range journal.Entries() {
+		rel, ok := manifestRel(root, e.Path)
+		if !ok || e.IsDir {
+			continue
+		}
+		before[rel] = ""
+		if e.Existed {
+			before[rel] = contentChecksum(e.Content)
+		}
+	}
+
+	upstream := []string{}
+	for _, rel := range journalFiles(root, journal) {
+		after := ""
+		path := filepath.Join(root, rel)
+		if vfs.Exists(journal.fs, path) && !vfs.IsDir(journal.fs, path) {
+			sum, err := fileChecksum(journal.fs, path)
+			if err != nil {
+				return nil, nil, err
+			}
+			after = sum
+		}
+		if after != before[rel] {
+			upstream = append(upstream, rel)
+		}
+	}
+
+	local := []string{}
+	for _, f := range m.Files {
+		current, touched := before[f.Path]
+		if !touched {
+			// Untouched by the latest run, so what's on disk is the pre-run state.
+			current = ""
+			path := filepath.Join(root, f.Path)
+			if vfs.Exists(journal.fs, path) {
+				sum, err := fileChecksum(journal.fs, path)
+				if err != nil {
+					return nil, nil, err
+				}
+				current = sum
+			}
+		}
+		if current != f.Checksum {
+			local = append(local, f.Path)
+		}
+	}
+	sort.Strings(local)
+
+	return upstream, local, nil
+}
+
+// ManifestPath returns the path to the manifest for the named generator.
+func (s *State) ManifestPath(name string) string {
+	return s.Path("manifests", filepath.Join(strings.Split(name, ":")...)+".yaml")
+}
+
+// ReadManifest returns the manifest for the named generator.
+// Returns [ErrNotFound] if the generator has not been run against the root dir.
+func (s *State) ReadManifest(name string) (*Manifest, error) {
+	return readManifest(s.fs, s.ManifestPath(name))
+}
+
+// Manifests returns all manifests in the state dir, ordered by generator name.
+func (s *State) Manifests() ([]*Manifest, error) {
+	manifests := []*Manifest{}
+	dir := s.Path("manifests")
+	if !vfs.Exists(s.fs, dir) {
+		return manifests, nil
+	}
+	err := vfs.WalkDir(s.fs, dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".yaml" {
+			return err
+		}
+		m, err := readManifest(s.fs, path)
+		if err != nil {
+			return err
+		}
+		manifests = append(manifests, m)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(manifests, func(i, j int) bool {
+		return manifests[i].Generator.Name < manifests[j].Generator.Name
+	})
+	return manifests, nil
+}
+
+// WriteManifest writes the manifest to the state dir.
+func (s *State) WriteManifest(m *Manifest) error {
+	buf, err := yaml.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("manifest encode: %w", err)
+	}
+	path := s.ManifestPath(m.Generator.Name)
+	if err := s.fs.MkdirAll(filepath.Dir(path), DstDirMode); err != nil {
+		return fmt.Errorf("manifest write: %w", err)
+	}
+	if err := s.fs.WriteFile(path, buf, DstFileMode); err != nil {
+		return fmt.Errorf("manifest write: %w", err)
+	}
+	return nil
+}
+
+// RecordManifest writes a manifest for a completed run of gen to the
+// state dir of the destination. Noop during a dry run.
+func RecordManifest(ctx *TaskContext, gen *Generator, values map[string]any) (*Manifest, error) {
+	if ctx.DryRun {
+		return nil, nil
+	}
+	root, err := dstRoot(gen, values)
+	if err != nil {
+		return nil, err
+	}
+	m, err := NewManifest(gen, values, root, ctx.Journal)
+	if err != nil {
+		return nil, err
+	}
+	state := NewState(ctx.FS, root)
+
+	// Files the run left alone (i.e. kept due to a conflict)
+	// are still owned by the generator, so carry them forward.
+	prev, err := state.ReadManifest(m.Generator.Name)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	if prev != nil {
+		m.carryForward(prev, root, ctx.Journal)
+	}
+
+	if err := ctx.Journal.Record(state.ManifestPath(m.Generator.Name)); err != nil {
+		return nil, err
+	}
+	return m, state.WriteManifest(m)
+}
+
+func (m *Manifest) carryForward(prev *Manifest, root string, journal *Journal) {
+	touched := map[string]bool{}
+	for _, rel := range journalFiles(root, journal) {
+		touched[rel] = true
+	}
+	for _, f := range prev.Files {
+		if touched[f.Path] || m.File(f.Path) != nil {
+			continue
+		}
+		if !vfs.Exists(journal.fs, filepath.Join(root, f.Path)) {
+			continue
+		}
+		m.Files = append(m.Files, f)
+	}
+	sort.SliceStable(m.Files, func(i, j int) bool {
+		return m.Files[i].Path < m.Files[j].Path
+	})
+}
+
+// dstRoot returns the resolved destination root dir for a run of gen.
+func dstRoot(gen *Generator, values map[string]any) (string, error) {
+	dstPath := cast.ToString(values["DstPath"])
+	if dstPath == "" {
+		dstPath = gen.Tasks.DstPath
+	}
+	return fsutil.ResolvePath(dstPath)
+}
+
+func readManifest(fsys vfs.FS, path string) (*Manifest, error) {
+	if !vfs.Exists(fsys, path) {
+		return nil, ErrNotFound
+	}
+	buf, err := fsys.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("manifest read: %w", err)
+	}
+	m := &Manifest{}
+	if err := yaml.Unmarshal(buf, m); err != nil {
+		return nil, fmt.Errorf("manifest decode: %w", err)
+	}
+	return m, nil
+}
+
+// journalFiles returns the sorted, unique, root relative paths of the files
+// written during the run (excluding the state dir). Paths only recorded
+// as part of a tree (i.e. the contents of a deleted dir) are excluded.
+func journalFiles(root string, journal *Journal) []string {
+	seen := map[string]bool{}
+	paths := []string{}
+	for _, e := range journal.Entries() {
+		rel, ok := manifestRel(root, e.Path)
+		if !ok || seen[rel] || !e.Written {
+			continue
+		}
+		// Skip paths that were (or now are) dirs.
+		if e.IsDir || vfs.IsDir(journal.fs, e.Path) {
+			continue
+		}
+		seen[rel] = true
+		paths = append(paths, rel)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// manifestRel returns the slash separated path relative to root,
+// or false if the path is outside root or inside the state dir.
+func manifestRel(root string, path string) (string, bool) {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == StateDir || strings.HasPrefix(rel, StateDir+"/") {
+		return "", false
+	}
+	return rel, true
+}
+
+func fileChecksum(fsys vfs.FS, path string) (string, error) {
+	content, err := fsys.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("checksum error: %w", err)
+	}
+	return contentChecksum(content), nil
+}
+
+func contentChecksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
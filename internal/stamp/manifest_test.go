@@ -0,0 +1,226 @@
+package stamp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twelvelabs/termite/testutil"
+
+	"github.com/twelvelabs/stamp/internal/vfs"
+)
+
+func TestRecordManifest(t *testing.T) {
+	app := NewTestApp()
+	gen, err := app.Store.Load("file")
+	require.NoError(t, err)
+
+	testutil.InTempDir(t, func(tmpDir string) {
+		ctx := NewTaskContext(app)
+		values := map[string]any{
+			"DstPath":     tmpDir,
+			"SrcPath":     gen.SrcPath(),
+			"FileName":    "hello.txt",
+			"FileContent": "Hello",
+		}
+
+		err := gen.Tasks.Execute(ctx, values)
+		require.NoError(t, err)
+
+		m, err := RecordManifest(ctx, gen, values)
+		require.NoError(t, err)
+
+		checksum, _ := gen.Checksum()
+		assert.Equal(t, ManifestGenerator{
+			Name:     "file",
+			Checksum: checksum,
+		}, m.Generator)
+		assert.Equal(t, map[string]any{
+			"FileName":    "hello.txt",
+			"FileContent": "Hello",
+		}, m.Values, "machine specific values should be omitted")
+		assert.Equal(t, []ManifestFile{
+			{Path: "hello.txt", Checksum: contentChecksum([]byte("Hello"))},
+		}, m.Files)
+
+		state := NewState(vfs.NewOS(), tmpDir)
+		read, err := state.ReadManifest("file")
+		require.NoError(t, err)
+		assert.Equal(t, m, read)
+
+		all, err := state.Manifests()
+		require.NoError(t, err)
+		assert.Equal(t, []*Manifest{m}, all)
+
+		// Manifest writes are journaled so they can be rolled back.
+		require.NoError(t, ctx.Rollback())
+		testutil.AssertPaths(t, tmpDir, map[string]any{
+			"hello.txt": false,
+			".stamp/":   false,
+		})
+	})
+}
+
+func TestRecordManifest_CarriesForwardUntouchedFiles(t *testing.T) {
+	app := NewTestApp()
+	gen, err := app.Store.Load("file")
+	require.NoError(t, err)
+
+	testutil.InTempDir(t, func(tmpDir string) {
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"kept.txt": "local edit",
+		})
+		state := NewState(vfs.NewOS(), tmpDir)
+		require.NoError(t, state.WriteManifest(&Manifest{
+			Generator: ManifestGenerator{Name: "file"},
+			Files: []ManifestFile{
+				{Path: "kept.txt", Checksum: "aaa"},
+				{Path: "removed.txt", Checksum: "bbb"},
+			},
+		}))
+
+		ctx := NewTaskContext(app)
+		values := map[string]any{
+			"DstPath":     tmpDir,
+			"SrcPath":     gen.SrcPath(),
+			"FileName":    "new.txt",
+			"FileContent": "New",
+		}
+		require.NoError(t, gen.Tasks.Execute(ctx, values))
+
+		m, err := RecordManifest(ctx, gen, values)
+		require.NoError(t, err)
+		assert.Equal(t, []ManifestFile{
+			{Path: "kept.txt", Checksum: "aaa"},
+			{Path: "new.txt", Checksum: contentChecksum([]byte("New"))},
+		}, m.Files)
+	})
+}
+
+func TestRecordManifest_DoesNothingOnDryRun(t *testing.T) {
+	app := NewTestApp()
+	app.Config.DryRun = true
+	gen, err := app.Store.Load("file")
+	require.NoError(t, err)
+
+	testutil.InTempDir(t, func(tmpDir string) {
+		ctx := NewTaskContext(app)
+		m, err := RecordManifest(ctx, gen, map[string]any{"DstPath": tmpDir})
+		assert.NoError(t, err)
+		assert.Nil(t, m)
+		testutil.AssertPaths(t, tmpDir, map[string]any{
+			".stamp/": false,
+		})
+	})
+}
+
+func TestState_ReadManifest_NotFound(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		state := NewState(vfs.NewOS(), tmpDir)
+		assert.Equal(t, filepath.Join(tmpDir, ".stamp", "manifests", "foo", "bar.yaml"), state.ManifestPath("foo:bar"))
+
+		_, err := state.ReadManifest("foo:bar")
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		all, err := state.Manifests()
+		assert.NoError(t, err)
+		assert.Empty(t, all)
+	})
+}
+
+func TestManifest_Changes(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"unchanged.txt": "unchanged",
+			"local.txt":     "local edit",
+			"upstream.txt":  "upstream",
+			"both.txt":      "both local",
+		})
+		m := &Manifest{
+			Files: []ManifestFile{
+				{Path: "unchanged.txt", Checksum: contentChecksum([]byte("unchanged"))},
+				{Path: "local.txt", Checksum: contentChecksum([]byte("local"))},
+				{Path: "upstream.txt", Checksum: contentChecksum([]byte("upstream"))},
+				{Path: "both.txt", Checksum: contentChecksum([]byte("both"))},
+			},
+		}
+
+		// Simulate an upgrade run.
+		j := NewJournal(vfs.NewOS())
+		for _, rel := range []string{"unchanged.txt", "upstream.txt", "both.txt", "added.txt", ".stamp/x.yaml"} {
+			require.NoError(t, j.Record(filepath.Join(tmpDir, rel)))
+		}
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"unchanged.txt": "unchanged",
+			"upstream.txt":  "upstream v2",
+			"both.txt":      "both v2",
+			"added.txt":     "added",
+			".stamp/x.yaml": "x",
+		})
+
+		upstream, local, err := m.Changes(tmpDir, j)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"added.txt", "both.txt", "upstream.txt"}, upstream)
+		assert.Equal(t, []string{"both.txt", "local.txt"}, local)
+	})
+}
+
+func TestManifest_Changes_SymlinkedRoot(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"real/local.txt":    "local edit",
+			"real/upstream.txt": "upstream",
+		})
+		require.NoError(t, os.Symlink(filepath.Join(tmpDir, "real"), filepath.Join(tmpDir, "link")))
+		realDir, err := filepath.EvalSymlinks(filepath.Join(tmpDir, "real"))
+		require.NoError(t, err)
+
+		m := &Manifest{
+			Files: []ManifestFile{
+				{Path: "local.txt", Checksum: contentChecksum([]byte("local"))},
+				{Path: "upstream.txt", Checksum: contentChecksum([]byte("upstream"))},
+			},
+		}
+
+		// The journal records resolved paths (as destinations do).
+		j := NewJournal(vfs.NewOS())
+		require.NoError(t, j.Record(filepath.Join(realDir, "upstream.txt")))
+		testutil.WritePaths(t, realDir, map[string]any{
+			"upstream.txt": "upstream v2",
+		})
+
+		upstream, local, err := m.Changes(filepath.Join(tmpDir, "link"), j)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"upstream.txt"}, upstream)
+		assert.Equal(t, []string{"local.txt"}, local)
+	})
+}
+
+func TestNewManifest_OnlyIncludesWrittenFiles(t *testing.T) {
+	app := NewTestApp()
+	gen, err := app.Store.Load("file")
+	require.NoError(t, err)
+
+	testutil.InTempDir(t, func(tmpDir string) {
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"src/existing.go": "package src",
+			"src/old/foo.txt": "foo",
+		})
+
+		j := NewJournal(vfs.NewOS())
+		require.NoError(t, j.Record(filepath.Join(tmpDir, "src")))
+		require.NoError(t, j.RecordTree(filepath.Join(tmpDir, "src", "old")))
+		require.NoError(t, j.Record(filepath.Join(tmpDir, "src", "new.go")))
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"src/new.go": "package src",
+		})
+
+		m, err := NewManifest(gen, map[string]any{}, tmpDir, j)
+		require.NoError(t, err)
+		assert.Equal(t, []ManifestFile{
+			{Path: "src/new.go", Checksum: contentChecksum([]byte("package src"))},
+		}, m.Files, "untouched files in recorded dirs should be excluded")
+	})
+}
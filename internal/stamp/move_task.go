@@ -0,0 +1,245 @@
+package stamp
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/swaggest/jsonschema-go"
+
+	"github.com/twelvelabs/stamp/internal/mdutil"
+	"github.com/twelvelabs/stamp/internal/vfs"
+)
+
+type MoveTask struct {
+	Common `mapstructure:",squash"`
+
+	Dst  Destination `mapstructure:"dst"  title:"Destination" required:"true"`
+	Src  Destination `mapstructure:"src"  title:"Source" required:"true"`
+	Type string      `mapstructure:"type" title:"Type" required:"true" description:"Moves (or renames) a path in the destination directory." const:"move" default:"move"` //nolint: lll
+}
+
+// PrepareJSONSchema implements the jsonschema.Preparer interface.
+func (t *MoveTask) PrepareJSONSchema(schema *jsonschema.Schema) error {
+	schema.WithTitle("MoveTask")
+	schema.WithDescription(mdutil.ToMarkdown(`
+		Moves (or renames) a path in the destination directory.
+
+		Both [src](#src) and [dst](#dst) are paths in the destination directory,
+		and may be files or directories. Missing parent directories of the
+		destination path are created as needed. Paths are moved as-is:
+		file content is never parsed, so [content_type](destination.md#content_type)
+		and [mode](destination.md#mode) are ignored.
+
+		The [missing](destination.md#missing) attribute of src is used
+		if the source path does not exist (__CODE_SPAN__touch__CODE_SPAN__ behaves like __CODE_SPAN__ignore__CODE_SPAN__),
+		and the [conflict](destination.md#conflict) attribute of dst is used
+		if the destination path already exists (__CODE_SPAN__merge__CODE_SPAN__ is not supported).
+
+		Example:
+
+		__CODE_BLOCK__yaml
+		tasks:
+			- type: move
+				# Move <./main.go> into a per-command directory.
+				# If main.go has already been moved, do nothing.
+				src:
+					path: "main.go"
+				dst:
+					path: "cmd/{{ .Name }}/main.go"
+					conflict: "keep"
+		__CODE_BLOCK__
+	`))
+
+	schema.Properties["src"].TypeObject.
+		WithExamples(
+			map[string]any{
+				"path": "main.go",
+			},
+			map[string]any{
+				"path":    "old_dir/",
+				"missing": "error",
+			},
+		)
+
+	schema.Properties["dst"].TypeObject.
+		WithExamples(
+			map[string]any{
+				"path": "cmd/{{ .Name }}/main.go",
+			},
+			map[string]any{
+				"path":     "new_dir/",
+				"conflict": "replace",
+			},
+		)
+
+	return nil
+}
+
+func (t *MoveTask) TypeKey() string {
+	return t.Type
+}
+
+func (t *MoveTask) dstPaths(values map[string]any) []string {
+	src := t.Src.lockPath(values)
+	dst := t.Dst.lockPath(values)
+	if src == "" || dst == "" {
+		return nil
+	}
+	return []string{src, dst}
+}
+
+func (t *MoveTask) Execute(ctx *TaskContext, values map[string]any) error {
+	// Paths are moved as-is (file content is never decoded).
+	if err := t.Src.SetPathValues(ctx.FS, values); err != nil {
+		ctx.Logger.Failure("fail", t.Src.RelativePath())
+		return err
+	}
+	if err := t.Dst.SetPathValues(ctx.FS, values); err != nil {
+		ctx.Logger.Failure("fail", t.Dst.RelativePath())
+		return err
+	}
+
+	if !t.Src.Exists() {
+		if t.Src.Missing == MissingConfigError {
+			ctx.Logger.Failure("fail", t.Src.RelativePath())
+			return ErrPathNotFound
+		}
+		return nil
+	}
+	if t.Src.Path() == t.Dst.Path() {
+		return nil
+	}
+	if isAncestorPath(t.Src.Path(), t.Dst.Path()) {
+		ctx.Logger.Failure("fail", t.Src.RelativePath())
+		return fmt.Errorf("move: can not move %s into itself", t.Src.RelativePath())
+	}
+
+	return t.dispatch(ctx)
+}
+
+// dispatch looks for conflicts and delegates to the correct move method.
+func (t *MoveTask) dispatch(ctx *TaskContext) error {
+	if !t.Dst.Exists() {
+		return t.move(ctx, "move")
+	}
+	switch t.Dst.Conflict {
+	case ConflictConfigKeep:
+		return t.keep(ctx)
+	case ConflictConfigReplace:
+		return t.move(ctx, "replace")
+	case ConflictConfigMerge:
+		ctx.Logger.Failure("fail", t.Src.RelativePath())
+		return errors.New("move: merge conflicts are not supported")
+	default: // ConflictConfigPrompt
+		// See CreateTask.dispatch.
+		ctx.waitTurn()
+		switch ctx.ConflictOverride {
+		case ConflictConfigKeep:
+			return t.keep(ctx)
+		case ConflictConfigReplace:
+			return t.move(ctx, "replace")
+		default:
+			return t.prompt(ctx)
+		}
+	}
+}
+
+// move moves src to dst (replacing dst if it exists).
+func (t *MoveTask) move(ctx *TaskContext, action string) error {
+	if err := t.moveDst(ctx); err != nil {
+		ctx.Logger.Failure("fail", t.Src.RelativePath())
+		return err
+	}
+	ctx.Logger.Success(action, "%s -> %s", t.Src.RelativePath(), t.Dst.RelativePath())
+	return nil
+}
+
+// keep is called when keeping an existing dst path (src is left as-is).
+func (t *MoveTask) keep(ctx *TaskContext) error {
+	ctx.Logger.Success("keep", t.Dst.RelativePath())
+	return nil
+}
+
+// prompt is called to prompt the user for how to resolve a dst path conflict.
+// delegates to keep or move depending on their response.
+func (t *MoveTask) prompt(ctx *TaskContext) error {
+	ctx.Logger.Warning("conflict", "%s already exists", t.Dst.RelativePath())
+	if ctx.NoInput {
+		ctx.Logger.Failure("fail", t.Src.RelativePath())
+		return fmt.Errorf("%w: unable to resolve conflict for %s", ErrNoInput, t.Dst.RelativePath())
+	}
+	for {
+		choice, err := ctx.UI.Select("Resolve conflict", ConflictChoices(), ConflictChoiceSkip)
+		if err != nil {
+			return err
+		}
+		switch choice {
+		case ConflictChoiceOverwrite:
+			return t.move(ctx, "replace")
+		case ConflictChoiceDiff:
+			diff, err := t.diff(ctx)
+			if err != nil {
+				return err
+			}
+			ctx.Logger.Diff(diff)
+			continue // re-prompt
+		case ConflictChoiceOverwriteAll:
+			ctx.ConflictOverride = ConflictConfigReplace
+			return t.move(ctx, "replace")
+		case ConflictChoiceSkipAll:
+			ctx.ConflictOverride = ConflictConfigKeep
+			return t.keep(ctx)
+		case ConflictChoiceAbort:
+			ctx.Logger.Failure("abort", t.Src.RelativePath())
+			return ErrAborted
+		default: // ConflictChoiceSkip
+			return t.keep(ctx)
+		}
+	}
+}
+
+// diff returns a unified diff between the dst file and the src file
+// it would be replaced with (or an empty string for dirs).
+func (t *MoveTask) diff(ctx *TaskContext) (string, error) {
+	if t.Src.IsDir() || t.Dst.IsDir() {
+		return "", nil
+	}
+	buf, err := ctx.FS.ReadFile(t.Src.Path())
+	if err != nil {
+		return "", fmt.Errorf("src path read: %w", err)
+	}
+	return t.Dst.diff(buf, false)
+}
+
+// moveDst moves src to dst, removing any existing dst path first.
+// During a dry run, both paths are left untouched.
+func (t *MoveTask) moveDst(ctx *TaskContext) error {
+	if ctx.DryRun {
+		return nil
+	}
+	if err := ctx.Journal.RecordTree(t.Src.Path()); err != nil {
+		return err
+	}
+	// Any existing dst path is removed, so it needs to be restored on rollback.
+	if err := ctx.Journal.RecordTree(t.Dst.Path()); err != nil {
+		return err
+	}
+	// Also record every path that will be moved into dst
+	// so that they are removed on rollback.
+	err := vfs.WalkDir(ctx.FS, t.Src.Path(), func(path string, _ fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return ctx.Journal.Record(filepath.Join(t.Dst.Path(), strings.TrimPrefix(path, t.Src.Path())))
+	})
+	if err != nil {
+		return err
+	}
+	if err := t.Dst.Delete(); err != nil {
+		return err
+	}
+	return vfs.Move(ctx.FS, t.Src.Path(), t.Dst.Path())
+}
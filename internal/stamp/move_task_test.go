@@ -0,0 +1,460 @@
+package stamp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twelvelabs/termite/testutil"
+	"github.com/twelvelabs/termite/ui"
+)
+
+func TestNewTask_WhenTypeIsMove(t *testing.T) {
+	tests := []struct {
+		Name     string
+		TaskData map[string]any
+		Err      string
+	}{
+		{
+			Name: "returns an error when conflict field is invalid",
+			TaskData: map[string]any{
+				"type": "move",
+				"src": map[string]any{
+					"path": "a.txt",
+				},
+				"dst": map[string]any{
+					"path":     "b.txt",
+					"conflict": "unknown",
+				},
+			},
+			Err: "unknown is not a valid ConflictConfig",
+		},
+		{
+			Name: "returns the task when all fields are valid",
+			TaskData: map[string]any{
+				"type": "move",
+				"src": map[string]any{
+					"path":    "a.txt",
+					"missing": "error",
+				},
+				"dst": map[string]any{
+					"path":     "b.txt",
+					"conflict": "replace",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			actual, err := NewTask(test.TaskData)
+
+			if test.Err == "" {
+				assert.NoError(t, err)
+				assert.NotNil(t, actual)
+			} else {
+				assert.ErrorContains(t, err, test.Err)
+				assert.Nil(t, actual)
+			}
+		})
+	}
+}
+
+func TestMoveTask_Execute(t *testing.T) {
+	tests := []struct {
+		Desc       string
+		DryRun     bool
+		NoInput    bool
+		TaskData   map[string]any
+		Values     map[string]any
+		StartFiles map[string]any
+		EndFiles   map[string]any
+		Setup      func(app *App)
+		Err        string
+	}{
+		{
+			Desc: "returns an error if src evaluates to empty string",
+			TaskData: map[string]any{
+				"type": "move",
+				"src":  map[string]any{"path": "{{ .Empty }}"},
+				"dst":  map[string]any{"path": "b.txt"},
+			},
+			Values: map[string]any{
+				"DstPath": ".",
+				"Empty":   "",
+			},
+			Err: "evaluated to an empty string",
+		},
+		{
+			Desc: "returns an error if dst is outside the destination dir",
+			StartFiles: map[string]any{
+				"a.txt": "a",
+			},
+			TaskData: map[string]any{
+				"type": "move",
+				"src":  map[string]any{"path": "a.txt"},
+				"dst":  map[string]any{"path": "../b.txt"},
+			},
+			Values: map[string]any{
+				"DstPath": ".",
+			},
+			EndFiles: map[string]any{
+				"a.txt": "a",
+			},
+			Err: "dst path validate",
+		},
+		{
+			Desc: "moves a file",
+			StartFiles: map[string]any{
+				"main.go": "package main",
+			},
+			TaskData: map[string]any{
+				"type": "move",
+				"src":  map[string]any{"path": "main.go"},
+				"dst":  map[string]any{"path": "cmd/{{ .Name }}/main.go"},
+			},
+			Values: map[string]any{
+				"DstPath": ".",
+				"Name":    "foo",
+			},
+			EndFiles: map[string]any{
+				"main.go":         false,
+				"cmd/foo/main.go": "package main",
+			},
+		},
+		{
+			Desc: "moves a dir",
+			StartFiles: map[string]any{
+				"old/a.txt":     "a",
+				"old/sub/b.txt": "b",
+			},
+			TaskData: map[string]any{
+				"type": "move",
+				"src":  map[string]any{"path": "old"},
+				"dst":  map[string]any{"path": "new"},
+			},
+			Values: map[string]any{
+				"DstPath": ".",
+			},
+			EndFiles: map[string]any{
+				"old":           false,
+				"new/a.txt":     "a",
+				"new/sub/b.txt": "b",
+			},
+		},
+		{
+			Desc: "returns an error when moving a dir into itself",
+			StartFiles: map[string]any{
+				"old/a.txt": "a",
+			},
+			TaskData: map[string]any{
+				"type": "move",
+				"src":  map[string]any{"path": "old"},
+				"dst":  map[string]any{"path": "old/new"},
+			},
+			Values: map[string]any{
+				"DstPath": ".",
+			},
+			EndFiles: map[string]any{
+				"old/a.txt": "a",
+			},
+			Err: "can not move old into itself",
+		},
+		{
+			Desc:   "does not move paths during a dry run",
+			DryRun: true,
+			StartFiles: map[string]any{
+				"a.txt": "a",
+			},
+			TaskData: map[string]any{
+				"type": "move",
+				"src":  map[string]any{"path": "a.txt"},
+				"dst":  map[string]any{"path": "b.txt"},
+			},
+			Values: map[string]any{
+				"DstPath": ".",
+			},
+			EndFiles: map[string]any{
+				"a.txt": "a",
+				"b.txt": false,
+			},
+		},
+
+		{
+			Desc: "[missing:ignore] ignores missing src paths",
+			TaskData: map[string]any{
+				"type": "move",
+				"src":  map[string]any{"path": "a.txt"},
+				"dst":  map[string]any{"path": "b.txt"},
+			},
+			Values: map[string]any{
+				"DstPath": ".",
+			},
+			EndFiles: map[string]any{
+				"a.txt": false,
+				"b.txt": false,
+			},
+		},
+		{
+			Desc: "[missing:error] returns an error when src path is missing",
+			TaskData: map[string]any{
+				"type": "move",
+				"src":  map[string]any{"path": "a.txt", "missing": "error"},
+				"dst":  map[string]any{"path": "b.txt"},
+			},
+			Values: map[string]any{
+				"DstPath": ".",
+			},
+			Err: "path not found",
+		},
+
+		{
+			Desc: "[conflict:keep] keeps existing dst paths",
+			StartFiles: map[string]any{
+				"a.txt": "a",
+				"b.txt": "b",
+			},
+			TaskData: map[string]any{
+				"type": "move",
+				"src":  map[string]any{"path": "a.txt"},
+				"dst":  map[string]any{"path": "b.txt", "conflict": "keep"},
+			},
+			Values: map[string]any{
+				"DstPath": ".",
+			},
+			EndFiles: map[string]any{
+				"a.txt": "a",
+				"b.txt": "b",
+			},
+		},
+		{
+			Desc: "[conflict:replace] replaces existing dst paths",
+			StartFiles: map[string]any{
+				"old/a.txt": "a",
+				"new/b.txt": "b",
+			},
+			TaskData: map[string]any{
+				"type": "move",
+				"src":  map[string]any{"path": "old"},
+				"dst":  map[string]any{"path": "new", "conflict": "replace"},
+			},
+			Values: map[string]any{
+				"DstPath": ".",
+			},
+			EndFiles: map[string]any{
+				"old":       false,
+				"new/a.txt": "a",
+				"new/b.txt": false,
+			},
+		},
+		{
+			Desc: "[conflict:merge] returns an error",
+			StartFiles: map[string]any{
+				"a.txt": "a",
+				"b.txt": "b",
+			},
+			TaskData: map[string]any{
+				"type": "move",
+				"src":  map[string]any{"path": "a.txt"},
+				"dst":  map[string]any{"path": "b.txt", "conflict": "merge"},
+			},
+			Values: map[string]any{
+				"DstPath": ".",
+			},
+			EndFiles: map[string]any{
+				"a.txt": "a",
+				"b.txt": "b",
+			},
+			Err: "merge conflicts are not supported",
+		},
+		{
+			Desc: "[conflict:prompt] replaces existing dst paths when confirmed",
+			StartFiles: map[string]any{
+				"a.txt": "a",
+				"b.txt": "b",
+			},
+			TaskData: map[string]any{
+				"type": "move",
+				"src":  map[string]any{"path": "a.txt"},
+				"dst":  map[string]any{"path": "b.txt"},
+			},
+			Values: map[string]any{
+				"DstPath": ".",
+			},
+			Setup: func(app *App) {
+				app.UI.RegisterStub(
+					ui.MatchSelect("Resolve conflict"),
+					ui.RespondString(ConflictChoiceOverwrite),
+				)
+			},
+			EndFiles: map[string]any{
+				"a.txt": false,
+				"b.txt": "a",
+			},
+		},
+		{
+			Desc: "[conflict:prompt] keeps existing dst paths when skipped",
+			StartFiles: map[string]any{
+				"a.txt": "a",
+				"b.txt": "b",
+			},
+			TaskData: map[string]any{
+				"type": "move",
+				"src":  map[string]any{"path": "a.txt"},
+				"dst":  map[string]any{"path": "b.txt"},
+			},
+			Values: map[string]any{
+				"DstPath": ".",
+			},
+			Setup: func(app *App) {
+				app.UI.RegisterStub(
+					ui.MatchSelect("Resolve conflict"),
+					ui.RespondString(ConflictChoiceSkip),
+				)
+			},
+			EndFiles: map[string]any{
+				"a.txt": "a",
+				"b.txt": "b",
+			},
+		},
+		{
+			Desc:    "[conflict:prompt] returns an error when input is disabled",
+			NoInput: true,
+			StartFiles: map[string]any{
+				"a.txt": "a",
+				"b.txt": "b",
+			},
+			TaskData: map[string]any{
+				"type": "move",
+				"src":  map[string]any{"path": "a.txt"},
+				"dst":  map[string]any{"path": "b.txt"},
+			},
+			Values: map[string]any{
+				"DstPath": ".",
+			},
+			EndFiles: map[string]any{
+				"a.txt": "a",
+				"b.txt": "b",
+			},
+			Err: "input required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Desc, func(t *testing.T) {
+			testutil.InTempDir(t, func(tmpDir string) {
+				// Populate the temp dir w/ any initial files
+				testutil.WritePaths(t, tmpDir, tt.StartFiles)
+
+				// Setup the app.
+				app := NewTestApp()
+				app.Config.DryRun = tt.DryRun
+				app.Config.NoInput = tt.NoInput
+				if tt.Setup != nil {
+					tt.Setup(app)
+				}
+				defer app.UI.VerifyStubs(t)
+
+				// Create a new task and execute it.
+				task, err := NewTask(tt.TaskData)
+				require.NoError(t, err)
+				ctx := NewTaskContext(app)
+				err = task.Execute(ctx, tt.Values)
+
+				// Ensure the expected files were generated
+				testutil.AssertPaths(t, tmpDir, tt.EndFiles)
+
+				if tt.Err == "" {
+					assert.NoError(t, err)
+				} else {
+					assert.ErrorContains(t, err, tt.Err)
+				}
+			})
+		})
+	}
+}
+
+func TestMoveTask_ExecuteCanBeRolledBack(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"old/a.txt": "a",
+			"new/b.txt": "b",
+		})
+
+		app := NewTestApp()
+		task, err := NewTask(map[string]any{
+			"type": "move",
+			"src":  map[string]any{"path": "old"},
+			"dst":  map[string]any{"path": "new", "conflict": "replace"},
+		})
+		require.NoError(t, err)
+
+		ctx := NewTaskContext(app)
+		require.NoError(t, task.Execute(ctx, map[string]any{"DstPath": tmpDir}))
+		assert.Equal(t, "✓ [   replace]: old -> new\n", app.IO.Out.String())
+
+		assert.NoError(t, ctx.Rollback())
+		testutil.AssertPaths(t, tmpDir, map[string]any{
+			"old/a.txt": "a",
+			"new/a.txt": false,
+			"new/b.txt": "b",
+		})
+	})
+}
+
+func TestMoveTask_ExecuteDoesNotDecodeContent(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"old.json": "{not json",
+			"new.toml": "= not toml",
+		})
+
+		app := NewTestApp()
+		task, err := NewTask(map[string]any{
+			"type": "move",
+			"src":  map[string]any{"path": "old.json"},
+			"dst":  map[string]any{"path": "new.toml", "conflict": "replace"},
+		})
+		require.NoError(t, err)
+
+		ctx := NewTaskContext(app)
+		require.NoError(t, task.Execute(ctx, map[string]any{"DstPath": tmpDir}))
+		testutil.AssertPaths(t, tmpDir, map[string]any{
+			"old.json": false,
+			"new.toml": "{not json",
+		})
+	})
+}
+
+func TestMoveTask_ExecuteRestoresReplacedDirsOnRollback(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"old/a.txt":         "a",
+			"new/a.txt":         "replaced",
+			"new/nested/b.txt":  "b",
+			"new/nested/c/d.md": "d",
+		})
+
+		app := NewTestApp()
+		task, err := NewTask(map[string]any{
+			"type": "move",
+			"src":  map[string]any{"path": "old"},
+			"dst":  map[string]any{"path": "new", "conflict": "replace"},
+		})
+		require.NoError(t, err)
+
+		ctx := NewTaskContext(app)
+		require.NoError(t, task.Execute(ctx, map[string]any{"DstPath": tmpDir}))
+		testutil.AssertPaths(t, tmpDir, map[string]any{
+			"new/a.txt":  "a",
+			"new/nested": false,
+		})
+
+		assert.NoError(t, ctx.Rollback())
+		testutil.AssertPaths(t, tmpDir, map[string]any{
+			"old/a.txt":         "a",
+			"new/a.txt":         "replaced",
+			"new/nested/b.txt":  "b",
+			"new/nested/c/d.md": "d",
+		})
+	})
+}
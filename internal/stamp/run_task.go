@@ -0,0 +1,216 @@
+package stamp
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cast"
+	"github.com/swaggest/jsonschema-go"
+	"github.com/twelvelabs/termite/render"
+
+	"github.com/twelvelabs/stamp/internal/fsutil"
+	"github.com/twelvelabs/stamp/internal/mdutil"
+	"github.com/twelvelabs/stamp/internal/vfs"
+)
+
+type RunTask struct {
+	Common `mapstructure:",squash"`
+
+	CommandTpl render.Template            `mapstructure:"command" title:"Command" required:"true" examples:"[\"go\", \"npm\", \"git\"]" description:"The command to execute. Must be an executable name or path (it is not run in a shell)."` //nolint: lll
+	ArgsTpl    []render.Template          `mapstructure:"args"    title:"Args" description:"Optional arguments to pass to the command."`                                                                                                      //nolint: lll
+	EnvTpl     map[string]render.Template `mapstructure:"env"     title:"Env" description:"Optional environment variables to set (in addition to those of the current process)."`                                                             //nolint: lll
+	DirTpl     render.Template            `mapstructure:"dir"     title:"Dir" description:"The working directory. Relative paths are resolved from the destination dir. Defaults to the destination dir."`                                    //nolint: lll
+	Type       string                     `mapstructure:"type"    title:"Type" required:"true" description:"Runs a command in the destination directory." const:"run" default:"run"`                                                          //nolint: lll
+
+	// Set for tasks belonging to generators installed from a remote origin.
+	// Those commands are only run with the user's permission.
+	remote bool
+}
+
+// PrepareJSONSchema implements the jsonschema.Preparer interface.
+func (t *RunTask) PrepareJSONSchema(schema *jsonschema.Schema) error {
+	schema.WithTitle("RunTask")
+	schema.WithDescription(mdutil.ToMarkdown(`
+		Runs a command in the destination directory.
+
+		Output from the command is streamed to the log as it runs.
+		Commands are not run during a dry run, and the changes they make
+		can not be rolled back if a later task fails.
+
+		If the generator (or one that runs it as a sub-generator)
+		was installed from a remote origin
+		(i.e. a git repo or URL), the user will be asked to confirm
+		each command before it is run.
+
+		Examples:
+
+		__CODE_BLOCK__yaml
+		tasks:
+			# ... tasks that generate a Go module ...
+
+			- type: run
+				# Runs <go mod tidy> in the destination dir.
+				command: "go"
+				args: ["mod", "tidy"]
+		__CODE_BLOCK__
+
+		__CODE_BLOCK__yaml
+		tasks:
+			- type: run
+				# Installs dependencies for each of the generated packages.
+				command: "npm"
+				args: ["install"]
+				dir: "packages/{{ ._Item }}"
+				env:
+					NODE_ENV: "development"
+				each: "api, web"
+		__CODE_BLOCK__
+	`))
+	return nil
+}
+
+func (t *RunTask) TypeKey() string {
+	return t.Type
+}
+
+func (t *RunTask) Execute(ctx *TaskContext, values map[string]any) error {
+	cmd, err := t.command(values)
+	if err != nil {
+		return err
+	}
+	dir := fsutil.TryRelative(cmd.Dir)
+	line := strings.Join(cmd.Args, " ")
+
+	// Output is streamed, so wait for earlier tasks to finish logging
+	// (and for any earlier prompts to be answered).
+	ctx.waitTurn()
+
+	if ctx.DryRun {
+		ctx.Logger.Info("run", "%s $ %s", dir, line)
+		return nil
+	}
+	if !vfs.IsOS(ctx.FS) {
+		// Commands would not see any files generated so far.
+		ctx.Logger.Warning("skip", "%s $ %s", dir, line)
+		return nil
+	}
+	if t.remote {
+		ok, err := t.confirm(ctx, dir, line)
+		if err != nil || !ok {
+			return err
+		}
+	}
+
+	ctx.Logger.Info("run", "%s $ %s", dir, line)
+	out := &logWriter{logger: ctx.Logger, path: dir}
+	cmd.Stdout = out
+	cmd.Stderr = out
+	err = cmd.Run()
+	out.Flush()
+	if err != nil {
+		ctx.Logger.Failure("fail", "%s $ %s", dir, line)
+		return fmt.Errorf("run %s: %w", line, err)
+	}
+	return nil
+}
+
+// command returns the rendered command (without running it).
+func (t *RunTask) command(values map[string]any) (*exec.Cmd, error) {
+	name, err := t.CommandTpl.RenderRequired(values)
+	if err != nil {
+		return nil, fmt.Errorf("command: %w", err)
+	}
+	args := []string{}
+	for _, tpl := range t.ArgsTpl {
+		arg, err := tpl.Render(values)
+		if err != nil {
+			return nil, fmt.Errorf("args: %w", err)
+		}
+		args = append(args, arg)
+	}
+
+	root := cast.ToString(values["DstPath"])
+	dir, err := t.DirTpl.Render(values)
+	if err != nil {
+		return nil, fmt.Errorf("dir: %w", err)
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(root, dir)
+	}
+
+	keys := make([]string, 0, len(t.EnvTpl))
+	for k := range t.EnvTpl {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	env := os.Environ()
+	for _, k := range keys {
+		tpl := t.EnvTpl[k]
+		v, err := tpl.Render(values)
+		if err != nil {
+			return nil, fmt.Errorf("env: %w", err)
+		}
+		env = append(env, k+"="+v)
+	}
+
+	cmd := exec.Command(name, args...) //nolint:gosec
+	cmd.Dir = dir
+	cmd.Env = env
+	return cmd, nil
+}
+
+// confirm asks the user for permission to run a command.
+// Returns false if the user declined.
+func (t *RunTask) confirm(ctx *TaskContext, dir string, line string) (bool, error) {
+	ctx.Logger.Warning("remote", "%s $ %s", dir, line)
+	if ctx.NoInput {
+		ctx.Logger.Failure("fail", "%s $ %s", dir, line)
+		return false, fmt.Errorf("%w: unable to confirm command from a remote generator: %s", ErrNoInput, line)
+	}
+	ok, err := ctx.UI.Confirm("Run this command from a remote generator", false)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		ctx.Logger.Warning("skip", "%s $ %s", dir, line)
+	}
+	return ok, nil
+}
+
+// logWriter logs each line written to it as an "output" action.
+type logWriter struct {
+	logger Logger
+	path   string
+	buf    []byte
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.log(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush logs any remaining partial line.
+func (w *logWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.log(string(w.buf))
+		w.buf = nil
+	}
+}
+
+func (w *logWriter) log(line string) {
+	// The path is passed (but not shown) per the Logger convention.
+	w.logger.Info("output", "%[2]s", w.path, strings.TrimSuffix(line, "\r"))
+}
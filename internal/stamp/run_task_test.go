@@ -0,0 +1,278 @@
+package stamp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twelvelabs/termite/testutil"
+	"github.com/twelvelabs/termite/ui"
+
+	"github.com/twelvelabs/stamp/internal/vfs"
+)
+
+func TestNewTask_WhenTypeIsRun(t *testing.T) {
+	tests := []struct {
+		Name     string
+		TaskData map[string]any
+		Err      string
+	}{
+		{
+			Name: "returns an error when args are invalid templates",
+			TaskData: map[string]any{
+				"type":    "run",
+				"command": "echo",
+				"args":    []any{"{{ .Foo"},
+			},
+			Err: "unclosed action",
+		},
+		{
+			Name: "returns the task when all fields are valid",
+			TaskData: map[string]any{
+				"type":    "run",
+				"command": "echo",
+				"args":    []any{"{{ .Foo }}"},
+				"env":     map[string]any{"FOO": "{{ .Foo }}"},
+				"dir":     "sub",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			actual, err := NewTask(test.TaskData)
+
+			if test.Err == "" {
+				assert.NoError(t, err)
+				assert.NotNil(t, actual)
+			} else {
+				assert.ErrorContains(t, err, test.Err)
+				assert.Nil(t, actual)
+			}
+		})
+	}
+}
+
+func TestRunTask_Execute(t *testing.T) {
+	tests := []struct {
+		Desc       string
+		DryRun     bool
+		NoInput    bool
+		Remote     bool
+		MemoryFS   bool
+		TaskData   map[string]any
+		Values     map[string]any
+		StartFiles map[string]any
+		EndFiles   map[string]any
+		Setup      func(app *App)
+		Err        string
+	}{
+		{
+			Desc: "returns an error if command evaluates to empty string",
+			TaskData: map[string]any{
+				"type":    "run",
+				"command": "{{ .Empty }}",
+			},
+			Values: map[string]any{
+				"Empty": "",
+			},
+			Err: "evaluated to an empty string",
+		},
+		{
+			Desc: "runs the command in the destination dir",
+			TaskData: map[string]any{
+				"type":    "run",
+				"command": "sh",
+				"args":    []any{"-c", "echo $GREETING, {{ .Name }} > out.txt"},
+				"env":     map[string]any{"GREETING": "{{ .Greeting }}"},
+			},
+			Values: map[string]any{
+				"DstPath":  ".",
+				"Greeting": "hello",
+				"Name":     "world",
+			},
+			EndFiles: map[string]any{
+				"out.txt": "hello, world\n",
+			},
+		},
+		{
+			Desc: "runs the command in dir relative to the destination dir",
+			StartFiles: map[string]any{
+				"sub/.keep": "",
+			},
+			TaskData: map[string]any{
+				"type":    "run",
+				"command": "touch",
+				"args":    []any{"out.txt"},
+				"dir":     "{{ .Dir }}",
+			},
+			Values: map[string]any{
+				"DstPath": ".",
+				"Dir":     "sub",
+			},
+			EndFiles: map[string]any{
+				"out.txt":     false,
+				"sub/out.txt": "",
+			},
+		},
+		{
+			Desc: "returns an error when the command fails",
+			TaskData: map[string]any{
+				"type":    "run",
+				"command": "sh",
+				"args":    []any{"-c", "exit 3"},
+			},
+			Values: map[string]any{
+				"DstPath": ".",
+			},
+			Err: "run sh -c exit 3: exit status 3",
+		},
+		{
+			Desc:   "does not run commands during a dry run",
+			DryRun: true,
+			TaskData: map[string]any{
+				"type":    "run",
+				"command": "touch",
+				"args":    []any{"out.txt"},
+			},
+			Values: map[string]any{
+				"DstPath": ".",
+			},
+			EndFiles: map[string]any{
+				"out.txt": false,
+			},
+		},
+		{
+			Desc:     "does not run commands when not using the OS filesystem",
+			MemoryFS: true,
+			TaskData: map[string]any{
+				"type":    "run",
+				"command": "touch",
+				"args":    []any{"out.txt"},
+			},
+			Values: map[string]any{
+				"DstPath": ".",
+			},
+			EndFiles: map[string]any{
+				"out.txt": false,
+			},
+		},
+		{
+			Desc:   "runs commands from remote generators when confirmed",
+			Remote: true,
+			TaskData: map[string]any{
+				"type":    "run",
+				"command": "touch",
+				"args":    []any{"out.txt"},
+			},
+			Values: map[string]any{
+				"DstPath": ".",
+			},
+			Setup: func(app *App) {
+				app.UI.RegisterStub(
+					ui.MatchConfirm("Run this command from a remote generator"),
+					ui.RespondBool(true),
+				)
+			},
+			EndFiles: map[string]any{
+				"out.txt": "",
+			},
+		},
+		{
+			Desc:   "skips commands from remote generators when declined",
+			Remote: true,
+			TaskData: map[string]any{
+				"type":    "run",
+				"command": "touch",
+				"args":    []any{"out.txt"},
+			},
+			Values: map[string]any{
+				"DstPath": ".",
+			},
+			Setup: func(app *App) {
+				app.UI.RegisterStub(
+					ui.MatchConfirm("Run this command from a remote generator"),
+					ui.RespondBool(false),
+				)
+			},
+			EndFiles: map[string]any{
+				"out.txt": false,
+			},
+		},
+		{
+			Desc:    "returns an error for commands from remote generators when input is disabled",
+			Remote:  true,
+			NoInput: true,
+			TaskData: map[string]any{
+				"type":    "run",
+				"command": "touch",
+				"args":    []any{"out.txt"},
+			},
+			Values: map[string]any{
+				"DstPath": ".",
+			},
+			EndFiles: map[string]any{
+				"out.txt": false,
+			},
+			Err: "input required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Desc, func(t *testing.T) {
+			testutil.InTempDir(t, func(tmpDir string) {
+				// Populate the temp dir w/ any initial files
+				testutil.WritePaths(t, tmpDir, tt.StartFiles)
+
+				// Setup the app.
+				app := NewTestApp()
+				app.Config.DryRun = tt.DryRun
+				app.Config.NoInput = tt.NoInput
+				if tt.MemoryFS {
+					app.FS = vfs.NewMemory(app.FS)
+				}
+				if tt.Setup != nil {
+					tt.Setup(app)
+				}
+				defer app.UI.VerifyStubs(t)
+
+				// Create a new task and execute it.
+				task, err := NewTask(tt.TaskData)
+				require.NoError(t, err)
+				task.(*RunTask).remote = tt.Remote
+				ctx := NewTaskContext(app)
+				err = task.Execute(ctx, tt.Values)
+
+				// Ensure the expected files were generated
+				testutil.AssertPaths(t, tmpDir, tt.EndFiles)
+
+				if tt.Err == "" {
+					assert.NoError(t, err)
+				} else {
+					assert.ErrorContains(t, err, tt.Err)
+				}
+			})
+		})
+	}
+}
+
+func TestRunTask_ExecuteLogsOutput(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		app := NewTestApp()
+		task, err := NewTask(map[string]any{
+			"type":    "run",
+			"command": "sh",
+			"args":    []any{"-c", "echo one; echo two 1>&2; printf 100%%"},
+		})
+		require.NoError(t, err)
+
+		ctx := NewTaskContext(app)
+		assert.NoError(t, task.Execute(ctx, map[string]any{"DstPath": tmpDir}))
+		assert.Equal(t, ""+
+			"• [       run]: . $ sh -c echo one; echo two 1>&2; printf 100%%\n"+
+			"• [    output]: one\n"+
+			"• [    output]: two\n"+
+			"• [    output]: 100%\n",
+			app.IO.Out.String(),
+		)
+	})
+}
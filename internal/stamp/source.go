@@ -2,13 +2,13 @@ package stamp
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/spf13/cast"
 	"github.com/swaggest/jsonschema-go"
 	"github.com/twelvelabs/termite/render"
 
 	"github.com/twelvelabs/stamp/internal/fsutil"
+	"github.com/twelvelabs/stamp/internal/vfs"
 )
 
 type Source struct {
@@ -19,6 +19,7 @@ type Source struct {
 
 	content     any
 	contentType FileType
+	fs          vfs.FS
 	path        string
 }
 
@@ -127,17 +128,19 @@ func (s *Source) Path() string {
 
 // Exists returns true if the file path exists.
 func (s *Source) Exists() bool {
-	return fsutil.PathExists(s.path)
+	return vfs.Exists(s.fs, s.path)
 }
 
 // IsDir returns true if the file path is a directory.
 func (s *Source) IsDir() bool {
-	return fsutil.PathIsDir(s.path)
+	return vfs.IsDir(s.fs, s.path)
 }
 
 // SetValues calculates source properties using the given values.
-func (s *Source) SetValues(values map[string]any) error {
+// The file (if any) is read from fsys.
+func (s *Source) SetValues(fsys vfs.FS, values map[string]any) error {
 	var err error
+	s.fs = fsys
 
 	// Render path.
 	s.path, err = s.PathTpl.Render(values)
@@ -179,7 +182,7 @@ func (s *Source) SetValues(values map[string]any) error {
 
 	if s.Exists() && !s.IsDir() {
 		// Read the path content.
-		buf, err := os.ReadFile(s.path)
+		buf, err := s.fs.ReadFile(s.path)
 		if err != nil {
 			return fmt.Errorf("src path content read: %w", err)
 		}
@@ -212,7 +215,7 @@ func (s *Source) ForPath(path string, values map[string]any) (Source, error) {
 		PathTpl:        *render.MustCompile(path),
 		Static:         s.Static,
 	}
-	if err := src.SetValues(values); err != nil {
+	if err := src.SetValues(s.fs, values); err != nil {
 		return src, fmt.Errorf("for path: %w", err)
 	}
 	return src, nil
@@ -7,6 +7,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/twelvelabs/termite/render"
 	"github.com/twelvelabs/termite/testutil"
+
+	"github.com/twelvelabs/stamp/internal/vfs"
 )
 
 func TestSource_ForPath(t *testing.T) {
@@ -18,7 +20,7 @@ func TestSource_ForPath(t *testing.T) {
 		"SrcPath": "testdata",
 	}
 
-	err := src.SetValues(values)
+	err := src.SetValues(vfs.NewOS(), values)
 	assert.NoError(t, err)
 	assert.Equal(t, "templates", filepath.Base(src.Path()))
 	assert.Equal(t, FileTypeText, src.ContentType())
@@ -233,7 +235,7 @@ func TestSource(t *testing.T) {
 					tt.setup(t, dir)
 				}
 
-				err := tt.src.SetValues(tt.values)
+				err := tt.src.SetValues(vfs.NewOS(), tt.values)
 
 				if tt.err == "" {
 					assert.NoError(t, err)
@@ -0,0 +1,67 @@
+package stamp
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/twelvelabs/stamp/internal/vfs"
+)
+
+const (
+	// StateDir is the name of the directory (relative to the destination root)
+	// where stamp stores bookkeeping data about generator runs.
+	StateDir = ".stamp"
+)
+
+// NewState returns a new State for the given destination root dir in fsys.
+func NewState(fsys vfs.FS, root string) *State {
+	return &State{
+		fs:   fsys,
+		root: root,
+	}
+}
+
+// State provides access to the generator bookkeeping data
+// stored in a destination directory (see [StateDir]).
+type State struct {
+	fs   vfs.FS
+	root string
+}
+
+// Path returns the absolute path to elem inside the state dir.
+func (s *State) Path(elem ...string) string {
+	return filepath.Join(append([]string{s.root, StateDir}, elem...)...)
+}
+
+// GeneratedPath returns the path used to store the last generated
+// version of the destination file at rel.
+func (s *State) GeneratedPath(rel string) string {
+	return s.Path("generated", rel)
+}
+
+// ReadGenerated returns the last generated version of the destination file at rel.
+// Returns nil if no version has been recorded.
+func (s *State) ReadGenerated(rel string) ([]byte, error) {
+	path := s.GeneratedPath(rel)
+	if !vfs.Exists(s.fs, path) {
+		return nil, nil
+	}
+	content, err := s.fs.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("state read: %w", err)
+	}
+	return content, nil
+}
+
+// WriteGenerated records content as the last generated version
+// of the destination file at rel.
+func (s *State) WriteGenerated(rel string, content []byte) error {
+	path := s.GeneratedPath(rel)
+	if err := s.fs.MkdirAll(filepath.Dir(path), DstDirMode); err != nil {
+		return fmt.Errorf("state write: %w", err)
+	}
+	if err := s.fs.WriteFile(path, content, DstFileMode); err != nil {
+		return fmt.Errorf("state write: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package stamp
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/twelvelabs/termite/testutil"
+
+	"github.com/twelvelabs/stamp/internal/vfs"
+)
+
+func TestState_Generated(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		state := NewState(vfs.NewOS(), tmpDir)
+		assert.Equal(t, filepath.Join(tmpDir, ".stamp", "generated", "aaa", "bbb.txt"), state.GeneratedPath("aaa/bbb.txt"))
+
+		content, err := state.ReadGenerated("aaa/bbb.txt")
+		assert.NoError(t, err)
+		assert.Nil(t, content)
+
+		err = state.WriteGenerated("aaa/bbb.txt", []byte("bbb"))
+		assert.NoError(t, err)
+
+		content, err = state.ReadGenerated("aaa/bbb.txt")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("bbb"), content)
+	})
+}
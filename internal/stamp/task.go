@@ -35,7 +35,9 @@ func AllTasks() []Task {
 		&CreateTask{},
 		&UpdateTask{},
 		&DeleteTask{},
+		&MoveTask{},
 		&GeneratorTask{},
+		&RunTask{},
 	}
 	// Ensure defaults are set.
 	// Needed for TypeKey() implementation.
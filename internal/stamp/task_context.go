@@ -1,25 +1,72 @@
 package stamp
 
 import (
+	"errors"
+
 	"github.com/twelvelabs/termite/ui"
+
+	"github.com/twelvelabs/stamp/internal/fsutil"
+	"github.com/twelvelabs/stamp/internal/vfs"
 )
 
 // TaskContext holds configuration and dependencies used in Task.Execute().
 type TaskContext struct {
-	DryRun bool
-	IO     *ui.IOStreams
-	UI     *ui.UserInterface
-	Logger *TaskLogger
-	Store  *Store
+	DryRun  bool
+	NoInput bool
+	IO      *ui.IOStreams
+	UI      *ui.UserInterface
+	Journal *Journal
+	Logger  Logger
+	Store   *Store
+
+	// FS is the filesystem that tasks read from and write to.
+	FS vfs.FS
+	// Jobs is the max number of tasks (or files) to execute concurrently.
+	// Tasks are executed sequentially unless greater than one.
+	Jobs int
+
+	// ConflictOverride is used in place of prompting when resolving conflicts.
+	// Set when the user chooses to overwrite or skip all remaining conflicts.
+	ConflictOverride ConflictConfig
+
+	workers chan struct{} // worker slots shared by all concurrent jobs
+	job     *job          // the job being executed (when concurrent)
 }
 
 // NewTaskContext returns a configured TaskContext.
 func NewTaskContext(app *App) *TaskContext {
 	return &TaskContext{
-		DryRun: app.Config.DryRun,
-		IO:     app.IO,
-		UI:     app.UI,
-		Logger: NewTaskLogger(app.UI, app.Config.DryRun),
-		Store:  app.Store,
+		DryRun:  app.Config.DryRun,
+		NoInput: app.Config.NoInput,
+		IO:      app.IO,
+		UI:      app.UI,
+		Journal: NewJournal(app.FS),
+		Logger:  NewLogger(app),
+		Store:   app.Store,
+		FS:      app.FS,
+		Jobs:    app.Config.Jobs,
+	}
+}
+
+// Rollback restores every path recorded in the journal
+// to the state it was in prior to the run.
+// Paths are restored in the reverse order they were recorded.
+func (ctx *TaskContext) Rollback() error {
+	return ctx.restore("rollback")
+}
+
+func (ctx *TaskContext) restore(action string) error {
+	entries := ctx.Journal.Entries()
+	errs := []error{}
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if err := entry.Restore(ctx.FS); err != nil {
+			ctx.Logger.Failure(action, fsutil.TryRelative(entry.Path))
+			errs = append(errs, err)
+			continue
+		}
+		ctx.Logger.Warning(action, fsutil.TryRelative(entry.Path))
 	}
+	ctx.Journal.Reset()
+	return errors.Join(errs...)
 }
@@ -0,0 +1,333 @@
+package stamp
+
+import (
+	"math"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// newTaskGroup returns a new, empty taskGroup for ctx.
+func newTaskGroup(ctx *TaskContext) *taskGroup {
+	if ctx.Jobs > 1 && ctx.workers == nil {
+		ctx.workers = make(chan struct{}, ctx.Jobs)
+	}
+	g := &taskGroup{
+		ctx:      ctx,
+		override: ctx.ConflictOverride,
+	}
+	g.failedAt.Store(math.MaxInt64)
+	return g
+}
+
+// taskGroup runs a sequence of jobs (task executions or individual files)
+// on behalf of a TaskContext.
+//
+// When ctx.Jobs is greater than one, jobs run concurrently on a pool of
+// ctx.Jobs workers (shared by any nested groups). Jobs that touch overlapping
+// destination paths still run in the order they were added, and everything
+// logged by a job is buffered and replayed in that order so that the output
+// is the same as a sequential run. Otherwise, jobs run as soon as they are added.
+type taskGroup struct {
+	ctx      *TaskContext
+	jobs     []*job
+	failedAt atomic.Int64
+
+	// The conflict override as of the start of the group.
+	override ConflictConfig
+}
+
+// job is a single unit of work in a concurrent taskGroup.
+type job struct {
+	group  *taskGroup
+	index  int
+	ctx    *TaskContext
+	logger *bufferedLogger
+	paths  []string
+	prev   *job
+	deps   []*job
+
+	err      error
+	override ConflictConfig // as of the end of the job
+	holding  bool           // true if the job holds a worker slot
+
+	mu      sync.Mutex
+	live    bool
+	done    chan struct{}
+	flushed chan struct{}
+}
+
+// parallel returns true if jobs run concurrently.
+func (g *taskGroup) parallel() bool {
+	return g.ctx.workers != nil
+}
+
+// Go runs fn as a job that modifies paths (absolute destination paths).
+// Jobs with no paths are assumed to modify everything.
+//
+// In a sequential group, fn is run immediately and its error returned.
+// Otherwise fn is run in the background (see [taskGroup.Wait]).
+func (g *taskGroup) Go(paths []string, fn func(ctx *TaskContext) error) error {
+	if !g.parallel() {
+		return fn(g.ctx)
+	}
+
+	j := &job{
+		group:   g,
+		index:   len(g.jobs),
+		logger:  &bufferedLogger{out: g.ctx.Logger, now: time.Now},
+		paths:   paths,
+		done:    make(chan struct{}),
+		flushed: make(chan struct{}),
+	}
+	for _, other := range g.jobs {
+		if j.overlaps(other) {
+			j.deps = append(j.deps, other)
+		}
+	}
+	if j.index > 0 {
+		j.prev = g.jobs[j.index-1]
+	}
+	j.ctx = &TaskContext{
+		DryRun:           g.ctx.DryRun,
+		NoInput:          g.ctx.NoInput,
+		IO:               g.ctx.IO,
+		UI:               g.ctx.UI,
+		Journal:          g.ctx.Journal,
+		Logger:           j.logger,
+		Store:            g.ctx.Store,
+		FS:               g.ctx.FS,
+		Jobs:             g.ctx.Jobs,
+		ConflictOverride: g.override,
+		workers:          g.ctx.workers,
+		job:              j,
+	}
+	g.jobs = append(g.jobs, j)
+
+	go j.run(fn)
+	return nil
+}
+
+// Wait waits for all jobs to finish and returns the first error
+// (in the order the jobs were added).
+func (g *taskGroup) Wait() error {
+	if !g.parallel() {
+		return nil
+	}
+	// Free up the worker slot of the job that created the group
+	// (if any) while waiting on the jobs in the group.
+	if parent := g.ctx.job; parent != nil {
+		parent.releaseSlot()
+		defer parent.takeSlot()
+	}
+	for _, j := range g.jobs {
+		<-j.flushed
+	}
+	if n := len(g.jobs); n > 0 {
+		g.ctx.ConflictOverride = g.jobs[n-1].override
+	}
+	for _, j := range g.jobs {
+		if j.err != nil {
+			return j.err
+		}
+	}
+	return nil
+}
+
+func (j *job) run(fn func(ctx *TaskContext) error) {
+	for _, dep := range j.deps {
+		<-dep.done
+	}
+	// Like a sequential run, skip jobs added after one that failed.
+	if int64(j.index) < j.group.failedAt.Load() {
+		j.takeSlot()
+		j.err = fn(j.ctx)
+		j.releaseSlot()
+		if j.err != nil {
+			j.fail()
+		}
+	}
+	close(j.done)
+
+	j.acquire()
+	j.override = j.ctx.ConflictOverride
+	close(j.flushed)
+}
+
+func (j *job) fail() {
+	for {
+		failedAt := j.group.failedAt.Load()
+		if int64(j.index) >= failedAt || j.group.failedAt.CompareAndSwap(failedAt, int64(j.index)) {
+			return
+		}
+	}
+}
+
+// acquire waits until every earlier job has finished logging, then replays
+// the output buffered by the job so far. Subsequent output is logged directly.
+func (j *job) acquire() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.live {
+		return
+	}
+
+	switch parent := j.group.ctx.job; {
+	case j.prev != nil:
+		<-j.prev.flushed
+		j.ctx.ConflictOverride = j.prev.override
+	case parent != nil:
+		parent.acquire()
+		j.ctx.ConflictOverride = parent.ctx.ConflictOverride
+	default:
+		j.ctx.ConflictOverride = j.group.override
+	}
+
+	j.logger.flush()
+	j.live = true
+}
+
+func (j *job) takeSlot() {
+	if !j.holding {
+		j.ctx.workers <- struct{}{}
+		j.holding = true
+	}
+}
+
+func (j *job) releaseSlot() {
+	if j.holding {
+		<-j.ctx.workers
+		j.holding = false
+	}
+}
+
+// overlaps returns true if j and other modify any of the same paths.
+func (j *job) overlaps(other *job) bool {
+	if len(j.paths) == 0 || len(other.paths) == 0 {
+		return true
+	}
+	for _, a := range j.paths {
+		for _, b := range other.paths {
+			if a == b || isAncestorPath(a, b) || isAncestorPath(b, a) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isAncestorPath(dir string, path string) bool {
+	return strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator))
+}
+
+// waitTurn blocks until all tasks started before the current one have
+// finished (a noop when running sequentially). Must be called before
+// interacting with the user.
+func (ctx *TaskContext) waitTurn() {
+	if ctx.job == nil {
+		return
+	}
+	ctx.job.releaseSlot()
+	ctx.job.acquire()
+	ctx.job.takeSlot()
+}
+
+var _ Logger = &bufferedLogger{}
+
+// timedLogger is implemented by loggers that record when calls happen,
+// so that buffered calls can be replayed as of their original time.
+type timedLogger interface {
+	Logger
+	withTime(t time.Time, fn func())
+}
+
+// bufferedLogger records log calls until flushed, then forwards them to out.
+type bufferedLogger struct {
+	mu    sync.Mutex
+	out   Logger
+	calls []bufferedCall
+	live  bool
+	at    time.Time // time of the call being replayed into the receiver (if any)
+	now   func() time.Time
+}
+
+type bufferedCall struct {
+	at   time.Time
+	call func(l Logger)
+}
+
+func (l *bufferedLogger) Info(action string, line string, args ...any) {
+	l.log(func(out Logger) { out.Info(action, line, args...) })
+}
+
+func (l *bufferedLogger) Success(action string, line string, args ...any) {
+	l.log(func(out Logger) { out.Success(action, line, args...) })
+}
+
+func (l *bufferedLogger) Warning(action string, line string, args ...any) {
+	l.log(func(out Logger) { out.Warning(action, line, args...) })
+}
+
+func (l *bufferedLogger) Failure(action string, line string, args ...any) {
+	l.log(func(out Logger) { out.Failure(action, line, args...) })
+}
+
+func (l *bufferedLogger) Diff(diff string) {
+	l.log(func(out Logger) { out.Diff(diff) })
+}
+
+func (l *bufferedLogger) TaskStarted(task Task, index int, iteration int) {
+	l.log(func(out Logger) { out.TaskStarted(task, index, iteration) })
+}
+
+func (l *bufferedLogger) TaskFinished(err error) {
+	l.log(func(out Logger) { out.TaskFinished(err) })
+}
+
+func (l *bufferedLogger) Summary(err error) {
+	l.log(func(out Logger) { out.Summary(err) })
+}
+
+func (l *bufferedLogger) log(call func(out Logger)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.live {
+		call(l.out)
+		return
+	}
+	at := l.at
+	if at.IsZero() {
+		at = l.now()
+	}
+	l.calls = append(l.calls, bufferedCall{at: at, call: call})
+}
+
+func (l *bufferedLogger) withTime(t time.Time, fn func()) {
+	l.mu.Lock()
+	l.at = t
+	l.mu.Unlock()
+	defer func() {
+		l.mu.Lock()
+		l.at = time.Time{}
+		l.mu.Unlock()
+	}()
+	fn()
+}
+
+// flush forwards all buffered calls and stops buffering.
+// Calls are replayed as of the time they were made (see [timedLogger]).
+func (l *bufferedLogger) flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, c := range l.calls {
+		if out, ok := l.out.(timedLogger); ok {
+			out.withTime(c.at, func() { c.call(out) })
+		} else {
+			c.call(l.out)
+		}
+	}
+	l.calls = nil
+	l.live = true
+}
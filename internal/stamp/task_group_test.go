@@ -0,0 +1,219 @@
+package stamp
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twelvelabs/termite/testutil"
+	"github.com/twelvelabs/termite/ui"
+)
+
+func TestTaskGroup_Sequential(t *testing.T) {
+	ctx := NewTaskContext(NewTestApp())
+	group := newTaskGroup(ctx)
+	assert.False(t, group.parallel())
+
+	ran := []string{}
+	err := group.Go([]string{"/a"}, func(jobCtx *TaskContext) error {
+		assert.Same(t, ctx, jobCtx, "should run with the group context")
+		ran = append(ran, "a")
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, ran, "should run immediately")
+
+	err = group.Go(nil, func(_ *TaskContext) error {
+		return errors.New("boom")
+	})
+	assert.EqualError(t, err, "boom")
+	assert.NoError(t, group.Wait())
+}
+
+func TestTaskGroup_Concurrent(t *testing.T) {
+	app := NewTestApp()
+	ctx := NewTaskContext(app)
+	ctx.Jobs = 4
+	group := newTaskGroup(ctx)
+	assert.True(t, group.parallel())
+
+	var mu sync.Mutex
+	ran := []string{}
+	job := func(name string, delay time.Duration) func(ctx *TaskContext) error {
+		return func(ctx *TaskContext) error {
+			time.Sleep(delay)
+			mu.Lock()
+			ran = append(ran, name)
+			mu.Unlock()
+			ctx.Logger.Success("run", name)
+			return nil
+		}
+	}
+	require.NoError(t, group.Go([]string{"/dst/a"}, job("a", 30*time.Millisecond)))
+	require.NoError(t, group.Go([]string{"/dst/b"}, job("b", 0)))
+	require.NoError(t, group.Go([]string{"/dst/a/c"}, job("a/c", 0)))
+	require.NoError(t, group.Go(nil, job("barrier", 0)))
+	require.NoError(t, group.Go([]string{"/dst/d"}, job("d", 0)))
+	assert.NoError(t, group.Wait())
+
+	assert.Less(t, indexOf(ran, "a"), indexOf(ran, "a/c"), "nested paths should run in order")
+	assert.Less(t, indexOf(ran, "b"), indexOf(ran, "a"), "unrelated paths should not wait")
+	assert.Equal(t, "barrier", ran[3], "barriers should wait for all earlier jobs")
+	assert.Equal(t, "d", ran[4], "jobs should wait for barriers")
+
+	assert.Equal(t, ""+
+		"✓ [       run]: a\n"+
+		"✓ [       run]: b\n"+
+		"✓ [       run]: a/c\n"+
+		"✓ [       run]: barrier\n"+
+		"✓ [       run]: d\n",
+		app.IO.Out.String(),
+		"output should be in the order jobs were added",
+	)
+}
+
+func TestTaskGroup_ConcurrentErrors(t *testing.T) {
+	ctx := NewTaskContext(NewTestApp())
+	ctx.Jobs = 1 // a single worker, but still concurrent
+	ctx.workers = make(chan struct{}, 1)
+	group := newTaskGroup(ctx)
+
+	ran := []string{}
+	require.NoError(t, group.Go([]string{"/a"}, func(_ *TaskContext) error {
+		ran = append(ran, "a")
+		return errors.New("a failed")
+	}))
+	require.NoError(t, group.Go([]string{"/a"}, func(_ *TaskContext) error {
+		ran = append(ran, "skipped")
+		return errors.New("should not run")
+	}))
+	assert.EqualError(t, group.Wait(), "a failed")
+	assert.Equal(t, []string{"a"}, ran, "jobs after a failed one should be skipped")
+}
+
+func TestTaskSet_ExecuteConcurrently(t *testing.T) {
+	run := func(jobs int) (string, map[string]any) {
+		var output string
+		files := map[string]any{}
+		testutil.InTempDir(t, func(tmpDir string) {
+			srcFiles := map[string]any{}
+			for i := 0; i < 20; i++ {
+				srcFiles[fmt.Sprintf("_src/tpl/dir%d/file%d.txt", i%3, i)] = fmt.Sprintf("file {{ .Name }} %d\n", i)
+			}
+			testutil.WritePaths(t, tmpDir, srcFiles)
+
+			app := NewTestApp()
+			app.Config.Jobs = jobs
+			ts := NewTaskSet()
+			ts.SrcPath = filepath.Join(tmpDir, "_src")
+			ts.DstPath = tmpDir
+			for _, data := range []map[string]any{
+				{
+					"type": "create",
+					"src":  map[string]any{"path": "tpl/"},
+					"dst":  map[string]any{"path": "out/"},
+				},
+				{
+					"type":   "update",
+					"src":    map[string]any{"content": "updated\n"},
+					"dst":    map[string]any{"path": "out/dir1/file{{ ._Item }}.txt"},
+					"action": map[string]any{"type": "append"},
+					"each":   "1, 4, 7",
+				},
+				{
+					"type": "create",
+					"src":  map[string]any{"content": "{{ ._Item }}"},
+					"dst":  map[string]any{"path": "items/{{ ._Item }}.txt"},
+					"each": "a, b, c, d, e",
+				},
+				{
+					"type": "delete",
+					"dst":  map[string]any{"path": "out/dir2"},
+				},
+			} {
+				task, err := NewTask(data)
+				require.NoError(t, err)
+				ts.Add(task)
+			}
+
+			ctx := NewTaskContext(app)
+			require.NoError(t, ts.Execute(ctx, map[string]any{"Name": "example"}))
+			output = app.IO.Out.String()
+
+			_ = filepath.WalkDir(tmpDir, func(path string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return err
+				}
+				rel, _ := filepath.Rel(tmpDir, path)
+				buf, _ := os.ReadFile(path)
+				files[filepath.ToSlash(rel)] = string(buf)
+				return nil
+			})
+		})
+		return output, files
+	}
+
+	sequentialOutput, sequentialFiles := run(1)
+	for i := 0; i < 5; i++ {
+		output, files := run(8)
+		assert.Equal(t, sequentialOutput, output)
+		assert.Equal(t, sequentialFiles, files)
+	}
+}
+
+func TestTaskSet_ExecuteConcurrentlyRemembersConflictChoices(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"aaa.txt": "Old",
+			"bbb.txt": "Old",
+			"ccc.txt": "Old",
+			"ddd.txt": "Old",
+		})
+
+		app := NewTestApp()
+		app.Config.Jobs = 4
+		app.UI.RegisterStub(
+			ui.MatchSelect("Resolve conflict"),
+			ui.RespondString(ConflictChoiceOverwriteAll),
+		)
+		defer app.UI.VerifyStubs(t)
+
+		ts := NewTaskSet()
+		ts.DstPath = tmpDir
+		task, err := NewTask(map[string]any{
+			"type": "create",
+			"src":  map[string]any{"content": "New"},
+			"dst":  map[string]any{"path": "{{ ._Item }}"},
+			"each": "aaa.txt, bbb.txt, ccc.txt, ddd.txt",
+		})
+		require.NoError(t, err)
+		ts.Add(task)
+
+		ctx := NewTaskContext(app)
+		assert.NoError(t, ts.Execute(ctx, map[string]any{}))
+		assert.Equal(t, ConflictConfigReplace, ctx.ConflictOverride)
+
+		testutil.AssertPaths(t, tmpDir, map[string]any{
+			"aaa.txt": "New",
+			"bbb.txt": "New",
+			"ccc.txt": "New",
+			"ddd.txt": "New",
+		})
+	})
+}
+
+func indexOf(items []string, item string) int {
+	for i, v := range items {
+		if v == item {
+			return i
+		}
+	}
+	return -1
+}
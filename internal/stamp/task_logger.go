@@ -5,6 +5,8 @@ import (
 	"strings"
 
 	"github.com/twelvelabs/termite/ui"
+
+	"github.com/twelvelabs/stamp/internal/diffutil"
 )
 
 const (
@@ -19,6 +21,8 @@ func NewTaskLogger(u *ui.UserInterface, dryRun bool) *TaskLogger {
 	}
 }
 
+var _ Logger = &TaskLogger{}
+
 // TaskLogger logs formatted Task actions.
 type TaskLogger struct {
 	ui     *ui.UserInterface
@@ -73,6 +77,38 @@ func (l *TaskLogger) Failure(action string, line string, args ...any) {
 	l.log(icon, action, line, args...)
 }
 
+// Diff logs a colorized unified diff (see [diffutil.Unified]).
+// Empty diffs are ignored.
+func (l *TaskLogger) Diff(diff string) {
+	if diff == "" {
+		return
+	}
+	for _, line := range diffutil.SplitLines([]byte(diff)) {
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			line = l.ui.Bold(line)
+		case strings.HasPrefix(line, "@@"):
+			line = l.ui.Cyan(line)
+		case strings.HasPrefix(line, "+"):
+			line = l.ui.Green(line)
+		case strings.HasPrefix(line, "-"):
+			line = l.ui.Red(line)
+		}
+		// Not using `l.ui.Out(line)` because the diff may contain format verbs.
+		l.ui.Out("%s\n", line)
+	}
+}
+
+// TaskStarted is a noop. Task boundaries are not shown in the log.
+func (l *TaskLogger) TaskStarted(_ Task, _ int, _ int) {}
+
+// TaskFinished is a noop. Task boundaries are not shown in the log.
+func (l *TaskLogger) TaskFinished(_ error) {}
+
+// Summary is a noop. The log lines speak for themselves.
+func (l *TaskLogger) Summary(_ error) {}
+
 // logs the formatted icon, action, and line to StdErr.
 func (l *TaskLogger) log(icon, action, line string, args ...any) {
 	prefix := icon + " "
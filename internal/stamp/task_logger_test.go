@@ -214,3 +214,16 @@ func TestTaskLogger_Failure(t *testing.T) {
 		})
 	}
 }
+
+func TestTaskLogger_Diff(t *testing.T) {
+	ios := ui.NewTestIOStreams()
+	u := ui.NewUserInterface(ios)
+
+	logger := NewTaskLogger(u, true)
+	logger.Diff("")
+	assert.Equal(t, "", ios.Out.String(), "empty diffs should not be logged")
+
+	diff := "--- a/file.txt\n+++ b/file.txt\n@@ -1 +1 @@\n-100%\n+200%\n"
+	logger.Diff(diff)
+	assert.Equal(t, diff, ios.Out.String(), "should not interpret format verbs")
+}
@@ -1,6 +1,9 @@
 package stamp
 
 import (
+	"maps"
+	"reflect"
+
 	"github.com/mitchellh/copystructure"
 )
 
@@ -11,7 +14,8 @@ func NewTaskSet() *TaskSet {
 	}
 }
 
-// TaskSet is an ordered set of tasks that can be executed sequentially.
+// TaskSet is an ordered set of tasks that can be executed sequentially
+// (or concurrently, see [TaskContext.Jobs]).
 type TaskSet struct {
 	tasks   []Task
 	SrcPath string
@@ -33,6 +37,10 @@ func (ts *TaskSet) Add(t Task) *TaskSet {
 // Tasks that return false from `ShouldExecute()` are skipped.
 // Tasks that return a slice from `Iterator()` will be executed once
 // per item in the slice.
+//
+// When executing concurrently, tasks only wait on earlier tasks
+// that modify the same destination path (or on every earlier task,
+// if the path can not be determined up front).
 func (ts *TaskSet) Execute(ctx *TaskContext, data map[string]any) error {
 	// deep-copy values
 	copied, err := copystructure.Copy(data)
@@ -46,24 +54,67 @@ func (ts *TaskSet) Execute(ctx *TaskContext, data map[string]any) error {
 		values["DstPath"] = ts.DstPath
 	}
 
-	for _, t := range ts.All() {
+	group := newTaskGroup(ctx)
+	for i, t := range ts.All() {
 		if iter := t.Iterator(values); iter != nil { //nolint: nestif
-			for i, item := range iter {
-				values["_Index"] = i
+			for j, item := range iter {
+				values["_Index"] = j
 				values["_Item"] = item
 				if t.ShouldExecute(values) {
-					err := t.Execute(ctx, values)
+					err := ts.execute(group, t, i, j, values)
 					if err != nil {
 						return err
 					}
 				}
 			}
 		} else if t.ShouldExecute(values) {
-			err := t.Execute(ctx, values)
+			err := ts.execute(group, t, i, NoIteration, values)
 			if err != nil {
 				return err
 			}
 		}
 	}
+	return group.Wait()
+}
+
+// execute executes the task at index, notifying the logger before and after.
+func (ts *TaskSet) execute(group *taskGroup, t Task, index int, iteration int, values map[string]any) error {
+	if group.parallel() {
+		// Concurrent executions can't share the task (which is stateful)
+		// or the values (which change each iteration).
+		t = copyTask(t)
+		values = maps.Clone(values)
+	}
+	return group.Go(taskDstPaths(t, values), func(ctx *TaskContext) error {
+		ctx.Logger.TaskStarted(t, index, iteration)
+		err := t.Execute(ctx, values)
+		ctx.Logger.TaskFinished(err)
+		return err
+	})
+}
+
+// dstPathTask is implemented by tasks that only modify
+// a known set of paths in the destination dir.
+type dstPathTask interface {
+	// dstPaths returns the absolute paths modified by the task
+	// or nil if they can not be determined.
+	dstPaths(values map[string]any) []string
+}
+
+func taskDstPaths(t Task, values map[string]any) []string {
+	if dt, ok := t.(dstPathTask); ok {
+		return dt.dstPaths(values)
+	}
 	return nil
 }
+
+// copyTask returns a shallow copy of t.
+func copyTask(t Task) Task { //nolint:ireturn
+	v := reflect.ValueOf(t)
+	if v.Kind() != reflect.Pointer {
+		return t
+	}
+	c := reflect.New(v.Elem().Type())
+	c.Elem().Set(v.Elem())
+	return c.Interface().(Task)
+}
@@ -0,0 +1,266 @@
+package stamp
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	cp "github.com/otiai10/copy"
+
+	"github.com/twelvelabs/stamp/internal/diffutil"
+	"github.com/twelvelabs/stamp/internal/fsutil"
+)
+
+const (
+	// TestDir is the generator dir containing test cases (one per sub-dir).
+	TestDir = "_test"
+	// TestValuesFile is the answers file used to run a test case.
+	TestValuesFile = "values.yaml"
+	// TestBeforeDir is the optional fixture tree the generator is run against.
+	TestBeforeDir = "before"
+	// TestExpectedDir is the golden tree the result is compared to.
+	TestExpectedDir = "expected"
+)
+
+// TestCase is a golden file test for a generator.
+//
+// Each case lives in `_test/<name>/` and contains a values file,
+// an optional `before/` tree that is copied to the destination
+// prior to running the generator, and an `expected/` tree that
+// the destination must match afterwards.
+type TestCase struct {
+	Name string
+	Path string
+
+	generatorPath string
+}
+
+// TestResult is the outcome of running a [TestCase].
+type TestResult struct {
+	Case *TestCase
+	// Diffs contains a unified diff for each path that did not match.
+	Diffs []string
+	// Updated is true if the expected tree was rewritten.
+	Updated bool
+}
+
+// Passed returns true if the result matched the expected tree.
+func (r *TestResult) Passed() bool {
+	return len(r.Diffs) == 0
+}
+
+// TestCases returns the generator's test cases sorted by name.
+func (g *Generator) TestCases() ([]*TestCase, error) {
+	root := filepath.Join(g.Path(), TestDir)
+	entries, err := os.ReadDir(root)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []*TestCase{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("test cases: %w", err)
+	}
+
+	cases := []*TestCase{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		cases = append(cases, &TestCase{
+			Name:          entry.Name(),
+			Path:          filepath.Join(root, entry.Name()),
+			generatorPath: g.Path(),
+		})
+	}
+	sort.Slice(cases, func(i, j int) bool {
+		return cases[i].Name < cases[j].Name
+	})
+	return cases, nil
+}
+
+// ValuesPath returns the path to the values file.
+func (tc *TestCase) ValuesPath() string {
+	return filepath.Join(tc.Path, TestValuesFile)
+}
+
+// BeforePath returns the path to the fixture tree.
+func (tc *TestCase) BeforePath() string {
+	return filepath.Join(tc.Path, TestBeforeDir)
+}
+
+// ExpectedPath returns the path to the golden tree.
+func (tc *TestCase) ExpectedPath() string {
+	return filepath.Join(tc.Path, TestExpectedDir)
+}
+
+// Run runs the generator into a temp dir and compares the result
+// to the expected tree. Values are never prompted for: any that
+// are not in the values file resolve to their defaults.
+//
+// When update is true, the expected tree is replaced with the result
+// instead of being compared.
+func (tc *TestCase) Run(app *App, update bool) (*TestResult, error) {
+	// Load a fresh copy of the generator, since values are stateful.
+	generator, err := NewGeneratorFromPath(app.Store, tc.generatorPath)
+	if err != nil {
+		return nil, err
+	}
+
+	dstPath, err := os.MkdirTemp("", "stamp-test-*")
+	if err != nil {
+		return nil, fmt.Errorf("test %s: %w", tc.Name, err)
+	}
+	defer os.RemoveAll(dstPath)
+
+	if fsutil.PathIsDir(tc.BeforePath()) {
+		if err := cp.Copy(tc.BeforePath(), dstPath); err != nil {
+			return nil, fmt.Errorf("test %s: %w", tc.Name, err)
+		}
+	}
+
+	if err := tc.setValues(generator, dstPath); err != nil {
+		return nil, fmt.Errorf("test %s: %w", tc.Name, err)
+	}
+	values := generator.Values.GetAll()
+	values["DstPath"] = dstPath
+	generator.Tasks.DstPath = dstPath
+
+	ctx := NewTaskContext(app)
+	ctx.DryRun = false
+	ctx.NoInput = true
+	ctx.Logger = &NopLogger{}
+	if err := generator.Tasks.Execute(ctx, values); err != nil {
+		return nil, fmt.Errorf("test %s: %w", tc.Name, err)
+	}
+
+	result := &TestResult{Case: tc}
+	if update {
+		if err := tc.updateExpected(dstPath); err != nil {
+			return nil, fmt.Errorf("test %s: %w", tc.Name, err)
+		}
+		result.Updated = true
+		return result, nil
+	}
+
+	result.Diffs, err = diffTrees(tc.ExpectedPath(), dstPath)
+	if err != nil {
+		return nil, fmt.Errorf("test %s: %w", tc.Name, err)
+	}
+	return result, nil
+}
+
+func (tc *TestCase) setValues(generator *Generator, dstPath string) error {
+	answers := map[string]any{}
+	if fsutil.PathExists(tc.ValuesPath()) {
+		var err error
+		answers, err = ReadAnswers(tc.ValuesPath(), nil)
+		if err != nil {
+			return err
+		}
+	}
+	for key, data := range answers {
+		if err := generator.Values.Set(key, data); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+	}
+	if generator.Values.Value("DstPath") != nil {
+		if err := generator.Values.Set("DstPath", dstPath); err != nil {
+			return err
+		}
+	}
+	return generator.Values.Validate()
+}
+
+// updateExpected replaces the expected tree with the generated one.
+func (tc *TestCase) updateExpected(dstPath string) error {
+	if err := os.RemoveAll(tc.ExpectedPath()); err != nil {
+		return err
+	}
+	return cp.Copy(dstPath, tc.ExpectedPath(), cp.Options{
+		Skip: func(_ os.FileInfo, src, _ string) (bool, error) {
+			return isStatePath(dstPath, src), nil
+		},
+	})
+}
+
+// diffTrees returns a unified diff for each file that differs between
+// the expected and actual dirs (ignoring the state dir).
+func diffTrees(expected string, actual string) ([]string, error) {
+	expectedFiles, err := treeFiles(expected)
+	if err != nil {
+		return nil, err
+	}
+	actualFiles, err := treeFiles(actual)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{}
+	for rel := range expectedFiles {
+		paths = append(paths, rel)
+	}
+	for rel := range actualFiles {
+		if _, ok := expectedFiles[rel]; !ok {
+			paths = append(paths, rel)
+		}
+	}
+	sort.Strings(paths)
+
+	diffs := []string{}
+	for _, rel := range paths {
+		from, to := filepath.Join(TestExpectedDir, rel), filepath.Join("actual", rel)
+		a, inExpected := expectedFiles[rel]
+		b, inActual := actualFiles[rel]
+		if !inExpected {
+			from = diffutil.DevNull
+		}
+		if !inActual {
+			to = diffutil.DevNull
+		}
+		diff, err := diffutil.Unified(a, b, from, to)
+		if err != nil {
+			return nil, err
+		}
+		if diff == "" && inExpected != inActual {
+			// Empty files still need to be reported.
+			diff = fmt.Sprintf("--- %s\n+++ %s\n", from, to)
+		}
+		if diff != "" {
+			diffs = append(diffs, diff)
+		}
+	}
+	return diffs, nil
+}
+
+// treeFiles returns the content of every file in root keyed by relative path.
+func treeFiles(root string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	if !fsutil.PathIsDir(root) {
+		return files, nil
+	}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if isStatePath(root, path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		files[rel] = buf
+		return nil
+	})
+	return files, err
+}
+
+func isStatePath(root string, path string) bool {
+	return path == filepath.Join(root, StateDir)
+}
@@ -0,0 +1,107 @@
+package stamp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	cp "github.com/otiai10/copy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twelvelabs/termite/testutil"
+)
+
+func TestGenerator_TestCases(t *testing.T) {
+	store := NewTestStore()
+
+	gen, err := store.Load("file")
+	require.NoError(t, err)
+	cases, err := gen.TestCases()
+	require.NoError(t, err)
+	require.Len(t, cases, 2)
+	assert.Equal(t, "custom", cases[0].Name)
+	assert.Equal(t, filepath.Join(gen.Path(), "_test", "custom", "values.yaml"), cases[0].ValuesPath())
+	assert.Equal(t, "defaults", cases[1].Name)
+
+	gen, err = store.Load("delegating")
+	require.NoError(t, err)
+	cases, err = gen.TestCases()
+	require.NoError(t, err)
+	assert.Empty(t, cases)
+}
+
+func TestTestCase_Run(t *testing.T) {
+	app := NewTestApp()
+
+	gen, err := app.Store.Load("file")
+	require.NoError(t, err)
+	cases, err := gen.TestCases()
+	require.NoError(t, err)
+
+	for _, tc := range cases {
+		result, err := tc.Run(app, false)
+		require.NoError(t, err)
+		assert.True(t, result.Passed(), "%s: %v", tc.Name, result.Diffs)
+		assert.False(t, result.Updated)
+	}
+	assert.Empty(t, app.IO.Out.String(), "should not log task actions")
+}
+
+func TestTestCase_RunReportsDiffs(t *testing.T) {
+	app := NewTestApp()
+	testutil.InTempDir(t, func(tmpDir string) {
+		genPath := filepath.Join(tmpDir, "file")
+		require.NoError(t, cp.Copy(filepath.Join(app.Store.BasePath, "file"), genPath))
+
+		testutil.WritePaths(t, genPath, map[string]any{
+			"_test/custom/expected/hello.txt": "Goodbye",
+			"_test/custom/expected/extra.txt": "extra",
+		})
+		gen, err := NewGeneratorFromPath(app.Store, genPath)
+		require.NoError(t, err)
+		cases, err := gen.TestCases()
+		require.NoError(t, err)
+
+		result, err := cases[0].Run(app, false)
+		require.NoError(t, err)
+		assert.False(t, result.Passed())
+		assert.Equal(t, []string{
+			"--- expected/extra.txt\n+++ /dev/null\n@@ -1 +0,0 @@\n-extra\n",
+			"--- expected/hello.txt\n+++ actual/hello.txt\n@@ -1 +1 @@\n-Goodbye\n+Hello\n",
+		}, result.Diffs)
+
+		// Updating rewrites the expected tree...
+		result, err = cases[0].Run(app, true)
+		require.NoError(t, err)
+		assert.True(t, result.Updated)
+		testutil.AssertPaths(t, genPath, map[string]any{
+			"_test/custom/expected/hello.txt":    "Hello",
+			"_test/custom/expected/existing.txt": "keep me\n",
+			"_test/custom/expected/extra.txt":    false,
+		})
+
+		// ... so that it passes afterwards.
+		result, err = cases[0].Run(app, false)
+		require.NoError(t, err)
+		assert.True(t, result.Passed())
+	})
+}
+
+func TestTestCase_RunReturnsErrors(t *testing.T) {
+	app := NewTestApp()
+	testutil.InTempDir(t, func(tmpDir string) {
+		genPath := filepath.Join(tmpDir, "file")
+		require.NoError(t, cp.Copy(filepath.Join(app.Store.BasePath, "file"), genPath))
+		require.NoError(t, os.WriteFile(
+			filepath.Join(genPath, "_test", "custom", "values.yaml"), []byte("- not a map\n"), 0o600,
+		))
+
+		gen, err := NewGeneratorFromPath(app.Store, genPath)
+		require.NoError(t, err)
+		cases, err := gen.TestCases()
+		require.NoError(t, err)
+
+		_, err = cases[0].Run(app, false)
+		assert.ErrorContains(t, err, "test custom: answers decode:")
+	})
+}
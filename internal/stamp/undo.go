@@ -0,0 +1,241 @@
+package stamp
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/twelvelabs/stamp/internal/fsutil"
+	"github.com/twelvelabs/stamp/internal/vfs"
+)
+
+var (
+	// ErrModifiedSinceRun is returned when attempting to undo a run
+	// whose files have since been modified.
+	ErrModifiedSinceRun = errors.New("modified since the last run")
+)
+
+// journalRecord is the on-disk representation of a JournalEntry.
+type journalRecord struct {
+	Path     string      `yaml:"path"`
+	Existed  bool        `yaml:"existed"`
+	IsDir    bool        `yaml:"is_dir,omitempty"`
+	Link     string      `yaml:"link,omitempty"`
+	Mode     os.FileMode `yaml:"mode,omitempty"`
+	Content  string      `yaml:"content,omitempty"`
+	Checksum string      `yaml:"checksum,omitempty"`
+}
+
+// JournalPath returns the path used to store the journal of the last run.
+func (s *State) JournalPath() string {
+	return s.Path("journal.yaml")
+}
+
+// ReadJournal returns the journal of the last run.
+// Returns [ErrNotFound] if no journal has been recorded.
+func (s *State) ReadJournal() (*Journal, error) {
+	path := s.JournalPath()
+	if !vfs.Exists(s.fs, path) {
+		return nil, ErrNotFound
+	}
+	buf, err := s.fs.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("journal read: %w", err)
+	}
+	records := []journalRecord{}
+	if err := yaml.Unmarshal(buf, &records); err != nil {
+		return nil, fmt.Errorf("journal decode: %w", err)
+	}
+
+	j := NewJournal(s.fs)
+	for _, r := range records {
+		content, err := base64.StdEncoding.DecodeString(r.Content)
+		if err != nil {
+			return nil, fmt.Errorf("journal decode: %w", err)
+		}
+		j.add(&JournalEntry{
+			Path:     filepath.Join(s.root, filepath.FromSlash(r.Path)),
+			Existed:  r.Existed,
+			IsDir:    r.IsDir,
+			Link:     r.Link,
+			Mode:     r.Mode,
+			Content:  content,
+			Checksum: r.Checksum,
+		})
+	}
+	return j, nil
+}
+
+// WriteJournal saves j (along with the current checksum of each path)
+// as the journal of the last run.
+func (s *State) WriteJournal(j *Journal) error {
+	records := []journalRecord{}
+	for _, e := range j.Entries() {
+		rel, err := filepath.Rel(s.root, e.Path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return fmt.Errorf("journal write: %s is outside of %s", e.Path, s.root)
+		}
+		sum, err := pathChecksum(s.fs, e.Path)
+		if err != nil {
+			return err
+		}
+		records = append(records, journalRecord{
+			Path:     filepath.ToSlash(rel),
+			Existed:  e.Existed,
+			IsDir:    e.IsDir,
+			Link:     e.Link,
+			Mode:     e.Mode,
+			Content:  base64.StdEncoding.EncodeToString(e.Content),
+			Checksum: sum,
+		})
+	}
+
+	buf, err := yaml.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("journal encode: %w", err)
+	}
+	path := s.JournalPath()
+	if err := s.fs.MkdirAll(filepath.Dir(path), DstDirMode); err != nil {
+		return fmt.Errorf("journal write: %w", err)
+	}
+	if err := s.fs.WriteFile(path, buf, DstFileMode); err != nil {
+		return fmt.Errorf("journal write: %w", err)
+	}
+	return nil
+}
+
+// Modified returns the paths in j that have changed since the end of the run.
+// This includes any paths that have been added to dirs created by the run.
+func (s *State) Modified(j *Journal) ([]string, error) {
+	seen := map[string]bool{}
+	for _, e := range j.Entries() {
+		if e.IsDir || vfs.IsDir(s.fs, e.Path) {
+			if e.Existed {
+				continue
+			}
+			// The run created this dir, so undo will remove it (and everything in it).
+			added, err := s.untracked(j, e.Path)
+			if err != nil {
+				return nil, err
+			}
+			for _, path := range added {
+				seen[path] = true
+			}
+			continue
+		}
+		sum, err := pathChecksum(s.fs, e.Path)
+		if err != nil {
+			return nil, err
+		}
+		if sum != e.Checksum {
+			seen[fsutil.TryRelative(e.Path)] = true
+		}
+	}
+
+	modified := []string{}
+	for path := range seen {
+		modified = append(modified, path)
+	}
+	sort.Strings(modified)
+	return modified, nil
+}
+
+// untracked returns the paths in dir that are not recorded in j.
+func (s *State) untracked(j *Journal, dir string) ([]string, error) {
+	if !vfs.IsDir(s.fs, dir) {
+		return nil, nil
+	}
+	paths := []string{}
+	err := vfs.WalkDir(s.fs, dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if _, ok := j.index[path]; ok || path == s.JournalPath() {
+			return nil
+		}
+		paths = append(paths, fsutil.TryRelative(path))
+		if d.IsDir() {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("journal check: %w", err)
+	}
+	return paths, nil
+}
+
+// RecordJournal saves the journal of a completed run of gen to the
+// state dir of the destination so that it can be undone. Noop during a dry run.
+func RecordJournal(ctx *TaskContext, gen *Generator, values map[string]any) error {
+	if ctx.DryRun {
+		return nil
+	}
+	root, err := dstRoot(gen, values)
+	if err != nil {
+		return err
+	}
+	return NewState(ctx.FS, root).WriteJournal(ctx.Journal)
+}
+
+// Undo reverts the last run recorded in state.
+// Returns [ErrModifiedSinceRun] if any of the paths have changed since
+// the run (unless force is true).
+func (ctx *TaskContext) Undo(state *State, force bool) error {
+	journal, err := state.ReadJournal()
+	if err != nil {
+		return err
+	}
+
+	if !force {
+		modified, err := state.Modified(journal)
+		if err != nil {
+			return err
+		}
+		if len(modified) > 0 {
+			return fmt.Errorf("%w: %s", ErrModifiedSinceRun, strings.Join(modified, ", "))
+		}
+	}
+
+	ctx.Journal = journal
+	if err := ctx.restore("undo"); err != nil {
+		return err
+	}
+
+	// Only the most recent run can be undone.
+	if err := state.fs.RemoveAll(state.JournalPath()); err != nil {
+		return fmt.Errorf("journal remove: %w", err)
+	}
+	return nil
+}
+
+// pathChecksum returns a checksum of the file or symlink at path in fsys.
+// Returns an empty string if path does not exist or is a dir.
+func pathChecksum(fsys vfs.FS, path string) (string, error) {
+	info, err := fsys.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("checksum error: %w", err)
+	}
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		link, err := fsys.Readlink(path)
+		if err != nil {
+			return "", fmt.Errorf("checksum error: %w", err)
+		}
+		return contentChecksum([]byte(link)), nil
+	case info.IsDir():
+		return "", nil
+	default:
+		return fileChecksum(fsys, path)
+	}
+}
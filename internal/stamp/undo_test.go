@@ -0,0 +1,149 @@
+package stamp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twelvelabs/termite/testutil"
+
+	"github.com/twelvelabs/stamp/internal/vfs"
+)
+
+// undoTestRun executes a run that creates, updates, and deletes files in tmpDir
+// and saves the resulting journal.
+func undoTestRun(t *testing.T, app *App, tmpDir string) {
+	t.Helper()
+
+	testutil.WritePaths(t, tmpDir, map[string]any{
+		"README.md":     "Pre-existing content",
+		"docs/guide.md": "Guide",
+		"binary.dat":    string([]byte{0xff, 0x00, 0xfe}),
+	})
+
+	ts := NewTaskSet()
+	ts.DstPath = tmpDir
+	for _, data := range []map[string]any{
+		{
+			"type": "create",
+			"src":  map[string]any{"content": "Replaced"},
+			"dst":  map[string]any{"path": "README.md", "conflict": "replace"},
+		},
+		{
+			"type": "create",
+			"src":  map[string]any{"content": "New"},
+			"dst":  map[string]any{"path": "new/nested/file.txt"},
+		},
+		{
+			"type": "delete",
+			"dst":  map[string]any{"path": "docs"},
+		},
+		{
+			"type": "delete",
+			"dst":  map[string]any{"path": "binary.dat"},
+		},
+	} {
+		task, err := NewTask(data)
+		require.NoError(t, err)
+		ts.Add(task)
+	}
+
+	ctx := NewTaskContext(app)
+	require.NoError(t, ts.Execute(ctx, map[string]any{}))
+	require.NoError(t, NewState(vfs.NewOS(), tmpDir).WriteJournal(ctx.Journal))
+}
+
+func TestState_Journal(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		state := NewState(vfs.NewOS(), tmpDir)
+		_, err := state.ReadJournal()
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		undoTestRun(t, NewTestApp(), tmpDir)
+
+		j, err := state.ReadJournal()
+		require.NoError(t, err)
+
+		paths := []string{}
+		for _, e := range j.Entries() {
+			rel, _ := filepath.Rel(tmpDir, e.Path)
+			paths = append(paths, rel)
+		}
+		assert.Equal(t, []string{
+			"README.md",
+			"new",
+			"new/nested",
+			"new/nested/file.txt",
+			"docs",
+			"docs/guide.md",
+			"binary.dat",
+		}, paths)
+
+		entries := j.Entries()
+		assert.Equal(t, []byte("Pre-existing content"), entries[0].Content)
+		assert.Equal(t, contentChecksum([]byte("Replaced")), entries[0].Checksum)
+		assert.Equal(t, []byte{0xff, 0x00, 0xfe}, entries[6].Content, "binary content should round trip")
+		assert.Equal(t, "", entries[6].Checksum)
+
+		modified, err := state.Modified(j)
+		assert.NoError(t, err)
+		assert.Empty(t, modified)
+	})
+}
+
+func TestTaskContext_Undo(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		app := NewTestApp()
+		undoTestRun(t, app, tmpDir)
+
+		ctx := NewTaskContext(app)
+		err := ctx.Undo(NewState(vfs.NewOS(), tmpDir), false)
+		assert.NoError(t, err)
+
+		testutil.AssertPaths(t, tmpDir, map[string]any{
+			"README.md":     "Pre-existing content",
+			"docs/guide.md": "Guide",
+			"binary.dat":    string([]byte{0xff, 0x00, 0xfe}),
+			"new/":          false,
+			".stamp/":       true,
+		})
+		assert.NoFileExists(t, NewState(vfs.NewOS(), tmpDir).JournalPath())
+
+		err = ctx.Undo(NewState(vfs.NewOS(), tmpDir), false)
+		assert.ErrorIs(t, err, ErrNotFound, "should only be able to undo once")
+	})
+}
+
+func TestTaskContext_UndoRefusesWhenModified(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		app := NewTestApp()
+		undoTestRun(t, app, tmpDir)
+
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"README.md":       "Local edit",
+			"new/other.txt":   "Added to a generated dir",
+			"docs/recreated":  "Recreated",
+			"unrelated.txt":   "Unrelated",
+			"new/nested/more": "More",
+		})
+
+		ctx := NewTaskContext(app)
+		err := ctx.Undo(NewState(vfs.NewOS(), tmpDir), false)
+		assert.ErrorIs(t, err, ErrModifiedSinceRun)
+		assert.ErrorContains(t, err, "README.md, new/nested/more, new/other.txt")
+		assert.FileExists(t, filepath.Join(tmpDir, "new", "nested", "file.txt"), "should not undo anything")
+
+		err = ctx.Undo(NewState(vfs.NewOS(), tmpDir), true)
+		assert.NoError(t, err)
+		testutil.AssertPaths(t, tmpDir, map[string]any{
+			"README.md":     "Pre-existing content",
+			"docs/guide.md": "Guide",
+			"unrelated.txt": "Unrelated",
+			"new/":          false,
+		})
+		_, err = os.Stat(filepath.Join(tmpDir, "docs", "recreated"))
+		assert.NoError(t, err, "restoring a dir should not remove unrelated files")
+	})
+}
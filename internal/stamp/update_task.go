@@ -2,11 +2,15 @@ package stamp
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
-	"os"
+	"go/format"
 	"regexp"
+	"strings"
 
+	"github.com/beevik/etree"
 	"github.com/ohler55/ojg/jp"
+	"github.com/spf13/cast"
 	"github.com/swaggest/jsonschema-go"
 	"github.com/twelvelabs/termite/render"
 
@@ -33,11 +37,30 @@ func (t *UpdateTask) PrepareJSONSchema(schema *jsonschema.Schema) error {
 
 		The default behavior is to replace the entire file with the
 		source content, but you can optionally specify alternate
-		[actions](#action) (prepend, append, or delete) or [target](#match)
-		a subsection of the destination file.
-		If the destination file is structured (JSON, YAML), then you
-		may target a JSON path pattern, otherwise it will be treated
-		as plain text and you can target via regular expression.
+		[actions](#action) (prepend, append, insert-before, insert-after,
+		or delete) or [target](#match) a subsection of the destination file.
+		If the destination file is structured (JSON, YAML, TOML, dotenv, INI),
+		then you may target a JSON path pattern, and if it is XML you may target
+		an XPath expression (append, prepend, replace, or delete elements,
+		or attributes via a trailing __CODE_SPAN__/@name__CODE_SPAN__).
+		If the content type is markdown, you may target a section
+		by its heading path (i.e. __CODE_SPAN__## Usage > ### Flags__CODE_SPAN__).
+		Prepend, append, and replace only change the text before its first subsection
+		(deleting a section also deletes its subsections).
+		Both ATX (__CODE_SPAN__#__CODE_SPAN__) and setext (underlined) headings are supported,
+		and missing sections are handled according to __CODE_SPAN__dst.missing__CODE_SPAN__
+		(__CODE_SPAN__error__CODE_SPAN__ fails the task, otherwise the update is skipped).
+		If the content type is go, you may target the imports, a function body,
+		struct fields, or switch cases (i.e. __CODE_SPAN__import__CODE_SPAN__, __CODE_SPAN__func:main__CODE_SPAN__,
+		__CODE_SPAN__struct:Config__CODE_SPAN__, or __CODE_SPAN__switch:run:cmd__CODE_SPAN__).
+		Missing targets are handled the same way as missing sections,
+		an __CODE_SPAN__import__CODE_SPAN__ guard (see [unless_present](#action)) is only present
+		if all of the imports in the source content are,
+		and go files are always formatted with gofmt.
+		Otherwise it will be treated as plain text
+		and you can target via regular expression.
+		Comments, key order, and formatting are preserved
+		when updating JSON (including JSONC), YAML, TOML, XML, dotenv, and INI files.
 
 		Examples:
 
@@ -46,12 +69,13 @@ func (t *UpdateTask) PrepareJSONSchema(schema *jsonschema.Schema) error {
 			- type: update
 				# Render <./_src/COPYRIGHT.tpl> and append it
 				# to the end of the README.
-				# If the README does not exist in the destination dir,
-				# then do nothing.
+				# If the README does not exist in the destination dir
+				# (or already contains the copyright), then do nothing.
 				src:
 					path: "COPYRIGHT.tpl"
 				action:
 					type: "append"
+					idempotent: true
 				dst:
 					path: "README.md"
 		__CODE_BLOCK__
@@ -81,14 +105,70 @@ func (t *UpdateTask) PrepareJSONSchema(schema *jsonschema.Schema) error {
 					content:
 						lodash: "4.17.21"
 		__CODE_BLOCK__
+
+		__CODE_BLOCK__yaml
+		tasks:
+			- type: update
+				# Add a dependency to <./pom.xml> in the destination dir
+				# (unless it has already been added).
+				dst:
+					path: "pom.xml"
+				match:
+					pattern: "/project/dependencies"
+				action:
+					type: "append"
+					merge: "upsert"
+				src:
+					content: |
+						<dependency>
+							<groupId>org.slf4j</groupId>
+							<artifactId>slf4j-api</artifactId>
+						</dependency>
+		__CODE_BLOCK__
+
+		__CODE_BLOCK__yaml
+		tasks:
+			- type: update
+				# Add an entry to the "Unreleased" section of <./CHANGELOG.md>
+				# (creating the section if needed).
+				dst:
+					path: "CHANGELOG.md"
+					content_type: "markdown"
+				match:
+					pattern: "# Changelog > ## Unreleased"
+					default: ""
+				action:
+					type: "append"
+				src:
+					content: "- Added {{ .Feature }}"
+		__CODE_BLOCK__
+
+		__CODE_BLOCK__yaml
+		tasks:
+			- type: update
+				# Register a handler at the end of the Register func in <./routes.go>
+				# (unless it is already registered).
+				dst:
+					path: "routes.go"
+					content_type: "go"
+				match:
+					pattern: "func:Register"
+				action:
+					type: "append"
+					merge: "upsert"
+				src:
+					content: 'mux.HandleFunc("/{{ .Name }}", {{ .Name }}.Handler)'
+		__CODE_BLOCK__
 	`))
 
 	return nil
 }
 
 type UpdateAction struct {
-	Type      modify.Action    `mapstructure:"type"  title:"Type"  default:"replace"`
-	MergeType modify.MergeType `mapstructure:"merge" title:"Merge" default:"concat"`
+	Type             modify.Action    `mapstructure:"type"           title:"Type"  default:"replace"`
+	MergeType        modify.MergeType `mapstructure:"merge"          title:"Merge" default:"concat"`
+	Idempotent       bool             `mapstructure:"idempotent"     title:"Idempotent" default:"false" description:"Skip the update if the source content is already present in the destination (for replace, if the destination would be unchanged, and for inserts, if it is already next to each match). Text content is compared ignoring indentation, and empty content is never present."` //nolint: lll
+	UnlessPresentTpl render.Template  `mapstructure:"unless_present" title:"Unless Present" description:"Skip the update if this regexp (content type: text), JSON path expression (content type: json, yaml, toml, dotenv, ini), XPath expression (content type: xml), heading path (content type: markdown), or Go target (content type: go) matches anything in the destination."`             //nolint: lll
 }
 
 // PrepareJSONSchema implements the jsonschema.Preparer interface.
@@ -99,11 +179,13 @@ func (UpdateAction) PrepareJSONSchema(schema *jsonschema.Schema) error {
 }
 
 type UpdateMatch struct {
-	PatternTpl render.Template `mapstructure:"pattern" title:"Pattern" default:"" description:"A regexp (content type: text) or JSON path expression (content type: json, yaml). When empty, will match everything."` //nolint: lll
-	Default    any             `mapstructure:"default" title:"Default" description:"A default value to use if the JSON path expression is not found."`                                                                //nolint: lll
-	Source     MatchSource     `mapstructure:"source"  title:"Source"  default:"line"`
+	PatternTpl render.Template `mapstructure:"pattern"    title:"Pattern" default:"" description:"A regexp (content type: text), JSON path expression (content type: json, yaml, toml, dotenv, ini), XPath expression (content type: xml), heading path (content type: markdown), or Go target (content type: go). When empty, will match everything."` //nolint: lll
+	Default    any             `mapstructure:"default"    title:"Default" description:"A default value to use if the JSON path expression (or heading path) is not found."`                                                                                                                                                                             //nolint: lll
+	Occurrence MatchOccurrence `mapstructure:"occurrence" title:"Occurrence" default:"all"`
+	Source     MatchSource     `mapstructure:"source"     title:"Source"  default:"line"`
 
-	pattern string
+	pattern  string
+	targeted bool // pattern is a markdown heading path or Go target
 }
 
 // PrepareJSONSchema implements the jsonschema.Preparer interface.
@@ -121,11 +203,14 @@ func (um *UpdateMatch) Pattern() string {
 // SetPattern sets the given match pattern.
 // Matches everything if the pattern is empty.
 func (um *UpdateMatch) SetPattern(pat string, ct FileType) {
+	um.targeted = pat != "" && (ct == FileTypeMarkdown || ct == FileTypeGo)
 	if pat != "" {
 		um.pattern = pat
 	} else {
 		if ct.IsStructured() {
 			um.pattern = "$" // root node
+		} else if ct == FileTypeXml {
+			um.pattern = "/*" // root element
 		} else {
 			um.Source = MatchSourceFile
 			um.pattern = "(?s)^(.*)$" // `?s` causes . to match newlines
@@ -137,13 +222,20 @@ func (t *UpdateTask) TypeKey() string {
 	return t.Type
 }
 
+func (t *UpdateTask) dstPaths(values map[string]any) []string {
+	if path := t.Dst.lockPath(values); path != "" {
+		return []string{path}
+	}
+	return nil
+}
+
 func (t *UpdateTask) Execute(ctx *TaskContext, values map[string]any) error {
 	// Render the source and destination.
-	if err := t.Dst.SetValues(values); err != nil {
+	if err := t.Dst.SetValues(ctx.FS, values); err != nil {
 		ctx.Logger.Failure("fail", t.Dst.RelativePath())
 		return err
 	}
-	if err := t.Src.SetValues(values); err != nil {
+	if err := t.Src.SetValues(ctx.FS, values); err != nil {
 		ctx.Logger.Failure("fail", t.Dst.RelativePath())
 		return err
 	}
@@ -163,6 +255,13 @@ func (t *UpdateTask) Execute(ctx *TaskContext, values map[string]any) error {
 	}
 	t.Match.SetPattern(pattern, t.Dst.ContentType())
 
+	// Render the presence guard.
+	guard, err := t.Action.UnlessPresentTpl.Render(values)
+	if err != nil {
+		ctx.Logger.Failure("fail", t.Dst.RelativePath())
+		return err
+	}
+
 	// Handle missing destination path.
 	if !t.Dst.Exists() {
 		switch t.Dst.Missing {
@@ -171,7 +270,11 @@ func (t *UpdateTask) Execute(ctx *TaskContext, values map[string]any) error {
 			return ErrPathNotFound
 		case MissingConfigTouch:
 			if !ctx.DryRun {
-				if err := os.WriteFile(t.Dst.Path(), []byte{}, DstFileMode); err != nil {
+				if err := ctx.Journal.Record(t.Dst.Path()); err != nil {
+					ctx.Logger.Failure("fail", t.Dst.RelativePath())
+					return err
+				}
+				if err := ctx.FS.WriteFile(t.Dst.Path(), []byte{}, DstFileMode); err != nil {
 					ctx.Logger.Failure("fail", t.Dst.RelativePath())
 					return err
 				}
@@ -181,48 +284,278 @@ func (t *UpdateTask) Execute(ctx *TaskContext, values map[string]any) error {
 		}
 	}
 
+	// Skip the update if the content is already present.
+	present, err := t.isPresent(guard)
+	if err != nil {
+		ctx.Logger.Failure("fail", t.Dst.RelativePath())
+		return err
+	}
+	if present {
+		ctx.Logger.Success("skip", "%s (already present)", t.Dst.RelativePath())
+		return nil
+	}
+
 	// Update the file.
-	if err := t.updateDst(ctx); err != nil {
+	diff, err := t.updateDst(ctx)
+	if errors.Is(err, modify.ErrMarkdownSectionNotFound) || errors.Is(err, modify.ErrGoTargetNotFound) {
+		// Handle missing targets the same way as missing destination paths
+		// (there is nothing to touch, so touch skips them too).
+		if t.Dst.Missing == MissingConfigError {
+			ctx.Logger.Failure("fail", t.Dst.RelativePath())
+			return err
+		}
+		ctx.Logger.Success("skip", "%s (%s not found)", t.Dst.RelativePath(), t.Match.Pattern())
+		return nil
+	}
+	if err != nil {
 		ctx.Logger.Failure("fail", t.Dst.RelativePath())
 		return err
 	}
 
 	// Log success.
-	updateMsg := t.Dst.RelativePath()
 	if desc != "" {
 		// Include custom, generator supplied description.
-		updateMsg = fmt.Sprintf("%s (%s)", t.Dst.RelativePath(), desc)
-	} else if t.Dst.ContentType().IsStructured() {
-		// Or the JSON path expression.
-		updateMsg = fmt.Sprintf("%s (%s)", t.Dst.RelativePath(), t.Match.Pattern())
+		ctx.Logger.Success("update", "%s (%s)", t.Dst.RelativePath(), desc)
+	} else if t.Dst.ContentType().IsStructured() || t.Dst.ContentType() == FileTypeXml || t.Match.targeted {
+		// Or the JSON path (XPath, heading path, Go target) expression.
+		ctx.Logger.Success("update", "%s (%s)", t.Dst.RelativePath(), t.Match.Pattern())
+	} else {
+		ctx.Logger.Success("update", t.Dst.RelativePath())
 	}
-	ctx.Logger.Success("update", updateMsg)
+	ctx.Logger.Diff(diff)
 
 	return nil
 }
 
-func (t *UpdateTask) updateDst(ctx *TaskContext) error {
-	if ctx.DryRun {
-		return nil
+// isPresent returns true if the guard pattern (or, for idempotent actions,
+// the src content) is already present in the destination.
+func (t *UpdateTask) isPresent(guard string) (bool, error) {
+	if guard == "" && (!t.Action.Idempotent || t.Action.Type == modify.ActionDelete) {
+		return false, nil
+	}
+	if t.Dst.ContentType().IsStructured() {
+		return t.isPresentStructured(guard)
+	}
+	if t.Dst.ContentType() == FileTypeXml && guard != "" {
+		return t.isPresentXML(guard)
+	}
+	if t.Dst.ContentType() == FileTypeMarkdown && guard != "" {
+		return t.isPresentMarkdown(guard)
+	}
+	if t.Dst.ContentType() == FileTypeGo && guard != "" {
+		return t.isPresentGo(guard)
+	}
+	return t.isPresentText(guard)
+}
+
+func (t *UpdateTask) isPresentStructured(guard string) (bool, error) {
+	data := t.Dst.Content()
+
+	if guard != "" {
+		exp, err := jp.ParseString(guard)
+		if err != nil {
+			return false, fmt.Errorf("unless present parse: %w", err)
+		}
+		return len(exp.Get(data)) > 0, nil
+	}
+
+	exp, err := jp.ParseString(t.Match.Pattern())
+	if err != nil {
+		return false, fmt.Errorf("json path parse: %w", err)
+	}
+	results := exp.Get(data)
+	if len(results) == 0 {
+		return false, nil
+	}
+	repl := t.Src.Content()
+	for _, result := range results {
+		if !modify.Contains(result, repl) {
+			return false, nil
+		}
+		// Replacing is only redundant if the content is identical.
+		if t.Action.Type == modify.ActionReplace && !modify.Contains(repl, result) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (t *UpdateTask) isPresentXML(guard string) (bool, error) {
+	doc, ok := t.Dst.Content().(*etree.Document)
+	if !ok {
+		return false, nil
+	}
+	path, attr := splitXMLPath(guard)
+	exp, err := etree.CompilePath(path)
+	if err != nil {
+		return false, fmt.Errorf("unless present parse: %w", err)
+	}
+	for _, e := range doc.FindElementsPath(exp) {
+		if attr == "" || e.SelectAttr(attr) != nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (t *UpdateTask) isPresentMarkdown(guard string) (bool, error) {
+	dstBytes, err := t.Dst.ContentBytes()
+	if err != nil {
+		return false, fmt.Errorf("dst bytes: %w", err)
+	}
+	found, err := modify.HasMarkdownSection(dstBytes, guard)
+	if err != nil {
+		return false, fmt.Errorf("unless present parse: %w", err)
+	}
+	return found, nil
+}
+
+func (t *UpdateTask) isPresentGo(guard string) (bool, error) {
+	dstBytes, err := t.Dst.ContentBytes()
+	if err != nil {
+		return false, fmt.Errorf("dst bytes: %w", err)
+	}
+	srcBytes, err := t.Src.ContentBytes()
+	if err != nil {
+		return false, fmt.Errorf("src bytes: %w", err)
+	}
+	found, err := modify.HasGoTarget(dstBytes, guard, srcBytes)
+	if err != nil {
+		return false, fmt.Errorf("unless present parse: %w", err)
+	}
+	return found, nil
+}
+
+func (t *UpdateTask) isPresentText(guard string) (bool, error) {
+	dstBytes, err := t.Dst.ContentBytes()
+	if err != nil {
+		return false, fmt.Errorf("dst bytes: %w", err)
+	}
+
+	if guard != "" {
+		re, err := regexp.Compile(guard)
+		if err != nil {
+			return false, fmt.Errorf("unless present pattern: %w", err)
+		}
+		return re.Match(dstBytes), nil
+	}
+
+	srcBytes, err := t.Src.ContentBytes()
+	if err != nil {
+		return false, fmt.Errorf("src bytes: %w", err)
+	}
+	if len(bytes.TrimSpace(srcBytes)) == 0 {
+		return false, nil
+	}
+
+	switch {
+	case t.Action.Type == modify.ActionReplace:
+		// Replacing is only redundant if it would not change anything.
+		updated, err := t.replaceText()
+		if err != nil {
+			return false, err
+		}
+		return bytes.Equal(trimLines(updated.([]byte)), trimLines(dstBytes)), nil
+	case t.Action.Type.IsInsert():
+		return t.isPresentInsert()
+	default:
+		// Ignore indentation.
+		return bytes.Contains(trimLines(dstBytes), trimLines(srcBytes)), nil
+	}
+}
+
+// isPresentInsert returns true if the src content is already
+// on the lines before (or after) each of the matching lines.
+func (t *UpdateTask) isPresentInsert() (bool, error) {
+	lines, anchors, err := t.insertAnchors()
+	if err != nil {
+		return false, err
+	}
+	if len(anchors) == 0 {
+		return false, nil
+	}
+	newline := []byte("\n")
+	for _, a := range anchors {
+		content := trimLines(a.content)
+		n := len(bytes.Split(content, newline))
+		start, end := a.line-n, a.line
+		if t.Action.Type == modify.ActionInsertAfter {
+			start, end = a.line+1, a.line+1+n
+		}
+		if start < 0 || end > len(lines) {
+			return false, nil
+		}
+		// Ignore indentation (inserted content is indented to match).
+		if !bytes.Equal(trimLines(bytes.Join(lines[start:end], newline)), content) {
+			return false, nil
+		}
 	}
+	return true, nil
+}
+
+// trimLines trims leading and trailing whitespace from each line.
+func trimLines(b []byte) []byte {
+	newline := []byte("\n")
+	lines := bytes.Split(bytes.TrimSpace(b), newline)
+	for i, line := range lines {
+		lines[i] = bytes.TrimSpace(line)
+	}
+	return bytes.Join(lines, newline)
+}
 
+// updateDst writes the updated content to the destination.
+// During a dry run, the destination is left untouched and a diff
+// of the pending changes is returned instead.
+func (t *UpdateTask) updateDst(ctx *TaskContext) (string, error) {
 	var updated any
 	var err error
 
-	if t.Dst.ContentType().IsStructured() {
+	switch {
+	case t.Dst.ContentType().IsStructured():
 		updated, err = t.replaceStructured()
-	} else {
+	case t.Dst.ContentType() == FileTypeXml:
+		updated, err = t.replaceXML()
+	case t.Match.targeted && t.Dst.ContentType() == FileTypeGo:
+		updated, err = t.replaceGo()
+	case t.Match.targeted:
+		updated, err = t.replaceMarkdown()
+	case t.Action.Type.IsInsert():
+		updated, err = t.insertText()
+	default:
 		updated, err = t.replaceText()
 	}
 	if err != nil {
-		return fmt.Errorf("update content: %w", err)
+		return "", fmt.Errorf("update content: %w", err)
+	}
+	// Go source is always formatted (targeted updates already are).
+	if buf, ok := updated.([]byte); ok && t.Dst.ContentType() == FileTypeGo {
+		if updated, err = format.Source(buf); err != nil {
+			return "", fmt.Errorf("update content: go format: %w", err)
+		}
 	}
 
-	if err := t.Dst.Write(updated); err != nil {
-		return fmt.Errorf("update content: %w", err)
+	// Patch (rather than re-encode) the existing content to preserve its formatting.
+	buf, err := t.Dst.PatchContent(updated)
+	if err != nil {
+		return "", fmt.Errorf("update content: %w", err)
 	}
 
-	return nil
+	if ctx.DryRun {
+		diff, err := t.Dst.diff(buf, false)
+		if err != nil {
+			return "", fmt.Errorf("update diff: %w", err)
+		}
+		return diff, nil
+	}
+
+	if err := ctx.Journal.Record(t.Dst.Path()); err != nil {
+		return "", fmt.Errorf("update content: %w", err)
+	}
+	if err := t.Dst.WriteBytes(buf); err != nil {
+		return "", fmt.Errorf("update content: %w", err)
+	}
+
+	return "", nil
 }
 
 func (t *UpdateTask) replaceStructured() (any, error) {
@@ -230,6 +563,10 @@ func (t *UpdateTask) replaceStructured() (any, error) {
 	pattern := t.Match.Pattern()
 	repl := t.Src.Content()
 
+	if t.Action.Type.IsInsert() {
+		return nil, fmt.Errorf("%s is only supported for text content", t.Action.Type)
+	}
+
 	// parse pattern as a JSON path expression
 	exp, err := jp.ParseString(pattern)
 	if err != nil {
@@ -259,22 +596,168 @@ func (t *UpdateTask) replaceStructured() (any, error) {
 	return data, nil
 }
 
-func (t *UpdateTask) replaceText() (any, error) {
+var xmlAttrPattern = regexp.MustCompile(`^(.*)/@([\w:.-]+)$`)
+
+// splitXMLPath splits an XPath expression into the element path
+// and (if the expression ends in "/@name") the attribute name.
+func splitXMLPath(pattern string) (string, string) {
+	if m := xmlAttrPattern.FindStringSubmatch(pattern); m != nil {
+		return m[1], m[2]
+	}
+	return pattern, ""
+}
+
+func (t *UpdateTask) replaceXML() (any, error) {
+	doc, ok := t.Dst.Content().(*etree.Document)
+	if !ok {
+		doc = etree.NewDocument()
+	}
+
+	if t.Action.Type.IsInsert() {
+		return nil, fmt.Errorf("%s is only supported for text content", t.Action.Type)
+	}
+
+	// parse pattern as an XPath expression
+	path, attr := splitXMLPath(t.Match.Pattern())
+	exp, err := etree.CompilePath(path)
+	if err != nil {
+		return nil, fmt.Errorf("xml path parse: %w", err)
+	}
+
+	srcBytes, err := t.Src.ContentBytes()
+	if err != nil {
+		return nil, fmt.Errorf("src bytes: %w", err)
+	}
+	conf := modify.ModifierConf{MergeType: t.Action.MergeType}
+
+	for _, e := range doc.FindElementsPath(exp) {
+		if attr != "" {
+			// Modify the attribute value as a string.
+			if t.Action.Type == modify.ActionDelete {
+				e.RemoveAttr(attr)
+				continue
+			}
+			value := modify.String(e.SelectAttrValue(attr, ""), t.Action.Type, string(srcBytes), conf)
+			e.CreateAttr(attr, value)
+			continue
+		}
+
+		tokens, err := parseXMLFragment(srcBytes)
+		if err != nil {
+			return nil, err
+		}
+		if len(tokens) == 0 && t.Action.Type != modify.ActionDelete {
+			// Modify the element text as a string.
+			e.SetText(modify.String(e.Text(), t.Action.Type, strings.TrimSpace(string(srcBytes)), conf))
+			continue
+		}
+		modify.XMLElement(e, t.Action.Type, tokens, conf)
+	}
+
+	return doc, nil
+}
+
+var xmlDeclPattern = regexp.MustCompile(`^\s*<\?xml[^>]*\?>`)
+
+// parseXMLFragment returns the elements and comments in src.
+func parseXMLFragment(src []byte) ([]etree.Token, error) {
+	src = xmlDeclPattern.ReplaceAll(src, nil)
+	doc := etree.NewDocument()
+	doc.ReadSettings.PreserveCData = true
+	if err := doc.ReadFromString("<fragment>" + string(src) + "</fragment>"); err != nil {
+		return nil, fmt.Errorf("src xml parse: %w", err)
+	}
+	tokens := []etree.Token{}
+	for _, token := range doc.Root().Child {
+		switch token.(type) {
+		case *etree.Element, *etree.Comment:
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+func (t *UpdateTask) replaceMarkdown() (any, error) {
+	if t.Action.Type.IsInsert() {
+		return nil, fmt.Errorf("%s is only supported for text content", t.Action.Type)
+	}
+
 	dstBytes, err := t.Dst.ContentBytes()
 	if err != nil {
 		return nil, fmt.Errorf("dst bytes: %w", err)
 	}
+	srcBytes, err := t.Src.ContentBytes()
+	if err != nil {
+		return nil, fmt.Errorf("src bytes: %w", err)
+	}
 
-	re, err := regexp.Compile(t.Match.Pattern())
+	// Create the section (and any missing parents) if needed.
+	pattern := t.Match.Pattern()
+	if t.Match.Default != nil && t.Action.Type != modify.ActionDelete {
+		body := []byte(cast.ToString(t.Match.Default))
+		dstBytes, err = modify.AddMarkdownSection(dstBytes, pattern, body)
+		if err != nil {
+			return nil, fmt.Errorf("heading path add: %w", err)
+		}
+	}
+
+	conf := modify.ModifierConf{MergeType: t.Action.MergeType}
+	updated, err := modify.MarkdownSection(dstBytes, pattern, t.Action.Type, srcBytes, conf)
 	if err != nil {
-		return nil, fmt.Errorf("match pattern: %w", err)
+		return nil, fmt.Errorf("heading path modify: %w", err)
+	}
+	return updated, nil
+}
+
+func (t *UpdateTask) replaceGo() (any, error) {
+	if t.Action.Type.IsInsert() {
+		return nil, fmt.Errorf("%s is only supported for text content", t.Action.Type)
 	}
 
+	dstBytes, err := t.Dst.ContentBytes()
+	if err != nil {
+		return nil, fmt.Errorf("dst bytes: %w", err)
+	}
 	srcBytes, err := t.Src.ContentBytes()
 	if err != nil {
 		return nil, fmt.Errorf("src bytes: %w", err)
 	}
 
+	conf := modify.ModifierConf{MergeType: t.Action.MergeType}
+	updated, err := modify.GoSource(dstBytes, t.Match.Pattern(), t.Action.Type, srcBytes, conf)
+	if err != nil {
+		return nil, fmt.Errorf("go target modify: %w", err)
+	}
+	return updated, nil
+}
+
+// textContent returns the dst content, match pattern, and src content
+// used to update text files.
+func (t *UpdateTask) textContent() ([]byte, *regexp.Regexp, []byte, error) {
+	dstBytes, err := t.Dst.ContentBytes()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("dst bytes: %w", err)
+	}
+
+	re, err := regexp.Compile(t.Match.Pattern())
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("match pattern: %w", err)
+	}
+
+	srcBytes, err := t.Src.ContentBytes()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("src bytes: %w", err)
+	}
+
+	return dstBytes, re, srcBytes, nil
+}
+
+func (t *UpdateTask) replaceText() (any, error) {
+	dstBytes, re, srcBytes, err := t.textContent()
+	if err != nil {
+		return nil, err
+	}
+
 	// Using this replacement func (as opposed to a simple call to `re.ReplaceAll`)
 	// because it allows us to use the `modify` package, and thus get the same
 	// merge behavior as when working with structured data.
@@ -308,3 +791,91 @@ func (t *UpdateTask) replaceText() (any, error) {
 	}
 	return bytes.Join(updatedLines, newline), nil
 }
+
+// insertText inserts the src content on its own line(s) before or after
+// the matching dst lines, indented to match each of them.
+func (t *UpdateTask) insertText() (any, error) {
+	lines, anchors, err := t.insertAnchors()
+	if err != nil {
+		return nil, err
+	}
+	newline := []byte("\n")
+
+	inserts := map[int][][]byte{}
+	for _, a := range anchors {
+		line := lines[a.line]
+		indent := line[:len(line)-len(bytes.TrimLeft(line, " \t"))]
+		for _, l := range bytes.Split(bytes.TrimSuffix(a.content, newline), newline) {
+			if len(bytes.TrimSpace(l)) > 0 {
+				l = append(append([]byte{}, indent...), l...)
+			}
+			inserts[a.line] = append(inserts[a.line], l)
+		}
+	}
+
+	updatedLines := [][]byte{}
+	for i, line := range lines {
+		if t.Action.Type == modify.ActionInsertBefore {
+			updatedLines = append(updatedLines, inserts[i]...)
+		}
+		updatedLines = append(updatedLines, line)
+		if t.Action.Type == modify.ActionInsertAfter {
+			updatedLines = append(updatedLines, inserts[i]...)
+		}
+	}
+	return bytes.Join(updatedLines, newline), nil
+}
+
+// insertAnchor is a dst line to insert content before (or after).
+type insertAnchor struct {
+	line    int
+	content []byte
+}
+
+// insertAnchors returns the dst lines, and the matching lines to insert
+// the src content at (the src content may contain capture group placeholders).
+func (t *UpdateTask) insertAnchors() ([][]byte, []insertAnchor, error) {
+	dstBytes, re, srcBytes, err := t.textContent()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newline := []byte("\n")
+	lines := bytes.Split(dstBytes, newline)
+
+	anchors := []insertAnchor{}
+	addAnchor := func(line int, subject []byte, match []int) {
+		if n := len(anchors); n > 0 && anchors[n-1].line == line {
+			return // only insert once per line
+		}
+		content := re.Expand([]byte{}, srcBytes, subject, match)
+		anchors = append(anchors, insertAnchor{line: line, content: content})
+	}
+	if t.Match.Source == MatchSourceFile {
+		for _, match := range re.FindAllSubmatchIndex(dstBytes, -1) {
+			// The line containing the start (or end) of the match.
+			pos := match[0]
+			if t.Action.Type == modify.ActionInsertAfter && match[1] > match[0] {
+				pos = match[1] - 1
+			}
+			addAnchor(bytes.Count(dstBytes[:pos], newline), dstBytes, match)
+		}
+	} else {
+		for i, line := range lines {
+			if match := re.FindSubmatchIndex(line); match != nil {
+				addAnchor(i, line, match)
+			}
+		}
+	}
+	if len(anchors) == 0 {
+		return lines, anchors, nil
+	}
+	switch t.Match.Occurrence {
+	case MatchOccurrenceFirst:
+		anchors = anchors[:1]
+	case MatchOccurrenceLast:
+		anchors = anchors[len(anchors)-1:]
+	default: // MatchOccurrenceAll
+	}
+	return lines, anchors, nil
+}
@@ -26,6 +26,19 @@ func TestNewTask_WhenTypeIsUpdate(t *testing.T) {
 			},
 			Err: "unknown is not a valid MissingConfig",
 		},
+		{
+			Name: "returns an error when occurrence field is invalid",
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "example.txt",
+				},
+				"match": map[string]any{
+					"occurrence": "second",
+				},
+			},
+			Err: "second is not a valid MatchOccurrence",
+		},
 		{
 			Name: "returns the task when all fields are valid",
 			TaskData: map[string]any{
@@ -61,6 +74,7 @@ func TestUpdateTask_Execute(t *testing.T) { //nolint: maintidx
 		Values     map[string]any
 		StartFiles map[string]any
 		EndFiles   map[string]any
+		Output     string
 		Setup      func(app *App)
 		Err        string
 	}{
@@ -338,6 +352,13 @@ func TestUpdateTask_Execute(t *testing.T) { //nolint: maintidx
 			EndFiles: map[string]any{
 				"README.md": "Hello World\n",
 			},
+			Output: "" +
+				"✓ [DRY RUN][    update]: README.md\n" +
+				"--- a/README.md\n" +
+				"+++ b/README.md\n" +
+				"@@ -1 +1 @@\n" +
+				"-Hello World\n" +
+				"+Goodbye World\n",
 		},
 		{
 			Desc: "updates a path using content from src field",
@@ -430,184 +451,179 @@ the
 		},
 
 		{
-			Desc: "prepends JSON data in dst",
+			Desc: "inserts before matching lines with matching indentation",
 			StartFiles: map[string]any{
-				"example.json": `{"foo":[1,2,3]}`,
+				"routes.go": `func routes(r *Router) {
+	r.Get("/", index)
+	// end routes
+}
+`,
 			},
 			TaskData: map[string]any{
 				"type": "update",
 				"dst": map[string]any{
-					"path": "example.json",
+					"path": "routes.go",
 				},
 				"match": map[string]any{
-					"pattern": "$.foo",
+					"pattern": `// end routes`,
 				},
 				"action": map[string]any{
-					"type": "prepend",
+					"type": "insert-before",
 				},
 				"src": map[string]any{
-					"content": []any{4, 5},
+					"content": "r.Get(\"/{{ .Name }}\", {{ .Name }})\nr.Post(\"/{{ .Name }}\", {{ .Name }})\n",
 				},
 			},
+			Values: map[string]any{
+				"Name": "users",
+			},
 			EndFiles: map[string]any{
-				"example.json": `{
-    "foo": [
-        4,
-        5,
-        1,
-        2,
-        3
-    ]
-}`,
+				"routes.go": `func routes(r *Router) {
+	r.Get("/", index)
+	r.Get("/users", users)
+	r.Post("/users", users)
+	// end routes
+}
+`,
 			},
 		},
 		{
-			Desc: "appends JSON data in dst",
+			Desc: "inserts after the last matching line",
 			StartFiles: map[string]any{
-				"example.json": `{"foo":[1,2,3]}`,
+				"index.ts": `import { a } from "./a";
+import { b } from "./b";
+
+  export { a, b };
+`,
 			},
 			TaskData: map[string]any{
 				"type": "update",
 				"dst": map[string]any{
-					"path": "example.json",
+					"path": "index.ts",
 				},
 				"match": map[string]any{
-					"pattern": "$.foo",
+					"pattern":    `^import `,
+					"occurrence": "last",
 				},
 				"action": map[string]any{
-					"type": "append",
+					"type": "insert-after",
 				},
 				"src": map[string]any{
-					"content": []any{4, 5},
+					"content": `import { c } from "./c";`,
 				},
 			},
 			EndFiles: map[string]any{
-				"example.json": `{
-    "foo": [
-        1,
-        2,
-        3,
-        4,
-        5
-    ]
-}`,
+				"index.ts": `import { a } from "./a";
+import { b } from "./b";
+import { c } from "./c";
+
+  export { a, b };
+`,
 			},
 		},
 		{
-			Desc: "replaces JSON data in dst",
+			Desc: "inserts next to the first matching line",
 			StartFiles: map[string]any{
-				"example.json": `{"foo":[1,2,3]}`,
+				"list.txt": "  - foo\n  - bar\n",
 			},
 			TaskData: map[string]any{
 				"type": "update",
 				"dst": map[string]any{
-					"path": "example.json",
+					"path": "list.txt",
 				},
 				"match": map[string]any{
-					"pattern": "$.foo",
+					"pattern":    `- (\w+)`,
+					"occurrence": "first",
 				},
 				"action": map[string]any{
-					"type": "replace",
+					"type": "insert-after",
 				},
 				"src": map[string]any{
-					"content": []any{4, 5},
+					"content": "- ${1}!",
 				},
 			},
 			EndFiles: map[string]any{
-				"example.json": `{
-    "foo": [
-        4,
-        5
-    ]
-}`,
+				"list.txt": "  - foo\n  - foo!\n  - bar\n",
 			},
 		},
 		{
-			Desc: "deletes JSON data in dst",
+			Desc: "inserts next to every matching line by default",
 			StartFiles: map[string]any{
-				"example.json": `{"foo":[1,2,3]}`,
+				"list.txt": "  - foo\n  - bar\n",
 			},
 			TaskData: map[string]any{
 				"type": "update",
 				"dst": map[string]any{
-					"path": "example.json",
+					"path": "list.txt",
 				},
 				"match": map[string]any{
-					"pattern": "$.foo",
+					"pattern": `- (\w+)`,
 				},
 				"action": map[string]any{
-					"type": "delete",
+					"type": "insert-before",
+				},
+				"src": map[string]any{
+					"content": "- ${1}!",
 				},
 			},
 			EndFiles: map[string]any{
-				"example.json": `{}`,
+				"list.txt": "  - foo!\n  - foo\n  - bar!\n  - bar\n",
 			},
 		},
 		{
-			Desc: "matches root element if pattern evaluates to empty string",
+			Desc: "inserts next to multiline matches if configured",
 			StartFiles: map[string]any{
-				"example.json": `{"foo":[1,2,3]}`,
+				"main.go": "package main\n\nimport (\n\t\"fmt\"\n)\n",
 			},
 			TaskData: map[string]any{
 				"type": "update",
 				"dst": map[string]any{
-					"path": "example.json",
+					"path": "main.go",
+				},
+				"match": map[string]any{
+					"pattern":    `(?s)import \(.*?"fmt"`,
+					"source":     "file",
+					"occurrence": "first",
 				},
 				"action": map[string]any{
-					"type": "replace",
+					"type": "insert-after",
 				},
 				"src": map[string]any{
-					"content": map[string]any{
-						"bar": true,
-					},
+					"content": `"os"`,
 				},
 			},
 			EndFiles: map[string]any{
-				"example.json": `{
-    "bar": true
-}`,
+				"main.go": "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n",
 			},
 		},
 		{
-			Desc: "parses src path content before updating",
+			Desc: "does not insert when nothing matches",
 			StartFiles: map[string]any{
-				"example.json": `{"foo":[1,2,3]}`,
+				"list.txt": "foo\n",
 			},
 			TaskData: map[string]any{
 				"type": "update",
 				"dst": map[string]any{
-					"path": "example.json",
-				},
-				"action": map[string]any{
-					"type": "append",
+					"path": "list.txt",
 				},
 				"match": map[string]any{
-					"pattern": "$.foo",
+					"pattern": `bar`,
+				},
+				"action": map[string]any{
+					"type": "insert-after",
 				},
 				"src": map[string]any{
-					"path": "valid.json",
+					"content": "baz",
 				},
 			},
-			Values: map[string]any{
-				"SrcPath": srcPath,
-			},
 			EndFiles: map[string]any{
-				"example.json": `{
-    "foo": [
-        1,
-        2,
-        3,
-        "aaa",
-        "bbb",
-        "ccc"
-    ]
-}`,
+				"list.txt": "foo\n",
 			},
 		},
 		{
-			Desc: "ensures pattern default before updating",
+			Desc: "returns an error when inserting into structured data",
 			StartFiles: map[string]any{
-				"example.json": `{}`,
+				"example.json": `{"foo":[1,2,3]}`,
 			},
 			TaskData: map[string]any{
 				"type": "update",
@@ -615,213 +631,2177 @@ the
 					"path": "example.json",
 				},
 				"action": map[string]any{
-					"type": "append",
-				},
-				"match": map[string]any{
-					"pattern": "$.foo",
-					"default": []any{},
+					"type": "insert-after",
 				},
 				"src": map[string]any{
-					"path": "valid.json",
+					"content": "4",
 				},
 			},
-			Values: map[string]any{
-				"SrcPath": srcPath,
+			Err: "insert-after is only supported for text content",
+		},
+
+		{
+			Desc: "does not append text already present when idempotent",
+			StartFiles: map[string]any{
+				"README.md": "# Title\n\nCopyright Acme\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "README.md",
+				},
+				"action": map[string]any{
+					"type":       "append",
+					"idempotent": true,
+				},
+				"src": map[string]any{
+					"content": "Copyright Acme\n",
+				},
 			},
 			EndFiles: map[string]any{
-				"example.json": `{
-    "foo": [
-        "aaa",
-        "bbb",
-        "ccc"
-    ]
-}`,
+				"README.md": "# Title\n\nCopyright Acme\n",
 			},
 		},
 		{
-			Desc: "does not append duplicate items when array_mode is upsert",
+			Desc: "appends text not yet present when idempotent",
 			StartFiles: map[string]any{
-				"example.json": `{"foo": ["aaa"]}`,
+				"README.md": "# Title\n",
 			},
 			TaskData: map[string]any{
 				"type": "update",
 				"dst": map[string]any{
-					"path": "example.json",
-				},
-				"match": map[string]any{
-					"pattern": "$.foo",
+					"path": "README.md",
 				},
 				"action": map[string]any{
-					"type":  "append",
-					"merge": "upsert",
+					"type":       "append",
+					"idempotent": true,
 				},
 				"src": map[string]any{
-					"content": []any{
-						"aaa",
-						"bbb",
-						"ccc",
-					},
+					"content": "Copyright Acme\n",
 				},
 			},
 			EndFiles: map[string]any{
-				"example.json": `{
-    "foo": [
-        "aaa",
-        "bbb",
-        "ccc"
-    ]
-}`,
+				"README.md": "# Title\nCopyright Acme\n",
 			},
 		},
-
 		{
-			Desc: "prepends YAML data in dst",
+			Desc: "does not insert indented text already present when idempotent",
 			StartFiles: map[string]any{
-				"example.yml": "foo: [1,2,3]\n",
+				"main.go": "import (\n\t\"fmt\"\n\t\"os\"\n)\n",
 			},
 			TaskData: map[string]any{
 				"type": "update",
 				"dst": map[string]any{
-					"path": "example.yml",
+					"path": "main.go",
 				},
 				"match": map[string]any{
-					"pattern": "$.foo",
+					"pattern": `"fmt"`,
 				},
 				"action": map[string]any{
-					"type": "prepend",
+					"type":       "insert-after",
+					"idempotent": true,
 				},
 				"src": map[string]any{
-					"content": []any{4, 5},
+					"content": `"os"`,
 				},
 			},
 			EndFiles: map[string]any{
-				"example.yml": "foo:\n" +
-					"  - 4\n" +
-					"  - 5\n" +
-					"  - 1\n" +
-					"  - 2\n" +
-					"  - 3\n",
+				"main.go": "import (\n\t\"fmt\"\n\t\"os\"\n)\n",
 			},
 		},
 		{
-			Desc: "appends YAML data in dst",
+			Desc: "inserts text present elsewhere in the file when idempotent",
 			StartFiles: map[string]any{
-				"example.yml": "foo: [1,2,3]\n",
+				"main.go": "import (\n\t\"fmt\"\n)\n\n// \"os\"\n",
 			},
 			TaskData: map[string]any{
 				"type": "update",
 				"dst": map[string]any{
-					"path": "example.yml",
+					"path": "main.go",
 				},
 				"match": map[string]any{
-					"pattern": "$.foo",
+					"pattern": `"fmt"`,
 				},
 				"action": map[string]any{
-					"type": "append",
+					"type":       "insert-after",
+					"idempotent": true,
 				},
 				"src": map[string]any{
-					"content": []any{4, 5},
+					"content": `"os"`,
 				},
 			},
 			EndFiles: map[string]any{
-				"example.yml": "foo:\n" +
-					"  - 1\n" +
-					"  - 2\n" +
-					"  - 3\n" +
-					"  - 4\n" +
-					"  - 5\n",
+				"main.go": "import (\n\t\"fmt\"\n\t\"os\"\n)\n\n// \"os\"\n",
 			},
 		},
 		{
-			Desc: "replaces YAML data in dst",
+			Desc: "does not replace identical text when idempotent",
 			StartFiles: map[string]any{
-				"example.yml": "foo: [1,2,3]\n",
+				"LICENSE": "Copyright Acme\n",
 			},
 			TaskData: map[string]any{
 				"type": "update",
 				"dst": map[string]any{
-					"path": "example.yml",
+					"path": "LICENSE",
 				},
-				"match": map[string]any{
-					"pattern": "$.foo",
+				"action": map[string]any{
+					"type":       "replace",
+					"idempotent": true,
+				},
+				"src": map[string]any{
+					"content": "Copyright Acme\n",
+				},
+			},
+			EndFiles: map[string]any{
+				"LICENSE": "Copyright Acme\n",
+			},
+			Output: "✓ [      skip]: LICENSE (already present)\n",
+		},
+		{
+			Desc: "replaces text that merely contains src when idempotent",
+			StartFiles: map[string]any{
+				"LICENSE": "Copyright Acme\n\nAll rights reserved.\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "LICENSE",
 				},
 				"action": map[string]any{
-					"type": "replace",
+					"type":       "replace",
+					"idempotent": true,
 				},
 				"src": map[string]any{
-					"content": []any{4, 5},
+					"content": "Copyright Acme\n",
 				},
 			},
 			EndFiles: map[string]any{
-				"example.yml": "foo:\n" +
-					"  - 4\n" +
-					"  - 5\n",
+				"LICENSE": "Copyright Acme\n",
 			},
+			Output: "✓ [    update]: LICENSE\n",
 		},
 		{
-			Desc: "deletes YAML data in dst",
+			Desc: "does not treat empty src as present when idempotent",
 			StartFiles: map[string]any{
-				"example.yml": "foo: [1,2,3]\n",
+				"README.md": "# Title\n",
 			},
 			TaskData: map[string]any{
 				"type": "update",
 				"dst": map[string]any{
-					"path": "example.yml",
+					"path": "README.md",
 				},
-				"match": map[string]any{
-					"pattern": "$.foo",
+				"action": map[string]any{
+					"type":       "replace",
+					"idempotent": true,
+				},
+				"src": map[string]any{
+					"content": "{{ .Empty }}",
+				},
+			},
+			Values: map[string]any{
+				"Empty": "",
+			},
+			EndFiles: map[string]any{
+				"README.md": "",
+			},
+			Output: "✓ [    update]: README.md\n",
+		},
+		{
+			Desc: "does not update text when the unless_present pattern matches",
+			StartFiles: map[string]any{
+				"README.md": "# Title\n\nCopyright 2020 Acme\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "README.md",
 				},
 				"action": map[string]any{
-					"type": "delete",
+					"type":           "append",
+					"unless_present": `Copyright \d+ {{ .Owner }}`,
+				},
+				"src": map[string]any{
+					"content": "Copyright 2024 {{ .Owner }}\n",
 				},
 			},
+			Values: map[string]any{
+				"DstPath": ".",
+				"Owner":   "Acme",
+			},
 			EndFiles: map[string]any{
-				"example.yml": "{}\n",
+				"README.md": "# Title\n\nCopyright 2020 Acme\n",
 			},
 		},
-
 		{
-			Desc: "[missing:ignore] ignores missing paths",
+			Desc: "returns an error when the unless_present pattern is invalid",
+			StartFiles: map[string]any{
+				"README.md": "# Title\n",
+			},
 			TaskData: map[string]any{
 				"type": "update",
 				"dst": map[string]any{
 					"path": "README.md",
 				},
-				"match": map[string]any{
-					"pattern": "foo",
+				"action": map[string]any{
+					"type":           "append",
+					"unless_present": `(`,
+				},
+				"src": map[string]any{
+					"content": "foo",
 				},
 			},
 			EndFiles: map[string]any{
-				"README.md": false,
+				"README.md": "# Title\n",
 			},
+			Err: "unless present pattern",
 		},
 		{
-			Desc: "[missing:error] returns an error when path is missing",
+			Desc: "does not append JSON data already present when idempotent",
+			StartFiles: map[string]any{
+				"package.json": `{"dependencies":{"lodash":"4.17.21","react":"18.2.0"}}`,
+			},
 			TaskData: map[string]any{
 				"type": "update",
 				"dst": map[string]any{
-					"path":    "README.md",
-					"missing": "error",
+					"path": "package.json",
 				},
 				"match": map[string]any{
-					"pattern": "foo",
+					"pattern": "$.dependencies",
+				},
+				"action": map[string]any{
+					"type":       "append",
+					"idempotent": true,
+				},
+				"src": map[string]any{
+					"content": map[string]any{"lodash": "4.17.21"},
 				},
 			},
 			EndFiles: map[string]any{
-				"README.md": false,
+				"package.json": `{"dependencies":{"lodash":"4.17.21","react":"18.2.0"}}`,
 			},
-			Err: "path not found",
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.Desc, func(t *testing.T) {
-			testutil.InTempDir(t, func(tmpDir string) {
-				// Populate the temp dir w/ any initial files
-				testutil.WritePaths(t, tmpDir, tt.StartFiles)
-
-				// Setup the app.
-				app := NewTestApp()
-				app.Config.DryRun = tt.DryRun
+		{
+			Desc: "appends JSON data not yet present when idempotent",
+			StartFiles: map[string]any{
+				"example.json": `{
+    "foo": [
+        1,
+        2,
+        3
+    ]
+}`,
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "example.json",
+				},
+				"match": map[string]any{
+					"pattern": "$.foo",
+				},
+				"action": map[string]any{
+					"type":       "append",
+					"idempotent": true,
+				},
+				"src": map[string]any{
+					"content": []any{3, 4},
+				},
+			},
+			EndFiles: map[string]any{
+				"example.json": `{
+    "foo": [
+        1,
+        2,
+        3,
+        3,
+        4
+    ]
+}`,
+			},
+		},
+		{
+			Desc: "replaces JSON data that is not identical when idempotent",
+			StartFiles: map[string]any{
+				"example.json": `{
+    "foo": {
+        "a": 1,
+        "b": 2
+    }
+}`,
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "example.json",
+				},
+				"match": map[string]any{
+					"pattern": "$.foo",
+				},
+				"action": map[string]any{
+					"type":       "replace",
+					"idempotent": true,
+				},
+				"src": map[string]any{
+					"content": map[string]any{"a": 1},
+				},
+			},
+			EndFiles: map[string]any{
+				"example.json": `{
+    "foo": {
+        "a": 1
+    }
+}`,
+			},
+		},
+		{
+			Desc: "does not update JSON data when the unless_present path matches",
+			StartFiles: map[string]any{
+				"example.json": `{"foo":[{"name":"a"}]}`,
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "example.json",
+				},
+				"match": map[string]any{
+					"pattern": "$.foo",
+				},
+				"action": map[string]any{
+					"type":           "append",
+					"unless_present": `$.foo[?(@.name == 'a')]`,
+				},
+				"src": map[string]any{
+					"content": map[string]any{"name": "a", "version": 2},
+				},
+			},
+			EndFiles: map[string]any{
+				"example.json": `{"foo":[{"name":"a"}]}`,
+			},
+		},
+
+		{
+			Desc: "preserves key order, indentation, and trailing newline of JSON data in dst",
+			StartFiles: map[string]any{
+				"package.json": "{\n" +
+					"  \"name\": \"example\",\n" +
+					"  \"dependencies\": {\n" +
+					"    \"react\": \"18.2.0\"\n" +
+					"  },\n" +
+					"  \"scripts\": {}\n" +
+					"}\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "package.json",
+				},
+				"match": map[string]any{
+					"pattern": "$.dependencies",
+				},
+				"action": map[string]any{
+					"type": "append",
+				},
+				"src": map[string]any{
+					"content": map[string]any{"lodash": "4.17.21"},
+				},
+			},
+			EndFiles: map[string]any{
+				"package.json": "{\n" +
+					"  \"name\": \"example\",\n" +
+					"  \"dependencies\": {\n" +
+					"    \"react\": \"18.2.0\",\n" +
+					"    \"lodash\": \"4.17.21\"\n" +
+					"  },\n" +
+					"  \"scripts\": {}\n" +
+					"}\n",
+			},
+		},
+		{
+			Desc: "preserves comments and trailing commas of JSONC data in dst",
+			StartFiles: map[string]any{
+				"tsconfig.json": "{\n" +
+					"\t// Compiler options\n" +
+					"\t\"compilerOptions\": {\n" +
+					"\t\t\"strict\": true, // always\n" +
+					"\t\t\"lib\": [\"es2020\",],\n" +
+					"\t},\n" +
+					"}\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "tsconfig.json",
+				},
+				"match": map[string]any{
+					"pattern": "$.compilerOptions.lib",
+				},
+				"action": map[string]any{
+					"type": "append",
+				},
+				"src": map[string]any{
+					"content": []any{"dom"},
+				},
+			},
+			EndFiles: map[string]any{
+				"tsconfig.json": "{\n" +
+					"\t// Compiler options\n" +
+					"\t\"compilerOptions\": {\n" +
+					"\t\t\"strict\": true, // always\n" +
+					"\t\t\"lib\": [\"es2020\", \"dom\",],\n" +
+					"\t},\n" +
+					"}\n",
+			},
+		},
+		{
+			Desc: "preserves compact JSON data in dst",
+			StartFiles: map[string]any{
+				"example.json": `{"foo":[1,2,3],"bar":{}}`,
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "example.json",
+				},
+				"match": map[string]any{
+					"pattern": "$.bar",
+				},
+				"action": map[string]any{
+					"type": "append",
+				},
+				"src": map[string]any{
+					"content": map[string]any{"baz": []any{4}},
+				},
+			},
+			EndFiles: map[string]any{
+				"example.json": `{"foo":[1,2,3],"bar":{"baz":[4]}}`,
+			},
+		},
+		{
+			Desc: "prepends JSON data in dst",
+			StartFiles: map[string]any{
+				"example.json": `{
+    "foo": [
+        1,
+        2,
+        3
+    ]
+}`,
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "example.json",
+				},
+				"match": map[string]any{
+					"pattern": "$.foo",
+				},
+				"action": map[string]any{
+					"type": "prepend",
+				},
+				"src": map[string]any{
+					"content": []any{4, 5},
+				},
+			},
+			EndFiles: map[string]any{
+				"example.json": `{
+    "foo": [
+        4,
+        5,
+        1,
+        2,
+        3
+    ]
+}`,
+			},
+		},
+		{
+			Desc: "appends JSON data in dst",
+			StartFiles: map[string]any{
+				"example.json": `{
+    "foo": [
+        1,
+        2,
+        3
+    ]
+}`,
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "example.json",
+				},
+				"match": map[string]any{
+					"pattern": "$.foo",
+				},
+				"action": map[string]any{
+					"type": "append",
+				},
+				"src": map[string]any{
+					"content": []any{4, 5},
+				},
+			},
+			EndFiles: map[string]any{
+				"example.json": `{
+    "foo": [
+        1,
+        2,
+        3,
+        4,
+        5
+    ]
+}`,
+			},
+		},
+		{
+			Desc: "replaces JSON data in dst",
+			StartFiles: map[string]any{
+				"example.json": `{
+    "foo": [
+        1,
+        2,
+        3
+    ]
+}`,
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "example.json",
+				},
+				"match": map[string]any{
+					"pattern": "$.foo",
+				},
+				"action": map[string]any{
+					"type": "replace",
+				},
+				"src": map[string]any{
+					"content": []any{4, 5},
+				},
+			},
+			EndFiles: map[string]any{
+				"example.json": `{
+    "foo": [
+        4,
+        5
+    ]
+}`,
+			},
+		},
+		{
+			Desc: "deletes JSON data in dst",
+			StartFiles: map[string]any{
+				"example.json": `{"foo":[1,2,3]}`,
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "example.json",
+				},
+				"match": map[string]any{
+					"pattern": "$.foo",
+				},
+				"action": map[string]any{
+					"type": "delete",
+				},
+			},
+			EndFiles: map[string]any{
+				"example.json": `{}`,
+			},
+		},
+		{
+			Desc: "matches root element if pattern evaluates to empty string",
+			StartFiles: map[string]any{
+				"example.json": `{
+    "foo": [
+        1,
+        2,
+        3
+    ]
+}`,
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "example.json",
+				},
+				"action": map[string]any{
+					"type": "replace",
+				},
+				"src": map[string]any{
+					"content": map[string]any{
+						"bar": true,
+					},
+				},
+			},
+			EndFiles: map[string]any{
+				"example.json": `{
+    "bar": true
+}`,
+			},
+		},
+		{
+			Desc: "parses src path content before updating",
+			StartFiles: map[string]any{
+				"example.json": `{
+    "foo": [
+        1,
+        2,
+        3
+    ]
+}`,
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "example.json",
+				},
+				"action": map[string]any{
+					"type": "append",
+				},
+				"match": map[string]any{
+					"pattern": "$.foo",
+				},
+				"src": map[string]any{
+					"path": "valid.json",
+				},
+			},
+			Values: map[string]any{
+				"SrcPath": srcPath,
+			},
+			EndFiles: map[string]any{
+				"example.json": `{
+    "foo": [
+        1,
+        2,
+        3,
+        "aaa",
+        "bbb",
+        "ccc"
+    ]
+}`,
+			},
+		},
+		{
+			Desc: "ensures pattern default before updating",
+			StartFiles: map[string]any{
+				"example.json": `{}`,
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "example.json",
+				},
+				"action": map[string]any{
+					"type": "append",
+				},
+				"match": map[string]any{
+					"pattern": "$.foo",
+					"default": []any{},
+				},
+				"src": map[string]any{
+					"path": "valid.json",
+				},
+			},
+			Values: map[string]any{
+				"SrcPath": srcPath,
+			},
+			EndFiles: map[string]any{
+				"example.json": `{
+    "foo": [
+        "aaa",
+        "bbb",
+        "ccc"
+    ]
+}`,
+			},
+		},
+		{
+			Desc: "does not append duplicate items when array_mode is upsert",
+			StartFiles: map[string]any{
+				"example.json": `{
+    "foo": [
+        "aaa"
+    ]
+}`,
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "example.json",
+				},
+				"match": map[string]any{
+					"pattern": "$.foo",
+				},
+				"action": map[string]any{
+					"type":  "append",
+					"merge": "upsert",
+				},
+				"src": map[string]any{
+					"content": []any{
+						"aaa",
+						"bbb",
+						"ccc",
+					},
+				},
+			},
+			EndFiles: map[string]any{
+				"example.json": `{
+    "foo": [
+        "aaa",
+        "bbb",
+        "ccc"
+    ]
+}`,
+			},
+		},
+
+		{
+			Desc: "prepends YAML data in dst",
+			StartFiles: map[string]any{
+				"example.yml": "foo: [1,2,3]\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "example.yml",
+				},
+				"match": map[string]any{
+					"pattern": "$.foo",
+				},
+				"action": map[string]any{
+					"type": "prepend",
+				},
+				"src": map[string]any{
+					"content": []any{4, 5},
+				},
+			},
+			EndFiles: map[string]any{
+				"example.yml": "foo: [4, 5, 1, 2, 3]\n",
+			},
+		},
+		{
+			Desc: "appends YAML data in dst",
+			StartFiles: map[string]any{
+				"example.yml": "foo: [1,2,3]\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "example.yml",
+				},
+				"match": map[string]any{
+					"pattern": "$.foo",
+				},
+				"action": map[string]any{
+					"type": "append",
+				},
+				"src": map[string]any{
+					"content": []any{4, 5},
+				},
+			},
+			EndFiles: map[string]any{
+				"example.yml": "foo: [1, 2, 3, 4, 5]\n",
+			},
+		},
+		{
+			Desc: "replaces YAML data in dst",
+			StartFiles: map[string]any{
+				"example.yml": "foo: [1,2,3]\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "example.yml",
+				},
+				"match": map[string]any{
+					"pattern": "$.foo",
+				},
+				"action": map[string]any{
+					"type": "replace",
+				},
+				"src": map[string]any{
+					"content": []any{4, 5},
+				},
+			},
+			EndFiles: map[string]any{
+				"example.yml": "foo: [4, 5]\n",
+			},
+		},
+		{
+			Desc: "deletes YAML data in dst",
+			StartFiles: map[string]any{
+				"example.yml": "foo: [1,2,3]\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "example.yml",
+				},
+				"match": map[string]any{
+					"pattern": "$.foo",
+				},
+				"action": map[string]any{
+					"type": "delete",
+				},
+			},
+			EndFiles: map[string]any{
+				"example.yml": "{}\n",
+			},
+		},
+		{
+			Desc: "preserves comments, key order, and quoting of YAML data in dst",
+			StartFiles: map[string]any{
+				"compose.yml": "# Services\n" +
+					"services:\n" +
+					"  web:\n" +
+					"    image: \"nginx:1.0\" # pinned\n" +
+					"    ports:\n" +
+					"      - \"80:80\"\n" +
+					"  db:\n" +
+					"    image: 'postgres'\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "compose.yml",
+				},
+				"match": map[string]any{
+					"pattern": "$.services.web.image",
+				},
+				"src": map[string]any{
+					"content": "nginx:2.0",
+				},
+			},
+			EndFiles: map[string]any{
+				"compose.yml": "# Services\n" +
+					"services:\n" +
+					"  web:\n" +
+					"    image: \"nginx:2.0\" # pinned\n" +
+					"    ports:\n" +
+					"      - \"80:80\"\n" +
+					"  db:\n" +
+					"    image: 'postgres'\n",
+			},
+		},
+		{
+			Desc: "preserves anchors and flow style of YAML data in dst",
+			StartFiles: map[string]any{
+				"values.yaml": "defaults: &defaults\n" +
+					"  replicas: 1\n" +
+					"app:\n" +
+					"  <<: *defaults\n" +
+					"  tags: [a, b]\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "values.yaml",
+				},
+				"match": map[string]any{
+					"pattern": "$.app.tags",
+				},
+				"action": map[string]any{
+					"type": "append",
+				},
+				"src": map[string]any{
+					"content": []any{"c"},
+				},
+			},
+			EndFiles: map[string]any{
+				"values.yaml": "defaults: &defaults\n" +
+					"  replicas: 1\n" +
+					"app:\n" +
+					"  <<: *defaults\n" +
+					"  tags: [a, b, c]\n",
+			},
+		},
+		{
+			Desc: "preserves the indentation of YAML data in dst",
+			StartFiles: map[string]any{
+				"example.yml": "foo:\n" +
+					"    # The bar\n" +
+					"    bar: 1\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "example.yml",
+				},
+				"match": map[string]any{
+					"pattern": "$.foo",
+				},
+				"action": map[string]any{
+					"type": "append",
+				},
+				"src": map[string]any{
+					"content": map[string]any{"baz": map[string]any{"qux": 2}},
+				},
+			},
+			EndFiles: map[string]any{
+				"example.yml": "foo:\n" +
+					"    # The bar\n" +
+					"    bar: 1\n" +
+					"    baz:\n" +
+					"        qux: 2\n",
+			},
+		},
+
+		{
+			Desc: "appends TOML data in dst",
+			StartFiles: map[string]any{
+				"pyproject.toml": "[project]\n" +
+					"name = \"example\"\n" +
+					"dependencies = [\"requests\"]\n" +
+					"\n" +
+					"[build-system]\n" +
+					"requires = [\"hatchling\"]\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "pyproject.toml",
+				},
+				"match": map[string]any{
+					"pattern": "$.project.dependencies",
+				},
+				"action": map[string]any{
+					"type": "append",
+				},
+				"src": map[string]any{
+					"content": []any{"rich"},
+				},
+			},
+			EndFiles: map[string]any{
+				"pyproject.toml": "[project]\n" +
+					"name = \"example\"\n" +
+					"dependencies = [\"requests\", \"rich\"]\n" +
+					"\n" +
+					"[build-system]\n" +
+					"requires = [\"hatchling\"]\n",
+			},
+		},
+		{
+			Desc: "keeps the table order of TOML data in dst",
+			StartFiles: map[string]any{
+				"Cargo.toml": "[package]\n" +
+					"name = 'example'\n" +
+					"\n" +
+					"[dependencies]\n" +
+					"serde = '1.0'\n" +
+					"\n" +
+					"[[bin]]\n" +
+					"name = 'a'\n" +
+					"\n" +
+					"[[bin]]\n" +
+					"name = 'b'\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "Cargo.toml",
+				},
+				"match": map[string]any{
+					"pattern": "$",
+				},
+				"action": map[string]any{
+					"type": "append",
+				},
+				"src": map[string]any{
+					"content": map[string]any{
+						"dependencies": map[string]any{"anyhow": "1.0"},
+						"features":     map[string]any{"default": []any{}},
+					},
+				},
+			},
+			EndFiles: map[string]any{
+				"Cargo.toml": "[package]\n" +
+					"name = 'example'\n" +
+					"\n" +
+					"[dependencies]\n" +
+					"serde = '1.0'\n" +
+					"anyhow = '1.0'\n" +
+					"\n" +
+					"[[bin]]\n" +
+					"name = 'a'\n" +
+					"\n" +
+					"[[bin]]\n" +
+					"name = 'b'\n" +
+					"\n" +
+					"[features]\n" +
+					"default = []\n",
+			},
+		},
+		{
+			Desc: "preserves comments, table order, and quoting of TOML data in dst",
+			StartFiles: map[string]any{
+				"Cargo.toml": "# Package metadata\n" +
+					"[package]\n" +
+					"name = \"example\" # the crate name\n" +
+					"version = \"0.1.0\"\n" +
+					"\n" +
+					"# Runtime dependencies\n" +
+					"[dependencies]\n" +
+					"serde = \"1.0\"\n" +
+					"\n" +
+					"[build-dependencies]\n" +
+					"cc = '1.0'\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "Cargo.toml",
+				},
+				"match": map[string]any{
+					"pattern": "$",
+				},
+				"action": map[string]any{
+					"type": "append",
+				},
+				"src": map[string]any{
+					"content": map[string]any{
+						"package":      map[string]any{"version": "0.2.0"},
+						"dependencies": map[string]any{"anyhow": "1.0"},
+					},
+				},
+			},
+			EndFiles: map[string]any{
+				"Cargo.toml": "# Package metadata\n" +
+					"[package]\n" +
+					"name = \"example\" # the crate name\n" +
+					"version = \"0.2.0\"\n" +
+					"\n" +
+					"# Runtime dependencies\n" +
+					"[dependencies]\n" +
+					"serde = \"1.0\"\n" +
+					"anyhow = \"1.0\"\n" +
+					"\n" +
+					"[build-dependencies]\n" +
+					"cc = '1.0'\n",
+			},
+		},
+		{
+			Desc: "deletes TOML data in dst",
+			StartFiles: map[string]any{
+				".golangci.toml": "[run]\n" +
+					"timeout = '5m'\n" +
+					"\n" +
+					"[linters]\n" +
+					"enable = ['errcheck']\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": ".golangci.toml",
+				},
+				"match": map[string]any{
+					"pattern": "$.run",
+				},
+				"action": map[string]any{
+					"type": "delete",
+				},
+			},
+			EndFiles: map[string]any{
+				".golangci.toml": "[linters]\n" +
+					"enable = ['errcheck']\n",
+			},
+		},
+
+		{
+			Desc: "appends XML elements in dst",
+			StartFiles: map[string]any{
+				"pom.xml": "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+					"<project xmlns=\"http://maven.apache.org/POM/4.0.0\">\n" +
+					"    <!-- Project dependencies -->\n" +
+					"    <dependencies>\n" +
+					"        <dependency>\n" +
+					"            <groupId>junit</groupId>\n" +
+					"        </dependency>\n" +
+					"    </dependencies>\n" +
+					"</project>\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "pom.xml",
+				},
+				"match": map[string]any{
+					"pattern": "/project/dependencies",
+				},
+				"action": map[string]any{
+					"type": "append",
+				},
+				"src": map[string]any{
+					"content": "<dependency>\n" +
+						"    <groupId>{{ .Group }}</groupId>\n" +
+						"</dependency>\n",
+				},
+			},
+			Values: map[string]any{
+				"Group": "org.slf4j",
+			},
+			EndFiles: map[string]any{
+				"pom.xml": "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+					"<project xmlns=\"http://maven.apache.org/POM/4.0.0\">\n" +
+					"    <!-- Project dependencies -->\n" +
+					"    <dependencies>\n" +
+					"        <dependency>\n" +
+					"            <groupId>junit</groupId>\n" +
+					"        </dependency>\n" +
+					"        <dependency>\n" +
+					"            <groupId>org.slf4j</groupId>\n" +
+					"        </dependency>\n" +
+					"    </dependencies>\n" +
+					"</project>\n",
+			},
+		},
+		{
+			Desc: "prepends XML elements to empty elements in dst",
+			StartFiles: map[string]any{
+				"App.csproj": "<Project Sdk=\"Microsoft.NET.Sdk\">\n" +
+					"  <ItemGroup>\n" +
+					"  </ItemGroup>\n" +
+					"</Project>\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "App.csproj",
+				},
+				"match": map[string]any{
+					"pattern": "/Project/ItemGroup",
+				},
+				"action": map[string]any{
+					"type": "prepend",
+				},
+				"src": map[string]any{
+					"content": "<PackageReference Include=\"Serilog\" Version=\"3.1.1\" />",
+				},
+			},
+			EndFiles: map[string]any{
+				"App.csproj": "<Project Sdk=\"Microsoft.NET.Sdk\">\n" +
+					"  <ItemGroup>\n" +
+					"    <PackageReference Include=\"Serilog\" Version=\"3.1.1\"/>\n" +
+					"  </ItemGroup>\n" +
+					"</Project>\n",
+			},
+		},
+		{
+			Desc: "upserts XML elements in dst",
+			StartFiles: map[string]any{
+				"App.csproj": "<Project>\n" +
+					"  <ItemGroup>\n" +
+					"    <PackageReference Include=\"Serilog\"/>\n" +
+					"  </ItemGroup>\n" +
+					"</Project>\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "App.csproj",
+				},
+				"match": map[string]any{
+					"pattern": "/Project/ItemGroup",
+				},
+				"action": map[string]any{
+					"type":  "append",
+					"merge": "upsert",
+				},
+				"src": map[string]any{
+					"content": "<PackageReference Include=\"Serilog\"/>\n" +
+						"<PackageReference Include=\"Dapper\"/>\n",
+				},
+			},
+			EndFiles: map[string]any{
+				"App.csproj": "<Project>\n" +
+					"  <ItemGroup>\n" +
+					"    <PackageReference Include=\"Serilog\"/>\n" +
+					"    <PackageReference Include=\"Dapper\"/>\n" +
+					"  </ItemGroup>\n" +
+					"</Project>\n",
+			},
+		},
+		{
+			Desc: "replaces XML attributes and text in dst",
+			StartFiles: map[string]any{
+				"App.csproj": "<Project>\n" +
+					"  <PropertyGroup>\n" +
+					"    <TargetFramework>net6.0</TargetFramework>\n" +
+					"  </PropertyGroup>\n" +
+					"  <ItemGroup>\n" +
+					"    <PackageReference Include=\"Serilog\" Version=\"2.0.0\"/>\n" +
+					"  </ItemGroup>\n" +
+					"</Project>\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "App.csproj",
+				},
+				"match": map[string]any{
+					"pattern": "//PackageReference[@Include='Serilog']/@Version",
+				},
+				"action": map[string]any{
+					"type": "replace",
+				},
+				"src": map[string]any{
+					"content": "3.1.1",
+				},
+			},
+			EndFiles: map[string]any{
+				"App.csproj": "<Project>\n" +
+					"  <PropertyGroup>\n" +
+					"    <TargetFramework>net6.0</TargetFramework>\n" +
+					"  </PropertyGroup>\n" +
+					"  <ItemGroup>\n" +
+					"    <PackageReference Include=\"Serilog\" Version=\"3.1.1\"/>\n" +
+					"  </ItemGroup>\n" +
+					"</Project>\n",
+			},
+		},
+		{
+			Desc: "replaces XML text in dst",
+			StartFiles: map[string]any{
+				"App.csproj": "<Project>\n" +
+					"  <PropertyGroup>\n" +
+					"    <TargetFramework>net6.0</TargetFramework>\n" +
+					"  </PropertyGroup>\n" +
+					"</Project>\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "App.csproj",
+				},
+				"match": map[string]any{
+					"pattern": "/Project/PropertyGroup/TargetFramework",
+				},
+				"action": map[string]any{
+					"type": "replace",
+				},
+				"src": map[string]any{
+					"content": "net8.0",
+				},
+			},
+			EndFiles: map[string]any{
+				"App.csproj": "<Project>\n" +
+					"  <PropertyGroup>\n" +
+					"    <TargetFramework>net8.0</TargetFramework>\n" +
+					"  </PropertyGroup>\n" +
+					"</Project>\n",
+			},
+		},
+		{
+			Desc: "deletes XML elements in dst",
+			StartFiles: map[string]any{
+				"pom.xml": "<project>\n" +
+					"  <!-- Dependencies -->\n" +
+					"  <dependencies>\n" +
+					"    <dependency scope=\"test\">junit</dependency>\n" +
+					"    <dependency scope=\"test\">mockito</dependency>\n" +
+					"  </dependencies>\n" +
+					"</project>\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "pom.xml",
+				},
+				"match": map[string]any{
+					"pattern": "//dependency[text()='mockito']",
+				},
+				"action": map[string]any{
+					"type": "delete",
+				},
+			},
+			EndFiles: map[string]any{
+				"pom.xml": "<project>\n" +
+					"  <!-- Dependencies -->\n" +
+					"  <dependencies>\n" +
+					"    <dependency scope=\"test\">junit</dependency>\n" +
+					"  </dependencies>\n" +
+					"</project>\n",
+			},
+		},
+		{
+			Desc: "deletes XML attributes in dst",
+			StartFiles: map[string]any{
+				"pom.xml": "<project>\n" +
+					"  <dependency scope=\"test\">junit</dependency>\n" +
+					"</project>\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "pom.xml",
+				},
+				"match": map[string]any{
+					"pattern": "/project/dependency/@scope",
+				},
+				"action": map[string]any{
+					"type": "delete",
+				},
+			},
+			EndFiles: map[string]any{
+				"pom.xml": "<project>\n" +
+					"  <dependency>junit</dependency>\n" +
+					"</project>\n",
+			},
+		},
+		{
+			Desc: "skips XML updates when unless_present matches",
+			StartFiles: map[string]any{
+				"pom.xml": "<project>\n" +
+					"  <dependencies>\n" +
+					"    <dependency><artifactId>junit</artifactId></dependency>\n" +
+					"  </dependencies>\n" +
+					"</project>\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "pom.xml",
+				},
+				"match": map[string]any{
+					"pattern": "/project/dependencies",
+				},
+				"action": map[string]any{
+					"type":           "append",
+					"unless_present": "//dependency[artifactId='junit']",
+				},
+				"src": map[string]any{
+					"content": "<dependency><artifactId>junit</artifactId></dependency>",
+				},
+			},
+			EndFiles: map[string]any{
+				"pom.xml": "<project>\n" +
+					"  <dependencies>\n" +
+					"    <dependency><artifactId>junit</artifactId></dependency>\n" +
+					"  </dependencies>\n" +
+					"</project>\n",
+			},
+		},
+		{
+			Desc: "returns an error when inserting into XML",
+			StartFiles: map[string]any{
+				"pom.xml": "<project/>\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "pom.xml",
+				},
+				"action": map[string]any{
+					"type": "insert-after",
+				},
+				"src": map[string]any{
+					"content": "<foo/>",
+				},
+			},
+			EndFiles: map[string]any{
+				"pom.xml": "<project/>\n",
+			},
+			Err: "insert-after is only supported for text content",
+		},
+
+		{
+			Desc: "updates dotenv data in dst",
+			StartFiles: map[string]any{
+				".env.example": "# Database\n" +
+					"export DATABASE_URL=postgres://localhost/dev # local only\n" +
+					"DEBUG=true\n" +
+					"\n" +
+					"SECRET_KEY='changeme'\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path":         ".env.example",
+					"content_type": "dotenv",
+				},
+				"action": map[string]any{
+					"type": "append",
+				},
+				"src": map[string]any{
+					"content": map[string]any{
+						"DATABASE_URL": "postgres://localhost/{{ .Name }}",
+						"APP_NAME":     "My App",
+					},
+				},
+			},
+			Values: map[string]any{
+				"Name": "example",
+			},
+			EndFiles: map[string]any{
+				".env.example": "# Database\n" +
+					"export DATABASE_URL=postgres://localhost/example # local only\n" +
+					"DEBUG=true\n" +
+					"\n" +
+					"SECRET_KEY='changeme'\n" +
+					"APP_NAME=\"My App\"\n",
+			},
+		},
+		{
+			Desc: "deletes dotenv data in dst",
+			StartFiles: map[string]any{
+				".env": "A=1\n" +
+					"B=\"multi\n" +
+					"line\"\n" +
+					"C=3\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path":         ".env",
+					"content_type": "dotenv",
+				},
+				"match": map[string]any{
+					"pattern": "$.B",
+				},
+				"action": map[string]any{
+					"type": "delete",
+				},
+			},
+			EndFiles: map[string]any{
+				".env": "A=1\n" +
+					"C=3\n",
+			},
+		},
+		{
+			Desc: "updates INI data in dst",
+			StartFiles: map[string]any{
+				"setup.cfg": "[metadata]\n" +
+					"name = example\n" +
+					"version = 0.1.0\n" +
+					"\n" +
+					"; Runtime dependencies\n" +
+					"[options]\n" +
+					"install_requires =\n" +
+					"  requests\n" +
+					"python_requires = >=3.8\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path":         "setup.cfg",
+					"content_type": "ini",
+				},
+				"match": map[string]any{
+					"pattern": "$.options",
+				},
+				"action": map[string]any{
+					"type": "append",
+				},
+				"src": map[string]any{
+					"content": map[string]any{
+						"install_requires": "requests\nrich",
+						"zip_safe":         "False",
+					},
+				},
+			},
+			EndFiles: map[string]any{
+				"setup.cfg": "[metadata]\n" +
+					"name = example\n" +
+					"version = 0.1.0\n" +
+					"\n" +
+					"; Runtime dependencies\n" +
+					"[options]\n" +
+					"install_requires =\n" +
+					"  requests\n" +
+					"  rich\n" +
+					"python_requires = >=3.8\n" +
+					"zip_safe = False\n",
+			},
+		},
+		{
+			Desc: "adds INI sections to dst",
+			StartFiles: map[string]any{
+				"tox.ini": "[tox]\n" +
+					"envlist = py311\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path":         "tox.ini",
+					"content_type": "ini",
+				},
+				"action": map[string]any{
+					"type": "append",
+				},
+				"src": map[string]any{
+					"content": map[string]any{
+						"testenv": map[string]any{
+							"commands": "pytest",
+						},
+					},
+				},
+			},
+			EndFiles: map[string]any{
+				"tox.ini": "[tox]\n" +
+					"envlist = py311\n" +
+					"\n" +
+					"[testenv]\n" +
+					"commands = pytest\n",
+			},
+		},
+
+		{
+			Desc: "replaces markdown sections in dst",
+			StartFiles: map[string]any{
+				"README.md": "# Example\n" +
+					"\n" +
+					"## Installation\n" +
+					"\n" +
+					"TODO\n" +
+					"\n" +
+					"## License\n" +
+					"\n" +
+					"MIT\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path":         "README.md",
+					"content_type": "markdown",
+				},
+				"match": map[string]any{
+					"pattern": "## Installation",
+				},
+				"src": map[string]any{
+					"content": "```sh\ngo install {{ .Module }}@latest\n```\n",
+				},
+			},
+			Values: map[string]any{
+				"Module": "example.com/foo",
+			},
+			EndFiles: map[string]any{
+				"README.md": "# Example\n" +
+					"\n" +
+					"## Installation\n" +
+					"\n" +
+					"```sh\n" +
+					"go install example.com/foo@latest\n" +
+					"```\n" +
+					"\n" +
+					"## License\n" +
+					"\n" +
+					"MIT\n",
+			},
+			Output: "✓ [    update]: README.md (## Installation)\n",
+		},
+		{
+			Desc: "creates missing markdown sections in dst when default is set",
+			StartFiles: map[string]any{
+				"CHANGELOG.md": "# Changelog\n" +
+					"\n" +
+					"## v1.0.0\n" +
+					"\n" +
+					"- Initial release\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path":         "CHANGELOG.md",
+					"content_type": "markdown",
+				},
+				"match": map[string]any{
+					"pattern": "# Changelog > ## Unreleased > ### Added",
+					"default": "",
+				},
+				"action": map[string]any{
+					"type": "append",
+				},
+				"src": map[string]any{
+					"content": "- New feature",
+				},
+			},
+			EndFiles: map[string]any{
+				"CHANGELOG.md": "# Changelog\n" +
+					"\n" +
+					"## v1.0.0\n" +
+					"\n" +
+					"- Initial release\n" +
+					"\n" +
+					"## Unreleased\n" +
+					"\n" +
+					"### Added\n" +
+					"\n" +
+					"- New feature\n",
+			},
+		},
+		{
+			Desc: "deletes markdown sections in dst",
+			StartFiles: map[string]any{
+				"README.md": "# Example\n" +
+					"\n" +
+					"## TODO\n" +
+					"\n" +
+					"- Write docs\n" +
+					"\n" +
+					"## License\n" +
+					"\n" +
+					"MIT\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path":         "README.md",
+					"content_type": "markdown",
+				},
+				"match": map[string]any{
+					"pattern": "TODO",
+				},
+				"action": map[string]any{
+					"type": "delete",
+				},
+			},
+			EndFiles: map[string]any{
+				"README.md": "# Example\n" +
+					"\n" +
+					"## License\n" +
+					"\n" +
+					"MIT\n",
+			},
+		},
+		{
+			Desc: "skips markdown updates when unless_present matches",
+			StartFiles: map[string]any{
+				"README.md": "# Example\n" +
+					"\n" +
+					"## Usage\n" +
+					"\n" +
+					"Run it.\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path":         "README.md",
+					"content_type": "markdown",
+				},
+				"match": map[string]any{
+					"pattern": "# Example",
+				},
+				"action": map[string]any{
+					"type":           "append",
+					"unless_present": "# Example > Usage",
+				},
+				"src": map[string]any{
+					"content": "## Usage\n\nRun it.\n",
+				},
+			},
+			EndFiles: map[string]any{
+				"README.md": "# Example\n" +
+					"\n" +
+					"## Usage\n" +
+					"\n" +
+					"Run it.\n",
+			},
+		},
+		{
+			Desc: "skips markdown sections that are missing",
+			StartFiles: map[string]any{
+				"README.md": "# Example\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path":         "README.md",
+					"content_type": "markdown",
+				},
+				"match": map[string]any{
+					"pattern": "# Example > ## Usage",
+				},
+				"src": map[string]any{
+					"content": "Run it.",
+				},
+			},
+			EndFiles: map[string]any{
+				"README.md": "# Example\n",
+			},
+			Output: "✓ [      skip]: README.md (# Example > ## Usage not found)\n",
+		},
+		{
+			Desc: "returns an error when markdown sections are missing and missing is error",
+			StartFiles: map[string]any{
+				"README.md": "# Example\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path":         "README.md",
+					"content_type": "markdown",
+					"missing":      "error",
+				},
+				"match": map[string]any{
+					"pattern": "# Example > ## Usage",
+				},
+				"src": map[string]any{
+					"content": "Run it.",
+				},
+			},
+			EndFiles: map[string]any{
+				"README.md": "# Example\n",
+			},
+			Output: "✖ [      fail]: README.md\n",
+			Err:    "markdown section not found",
+		},
+		{
+			Desc: "returns an error when inserting into markdown sections",
+			StartFiles: map[string]any{
+				"README.md": "# Example\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path":         "README.md",
+					"content_type": "markdown",
+				},
+				"match": map[string]any{
+					"pattern": "# Example",
+				},
+				"action": map[string]any{
+					"type": "insert-before",
+				},
+				"src": map[string]any{
+					"content": "foo",
+				},
+			},
+			EndFiles: map[string]any{
+				"README.md": "# Example\n",
+			},
+			Err: "insert-before is only supported for text content",
+		},
+
+		{
+			Desc: "adds imports and registrations to go source in dst",
+			StartFiles: map[string]any{
+				"routes.go": "package app\n" +
+					"\n" +
+					"import \"net/http\"\n" +
+					"\n" +
+					"func Register(mux *http.ServeMux) {\n" +
+					"\tmux.HandleFunc(\"/\", index)\n" +
+					"}\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path":         "routes.go",
+					"content_type": "go",
+				},
+				"match": map[string]any{
+					"pattern": "func:Register",
+				},
+				"action": map[string]any{
+					"type": "append",
+				},
+				"src": map[string]any{
+					"content": "mux.HandleFunc(\"/{{ .Name }}\",   {{ .Name }}.Handler)",
+				},
+			},
+			Values: map[string]any{
+				"Name": "users",
+			},
+			EndFiles: map[string]any{
+				"routes.go": "package app\n" +
+					"\n" +
+					"import \"net/http\"\n" +
+					"\n" +
+					"func Register(mux *http.ServeMux) {\n" +
+					"\tmux.HandleFunc(\"/\", index)\n" +
+					"\tmux.HandleFunc(\"/users\", users.Handler)\n" +
+					"}\n",
+			},
+			Output: "✓ [    update]: routes.go (func:Register)\n",
+		},
+		{
+			Desc: "adds struct fields to go source in dst",
+			StartFiles: map[string]any{
+				"config.go": "package app\n" +
+					"\n" +
+					"type Config struct {\n" +
+					"\tName string\n" +
+					"}\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path":         "config.go",
+					"content_type": "go",
+				},
+				"match": map[string]any{
+					"pattern": "struct:Config",
+				},
+				"action": map[string]any{
+					"type":  "append",
+					"merge": "upsert",
+				},
+				"src": map[string]any{
+					"content": "Timeout time.Duration",
+				},
+			},
+			EndFiles: map[string]any{
+				"config.go": "package app\n" +
+					"\n" +
+					"type Config struct {\n" +
+					"\tName    string\n" +
+					"\tTimeout time.Duration\n" +
+					"}\n",
+			},
+		},
+		{
+			Desc: "skips go updates when unless_present matches",
+			StartFiles: map[string]any{
+				"main.go": "package main\n\nfunc run() {}\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path":         "main.go",
+					"content_type": "go",
+				},
+				"match": map[string]any{
+					"pattern": "import",
+				},
+				"action": map[string]any{
+					"type":           "append",
+					"unless_present": "func:run",
+				},
+				"src": map[string]any{
+					"content": "fmt",
+				},
+			},
+			EndFiles: map[string]any{
+				"main.go": "package main\n\nfunc run() {}\n",
+			},
+		},
+		{
+			Desc: "returns an error when the go target is invalid",
+			StartFiles: map[string]any{
+				"main.go": "package main\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path":         "main.go",
+					"content_type": "go",
+				},
+				"match": map[string]any{
+					"pattern": "main",
+				},
+				"src": map[string]any{
+					"content": "foo",
+				},
+			},
+			EndFiles: map[string]any{
+				"main.go": "package main\n",
+			},
+			Err: "invalid go target",
+		},
+		{
+			Desc: "skips go targets that are missing",
+			StartFiles: map[string]any{
+				"main.go": "package main\n\nfunc main() {}\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path":         "main.go",
+					"content_type": "go",
+				},
+				"match": map[string]any{
+					"pattern": "func:Register",
+				},
+				"action": map[string]any{
+					"type": "append",
+				},
+				"src": map[string]any{
+					"content": "register()",
+				},
+			},
+			EndFiles: map[string]any{
+				"main.go": "package main\n\nfunc main() {}\n",
+			},
+			Output: "✓ [      skip]: main.go (func:Register not found)\n",
+		},
+		{
+			Desc: "returns an error when go targets are missing and missing is error",
+			StartFiles: map[string]any{
+				"main.go": "package main\n\nfunc main() {}\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path":         "main.go",
+					"content_type": "go",
+					"missing":      "error",
+				},
+				"match": map[string]any{
+					"pattern": "func:Register",
+				},
+				"action": map[string]any{
+					"type": "append",
+				},
+				"src": map[string]any{
+					"content": "register()",
+				},
+			},
+			EndFiles: map[string]any{
+				"main.go": "package main\n\nfunc main() {}\n",
+			},
+			Output: "✖ [      fail]: main.go\n",
+			Err:    "go target not found",
+		},
+		{
+			Desc: "adds go imports unless all of them are present",
+			StartFiles: map[string]any{
+				"main.go": "package main\n\nimport \"fmt\"\n\nfunc main() {}\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path":         "main.go",
+					"content_type": "go",
+				},
+				"match": map[string]any{
+					"pattern": "import",
+				},
+				"action": map[string]any{
+					"type":           "append",
+					"unless_present": "import",
+				},
+				"src": map[string]any{
+					"content": "fmt\nos",
+				},
+			},
+			EndFiles: map[string]any{
+				"main.go": "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\nfunc main() {}\n",
+			},
+			Output: "✓ [    update]: main.go (import)\n",
+		},
+		{
+			Desc: "skips go imports when all of them are present",
+			StartFiles: map[string]any{
+				"main.go": "package main\n\nimport \"fmt\"\n\nfunc main() {}\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path":         "main.go",
+					"content_type": "go",
+				},
+				"match": map[string]any{
+					"pattern": "import",
+				},
+				"action": map[string]any{
+					"type":           "append",
+					"unless_present": "import",
+				},
+				"src": map[string]any{
+					"content": "fmt",
+				},
+			},
+			EndFiles: map[string]any{
+				"main.go": "package main\n\nimport \"fmt\"\n\nfunc main() {}\n",
+			},
+			Output: "✓ [      skip]: main.go (already present)\n",
+		},
+		{
+			Desc: "formats whole file updates to go files",
+			StartFiles: map[string]any{
+				"main.go": "package main\n\nfunc main() {}\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path":         "main.go",
+					"content_type": "go",
+				},
+				"action": map[string]any{
+					"type": "append",
+				},
+				"src": map[string]any{
+					"content": "func run(  ) {\nprintln( \"run\" )\n}",
+				},
+			},
+			EndFiles: map[string]any{
+				"main.go": "package main\n\nfunc main() {}\nfunc run() {\n\tprintln(\"run\")\n}\n",
+			},
+		},
+		{
+			Desc: "updates go files with regexp patterns unless the content type is set",
+			StartFiles: map[string]any{
+				"main.go": "package main\n\nconst version = \"1.0.0\"\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "main.go",
+				},
+				"match": map[string]any{
+					"pattern": "1\\.0\\.0",
+				},
+				"src": map[string]any{
+					"content": "1.1.0",
+				},
+			},
+			EndFiles: map[string]any{
+				"main.go": "package main\n\nconst version = \"1.1.0\"\n",
+			},
+		},
+		{
+			Desc: "appends to block style YAML sequences in dst",
+			StartFiles: map[string]any{
+				"example.yml": "foo:\n  - 1\n  - 2\n  - 3\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "example.yml",
+				},
+				"match": map[string]any{
+					"pattern": "$.foo",
+				},
+				"action": map[string]any{
+					"type": "append",
+				},
+				"src": map[string]any{
+					"content": []any{4, 5},
+				},
+			},
+			EndFiles: map[string]any{
+				"example.yml": "foo:\n  - 1\n  - 2\n  - 3\n  - 4\n  - 5\n",
+			},
+		},
+		{
+			Desc: "preserves blank lines and compact sequences of YAML data in dst",
+			StartFiles: map[string]any{
+				"example.yml": "# Tools\n" +
+					"tools:\n" +
+					"- a\n" +
+					"- b\n" +
+					"\n" +
+					"# Settings\n" +
+					"settings:\n" +
+					"  debug: false\n" +
+					"\n" +
+					"  level: 1\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "example.yml",
+				},
+				"match": map[string]any{
+					"pattern": "$.tools",
+				},
+				"action": map[string]any{
+					"type": "prepend",
+				},
+				"src": map[string]any{
+					"content": []any{"z"},
+				},
+			},
+			EndFiles: map[string]any{
+				"example.yml": "# Tools\n" +
+					"tools:\n" +
+					"- z\n" +
+					"- a\n" +
+					"- b\n" +
+					"\n" +
+					"# Settings\n" +
+					"settings:\n" +
+					"  debug: false\n" +
+					"\n" +
+					"  level: 1\n",
+			},
+		},
+		{
+			Desc: "preserves blank lines around replaced YAML data in dst",
+			StartFiles: map[string]any{
+				"example.yml": "settings:\n" +
+					"  debug: false # toggled\n" +
+					"\n" +
+					"  level: 1\n" +
+					"\n" +
+					"tools:\n" +
+					"- a\n" +
+					"- b\n",
+			},
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "example.yml",
+				},
+				"match": map[string]any{
+					"pattern": "$.settings",
+				},
+				"action": map[string]any{
+					"type": "append",
+				},
+				"src": map[string]any{
+					"content": map[string]any{
+						"debug": true,
+						"paths": []any{"x", "z"},
+					},
+				},
+			},
+			EndFiles: map[string]any{
+				"example.yml": "settings:\n" +
+					"  debug: true # toggled\n" +
+					"\n" +
+					"  level: 1\n" +
+					"  paths:\n" +
+					"    - x\n" +
+					"    - z\n" +
+					"\n" +
+					"tools:\n" +
+					"- a\n" +
+					"- b\n",
+			},
+		},
+
+		{
+			Desc: "[missing:ignore] ignores missing paths",
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path": "README.md",
+				},
+				"match": map[string]any{
+					"pattern": "foo",
+				},
+			},
+			EndFiles: map[string]any{
+				"README.md": false,
+			},
+		},
+		{
+			Desc: "[missing:error] returns an error when path is missing",
+			TaskData: map[string]any{
+				"type": "update",
+				"dst": map[string]any{
+					"path":    "README.md",
+					"missing": "error",
+				},
+				"match": map[string]any{
+					"pattern": "foo",
+				},
+			},
+			EndFiles: map[string]any{
+				"README.md": false,
+			},
+			Err: "path not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Desc, func(t *testing.T) {
+			testutil.InTempDir(t, func(tmpDir string) {
+				// Populate the temp dir w/ any initial files
+				testutil.WritePaths(t, tmpDir, tt.StartFiles)
+
+				// Setup the app.
+				app := NewTestApp()
+				app.Config.DryRun = tt.DryRun
 				if tt.Setup != nil {
 					tt.Setup(app)
 				}
@@ -836,6 +2816,9 @@ the
 				// Ensure the expected files were generated
 				testutil.AssertPaths(t, tmpDir, tt.EndFiles)
 
+				if tt.Output != "" {
+					assert.Equal(t, tt.Output, app.IO.Out.String())
+				}
 				if tt.Err == "" {
 					assert.NoError(t, err)
 				} else {
@@ -845,3 +2828,24 @@ the
 		})
 	}
 }
+
+func TestUpdateTask_ExecuteLogsSkippedUpdates(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"README.md": "# Title\n\nCopyright Acme\n",
+		})
+
+		app := NewTestApp()
+		task, err := NewTask(map[string]any{
+			"type":   "update",
+			"dst":    map[string]any{"path": "README.md"},
+			"action": map[string]any{"type": "append", "idempotent": true},
+			"src":    map[string]any{"content": "Copyright Acme\n"},
+		})
+		require.NoError(t, err)
+
+		ctx := NewTaskContext(app)
+		assert.NoError(t, task.Execute(ctx, map[string]any{"DstPath": tmpDir}))
+		assert.Equal(t, "✓ [      skip]: README.md (already present)\n", app.IO.Out.String())
+	})
+}
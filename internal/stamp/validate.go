@@ -0,0 +1,630 @@
+package stamp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"unicode/utf8"
+
+	"github.com/gobuffalo/flect"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/twelvelabs/termite/render"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"gopkg.in/yaml.v3"
+
+	"github.com/twelvelabs/stamp/internal/fsutil"
+	"github.com/twelvelabs/stamp/internal/value"
+)
+
+var (
+	// Values that are set by the task set rather than the user.
+	builtinTaskKeys = []string{"SrcPath", "DstPath", "_Item", "_Index"}
+
+	yamlErrorRegexp     = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+	templateErrorRegexp = regexp.MustCompile(`^template: [^:]*:(\d+):(?:(\d+):)? (.*)$`)
+)
+
+// Problem is an issue found when validating a generator.
+type Problem struct {
+	Path    string
+	Line    int
+	Column  int
+	Message string
+}
+
+// String returns the problem formatted as `path:line:column: message`.
+func (p Problem) String() string {
+	pos := p.Path
+	if p.Line > 0 {
+		pos += ":" + strconv.Itoa(p.Line)
+		if p.Column > 0 {
+			pos += ":" + strconv.Itoa(p.Column)
+		}
+	}
+	return pos + ": " + p.Message
+}
+
+// ValidateGenerator checks the generator package at path for mistakes that
+// would otherwise only surface when the generator is run:
+//
+//   - metadata that does not match the generator JSON schema
+//   - values and tasks that fail to parse
+//   - templates (in the metadata and in `_src`) that fail to compile
+//   - templates that reference undefined value keys
+//   - `src.path` entries that do not exist in `_src`
+//
+// All problems are returned (sorted by position) rather than just the first.
+// The error is only non-nil if the package could not be read.
+func ValidateGenerator(store *Store, path string) ([]Problem, error) {
+	v := &generatorValidator{
+		store:    store,
+		metaPath: filepath.Join(path, store.MetaFile),
+		srcPath:  filepath.Join(path, "_src"),
+		keys:     map[string]bool{},
+		static:   []string{},
+	}
+	if err := v.validate(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		a, b := v.problems[i], v.problems[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return v.problems, nil
+}
+
+type generatorValidator struct {
+	store    *Store
+	metaPath string
+	srcPath  string
+	schema   *jsonschema.Compiler
+	schemaID string
+
+	keys     map[string]bool
+	static   []string
+	problems []Problem
+}
+
+func (v *generatorValidator) validate() error {
+	buf, err := os.ReadFile(v.metaPath)
+	if err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(buf, doc); err != nil {
+		v.addYAMLError(err)
+		return nil
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		v.add(doc, "expected a mapping of generator metadata")
+		return nil
+	}
+	root := doc.Content[0]
+
+	if err := v.compileSchema(); err != nil {
+		return err
+	}
+	v.validateMetadata(root)
+
+	valueNodes := sequenceItems(lookupKey(root, "values"))
+	taskNodes := sequenceItems(lookupKey(root, "tasks"))
+
+	// Collect the value keys first so templates can be checked against them.
+	for i, node := range valueNodes {
+		v.validateValue(i, node)
+	}
+	for i, node := range taskNodes {
+		v.validateTask(i, node)
+	}
+	// Mirror the `DstPath` value that NewGenerator adds.
+	v.keys["DstPath"] = true
+
+	valueKeys := copyKeys(v.keys)
+	for _, node := range valueNodes {
+		for _, field := range []string{"default", "if"} {
+			if n := lookupKey(node, field); n != nil && n.Kind == yaml.ScalarNode && n.Tag == "!!str" {
+				v.validateTemplate(n, n.Value, valueKeys)
+			}
+		}
+	}
+
+	taskKeys := copyKeys(v.keys)
+	for _, k := range builtinTaskKeys {
+		taskKeys[k] = true
+	}
+	for _, node := range taskNodes {
+		v.validateTaskTemplates(node, taskKeys)
+	}
+	v.validateSrcFiles(taskKeys)
+
+	return nil
+}
+
+// compileSchema compiles the schema produced by [GeneratorMetadata.ReflectSchema].
+func (v *generatorValidator) compileSchema() error {
+	schema, err := (&GeneratorMetadata{}).ReflectSchema()
+	if err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	buf, err := json.Marshal(schema)
+	if err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	doc, err := jsonschema.UnmarshalJSON(strings.NewReader(string(buf)))
+	if err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	v.schemaID = *schema.ID
+	v.schema = jsonschema.NewCompiler()
+	v.schema.DefaultDraft(jsonschema.Draft7)
+	if err := v.schema.AddResource(v.schemaID, doc); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	return nil
+}
+
+// validateMetadata validates the top level metadata against the schema.
+// Tasks are validated individually by validateTask, so that problems are
+// reported against the schema for the task type rather than every `oneOf` branch.
+func (v *generatorValidator) validateMetadata(root *yaml.Node) {
+	data := map[string]any{}
+	if !v.decode(root, &data) {
+		return
+	}
+	// Metadata keys may be pascalized (see [pkg.Package.MetadataLookup]).
+	for _, key := range []string{"name", "description", "version", "visibility", "values", "tasks"} {
+		if val, ok := data[flect.Pascalize(key)]; ok {
+			if _, exists := data[key]; !exists {
+				delete(data, flect.Pascalize(key))
+				data[key] = val
+			}
+		}
+	}
+	if _, ok := data["tasks"].([]any); ok {
+		data["tasks"] = []any{}
+	}
+	v.validateSchema(root, "", data)
+}
+
+// validateValue parses a value definition and records its key.
+func (v *generatorValidator) validateValue(index int, node *yaml.Node) {
+	data := map[string]any{}
+	if !v.decode(node, &data) {
+		return
+	}
+	val, err := value.NewValue(data)
+	if err != nil {
+		v.add(node, "values[%d]: %s", index, err)
+		return
+	}
+	v.keys[val.Key] = true
+	if err := value.ValidateTransformRule(val.Key, val.TransformRules); err != nil {
+		v.add(nodeOrParent(lookupKey(node, "transform"), node), "values[%d]: %s", index, err)
+	}
+}
+
+// validateTask validates a task definition against the schema for its type
+// and parses it with [NewTask].
+func (v *generatorValidator) validateTask(index int, node *yaml.Node) {
+	data := map[string]any{}
+	if !v.decode(node, &data) {
+		return
+	}
+
+	before := len(v.problems)
+	for _, t := range AllTasks() {
+		if t.TypeKey() == data["type"] {
+			def := reflect.TypeOf(t).Elem().Name()
+			v.validateSchema(node, fmt.Sprintf("tasks[%d]", index), data, "definitions", def)
+			break
+		}
+	}
+
+	v.validateSrcPath(index, node)
+
+	task, err := NewTask(data)
+	if err != nil {
+		// The schema usually catches the same mistakes with better positions.
+		if len(v.problems) == before {
+			v.add(nodeOrParent(lookupKey(node, "type"), node), "tasks[%d]: %s", index, err)
+		}
+		return
+	}
+
+	if gt, ok := task.(*GeneratorTask); ok {
+		subGen, err := gt.GetGenerator(v.store)
+		if err != nil {
+			v.add(nodeOrParent(lookupKey(node, "name"), node),
+				"tasks[%d]: unable to load sub-generator '%s': %s", index, gt.Name, err)
+			return
+		}
+		for _, val := range subGen.Values.All() {
+			v.keys[val.Key] = true
+		}
+	}
+}
+
+// validateSrcPath ensures non-templated source paths exist in `_src`.
+func (v *generatorValidator) validateSrcPath(index int, node *yaml.Node) {
+	src := lookupKey(node, "src")
+	pathNode := lookupKey(src, "path")
+	if pathNode == nil || pathNode.Kind != yaml.ScalarNode || strings.Contains(pathNode.Value, "{{") {
+		return
+	}
+	path, err := fsutil.EnsurePathRelativeToRoot(pathNode.Value, v.srcPath)
+	if err != nil {
+		v.add(pathNode, "tasks[%d].src.path: %s", index, err)
+		return
+	}
+	if fsutil.NoPathExists(path) {
+		v.add(pathNode, "tasks[%d].src.path: %s does not exist in _src", index, pathNode.Value)
+		return
+	}
+	if static := lookupKey(src, "static"); static != nil && static.Value == "true" {
+		v.static = append(v.static, path)
+	}
+}
+
+// validateTaskTemplates compiles every string in the task definition.
+func (v *generatorValidator) validateTaskTemplates(node *yaml.Node, keys map[string]bool) {
+	walkScalars(node, func(n *yaml.Node) {
+		if n.Tag == "!!str" {
+			v.validateTemplate(n, n.Value, keys)
+		}
+	})
+}
+
+// validateSrcFiles compiles every (non-static) file in `_src`.
+func (v *generatorValidator) validateSrcFiles(keys map[string]bool) {
+	if !fsutil.PathIsDir(v.srcPath) {
+		return
+	}
+	_ = filepath.WalkDir(v.srcPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || v.isStatic(path) {
+			return nil //nolint:nilerr
+		}
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			v.problems = append(v.problems, Problem{Path: path, Message: err.Error()})
+			return nil
+		}
+		if !utf8.Valid(buf) {
+			return nil // binary files are rendered as-is
+		}
+		v.validateFile(path, string(buf), keys)
+		return nil
+	})
+}
+
+func (v *generatorValidator) isStatic(path string) bool {
+	for _, s := range v.static {
+		if path == s || strings.HasPrefix(path, s+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateTemplate compiles a template string found in the metadata file.
+func (v *generatorValidator) validateTemplate(node *yaml.Node, text string, keys map[string]bool) {
+	line := node.Line
+	if node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		line++ // content starts on the line after the indicator
+	}
+	for _, p := range templateProblems(text, keys) {
+		column := p.Column
+		if p.Line <= 1 {
+			column = node.Column
+		}
+		v.problems = append(v.problems, Problem{
+			Path:    v.metaPath,
+			Line:    line + p.Line - 1,
+			Column:  column,
+			Message: p.Message,
+		})
+	}
+}
+
+// validateFile compiles a template file in `_src`.
+func (v *generatorValidator) validateFile(path string, text string, keys map[string]bool) {
+	for _, p := range templateProblems(text, keys) {
+		p.Path = path
+		v.problems = append(v.problems, p)
+	}
+}
+
+func (v *generatorValidator) validateSchema(node *yaml.Node, prefix string, data any, defPath ...string) {
+	loc := v.schemaID
+	if len(defPath) > 0 {
+		loc += "#/" + strings.Join(defPath, "/")
+	}
+	schema, err := v.schema.Compile(loc)
+	if err != nil {
+		v.add(node, "schema: %s", err)
+		return
+	}
+
+	// The validator expects JSON types (i.e. float64 rather than int).
+	buf, err := json.Marshal(data)
+	if err != nil {
+		v.add(node, "%s", err)
+		return
+	}
+	instance, err := jsonschema.UnmarshalJSON(strings.NewReader(string(buf)))
+	if err != nil {
+		v.add(node, "%s", err)
+		return
+	}
+
+	verr := &jsonschema.ValidationError{}
+	if err := schema.Validate(instance); errors.As(err, &verr) {
+		printer := message.NewPrinter(language.English)
+		for _, leaf := range validationLeaves(verr) {
+			path := formatInstancePath(prefix, leaf.InstanceLocation)
+			msg := leaf.ErrorKind.LocalizedString(printer)
+			if path != "" {
+				msg = path + ": " + msg
+			}
+			v.add(lookupPath(node, leaf.InstanceLocation), "%s", msg)
+		}
+	}
+}
+
+// decode decodes node into out, recording a problem on failure.
+func (v *generatorValidator) decode(node *yaml.Node, out *map[string]any) bool {
+	if node.Kind != yaml.MappingNode {
+		v.add(node, "expected a mapping, got %s", node.ShortTag())
+		return false
+	}
+	if err := node.Decode(out); err != nil {
+		v.add(node, "%s", err)
+		return false
+	}
+	return true
+}
+
+func (v *generatorValidator) add(node *yaml.Node, format string, args ...any) {
+	v.problems = append(v.problems, Problem{
+		Path:    v.metaPath,
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *generatorValidator) addYAMLError(err error) {
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		for _, msg := range typeErr.Errors {
+			v.addYAMLMessage(msg)
+		}
+		return
+	}
+	v.addYAMLMessage(err.Error())
+}
+
+func (v *generatorValidator) addYAMLMessage(msg string) {
+	p := Problem{Path: v.metaPath, Message: msg}
+	if m := yamlErrorRegexp.FindStringSubmatch(msg); m != nil {
+		p.Line, _ = strconv.Atoi(m[1])
+		p.Message = m[2]
+	}
+	v.problems = append(v.problems, p)
+}
+
+// templateProblems compiles text and returns any parse errors
+// or references to undefined keys (positioned relative to text).
+func templateProblems(text string, keys map[string]bool) []Problem {
+	if !strings.Contains(text, "{{") {
+		return nil
+	}
+
+	tpl, err := template.New("validate").Funcs(render.FuncMap).Parse(text)
+	if err != nil {
+		p := Problem{Message: err.Error()}
+		if m := templateErrorRegexp.FindStringSubmatch(err.Error()); m != nil {
+			p.Line, _ = strconv.Atoi(m[1])
+			p.Column, _ = strconv.Atoi(m[2])
+			p.Message = m[3]
+		}
+		return []Problem{p}
+	}
+
+	problems := []Problem{}
+	for _, t := range tpl.Templates() {
+		if t.Tree == nil || t.Root == nil {
+			continue
+		}
+		walkTemplate(t.Root, true, func(node parse.Node, key string) {
+			if keys[key] {
+				return
+			}
+			p := Problem{Message: fmt.Sprintf("undefined value: .%s", key)}
+			// Location is formatted as `name:line:column`.
+			loc, _ := t.ErrorContext(node)
+			if parts := strings.Split(loc, ":"); len(parts) == 3 {
+				p.Line, _ = strconv.Atoi(parts[1])
+				p.Column, _ = strconv.Atoi(parts[2])
+			}
+			problems = append(problems, p)
+		})
+	}
+	return problems
+}
+
+// walkTemplate calls fn for every reference to a top level key.
+// Field references (`.Key`) are only top level when dot has not been
+// reassigned by `range` or `with`; variable references (`$.Key`) always are.
+func walkTemplate(node parse.Node, rootDot bool, fn func(parse.Node, string)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			walkTemplate(c, rootDot, fn)
+		}
+	case *parse.ActionNode:
+		walkTemplate(n.Pipe, rootDot, fn)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Cmds {
+			walkTemplate(c, rootDot, fn)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			walkTemplate(arg, rootDot, fn)
+		}
+	case *parse.ChainNode:
+		walkTemplate(n.Node, rootDot, fn)
+	case *parse.FieldNode:
+		if rootDot {
+			fn(n, n.Ident[0])
+		}
+	case *parse.VariableNode:
+		if n.Ident[0] == "$" && len(n.Ident) > 1 {
+			fn(n, n.Ident[1])
+		}
+	case *parse.IfNode:
+		walkTemplate(n.Pipe, rootDot, fn)
+		walkTemplate(n.List, rootDot, fn)
+		walkTemplate(n.ElseList, rootDot, fn)
+	case *parse.RangeNode:
+		walkTemplate(n.Pipe, rootDot, fn)
+		walkTemplate(n.List, false, fn)
+		walkTemplate(n.ElseList, rootDot, fn)
+	case *parse.WithNode:
+		walkTemplate(n.Pipe, rootDot, fn)
+		walkTemplate(n.List, false, fn)
+		walkTemplate(n.ElseList, rootDot, fn)
+	case *parse.TemplateNode:
+		walkTemplate(n.Pipe, rootDot, fn)
+	}
+}
+
+// validationLeaves returns the most specific errors in the tree.
+func validationLeaves(err *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(err.Causes) == 0 {
+		return []*jsonschema.ValidationError{err}
+	}
+	leaves := []*jsonschema.ValidationError{}
+	for _, cause := range err.Causes {
+		leaves = append(leaves, validationLeaves(cause)...)
+	}
+	return leaves
+}
+
+// formatInstancePath formats a JSON pointer as `prefix.key[index]`.
+func formatInstancePath(prefix string, tokens []string) string {
+	path := prefix
+	for _, token := range tokens {
+		if _, err := strconv.Atoi(token); err == nil {
+			path += "[" + token + "]"
+		} else if path == "" {
+			path = token
+		} else {
+			path += "." + token
+		}
+	}
+	return path
+}
+
+// lookupPath returns the deepest node found along a JSON pointer.
+func lookupPath(node *yaml.Node, tokens []string) *yaml.Node {
+	for _, token := range tokens {
+		var next *yaml.Node
+		switch node.Kind { //nolint:exhaustive
+		case yaml.MappingNode:
+			next = lookupKey(node, token)
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(token); err == nil && i < len(node.Content) {
+				next = node.Content[i]
+			}
+		}
+		if next == nil {
+			break
+		}
+		node = next
+	}
+	return node
+}
+
+// lookupKey returns the value node for key in a mapping node (or nil).
+// Like [pkg.Package.MetadataLookup], falls back to the pascalized key.
+func lookupKey(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for _, k := range []string{key, flect.Pascalize(key)} {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == k {
+				return node.Content[i+1]
+			}
+		}
+	}
+	return nil
+}
+
+func sequenceItems(node *yaml.Node) []*yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return node.Content
+}
+
+// walkScalars calls fn for every scalar (keys included) below node,
+// skipping the task type.
+func walkScalars(node *yaml.Node, fn func(*yaml.Node)) {
+	switch node.Kind { //nolint:exhaustive
+	case yaml.ScalarNode:
+		fn(node)
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == "type" && node.Content[i+1].Kind == yaml.ScalarNode {
+				continue
+			}
+			walkScalars(node.Content[i], fn)
+			walkScalars(node.Content[i+1], fn)
+		}
+	case yaml.SequenceNode:
+		for _, c := range node.Content {
+			walkScalars(c, fn)
+		}
+	}
+}
+
+func nodeOrParent(node *yaml.Node, parent *yaml.Node) *yaml.Node {
+	if node != nil {
+		return node
+	}
+	return parent
+}
+
+func copyKeys(keys map[string]bool) map[string]bool {
+	copied := map[string]bool{}
+	for k := range keys {
+		copied[k] = true
+	}
+	return copied
+}
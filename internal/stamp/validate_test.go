@@ -0,0 +1,146 @@
+package stamp
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twelvelabs/termite/testutil"
+)
+
+func TestValidateGenerator(t *testing.T) {
+	tests := []struct {
+		Desc     string
+		Files    map[string]any
+		Expected []string
+	}{
+		{
+			Desc: "returns nothing for valid generators",
+			Files: map[string]any{
+				"generator.yaml": `
+name: valid
+values:
+  - key: Name
+    transform: trim
+tasks:
+  - type: create
+    each: "a, b"
+    src:
+      path: "tpl.txt"
+    dst:
+      path: "{{ .DstPath }}/{{ ._Item }}-{{ .Name }}.txt"
+  - type: create
+    src:
+      path: "static.txt"
+      static: true
+    dst:
+      path: "static.txt"
+`,
+				"_src/tpl.txt":    "{{ range .Name | splitList \",\" }}{{ .X }}{{ $.Name }}{{ end }}",
+				"_src/static.txt": "{{ .NotAValue }}",
+			},
+			Expected: []string{},
+		},
+		{
+			Desc: "reports yaml syntax errors",
+			Files: map[string]any{
+				"generator.yaml": "name: bad\ntasks: [\n",
+			},
+			Expected: []string{
+				"generator.yaml:2: did not find expected node content",
+			},
+		},
+		{
+			Desc: "reports schema and parse errors",
+			Files: map[string]any{
+				"generator.yaml": `
+name: bad
+values:
+  - key: Name
+    transform: trim,shout
+tasks:
+  - type: create
+    src:
+      content: "hi"
+    dst:
+      path: "README.md"
+      conflict: explode
+  - type: frobnicate
+`,
+			},
+			Expected: []string{
+				"generator.yaml:5:16: values[0]: undefined transform [Name: shout]",
+				"generator.yaml:12:17: tasks[0].dst.conflict: value must be one of 'keep', 'replace', 'prompt', 'merge'",
+				"generator.yaml:13:11: tasks[1]: unknown task type: frobnicate",
+			},
+		},
+		{
+			Desc: "reports template errors",
+			Files: map[string]any{
+				"generator.yaml": `
+name: bad
+values:
+  - key: Name
+  - key: Other
+    default: "{{ .Nope }}"
+tasks:
+  - type: create
+    src:
+      content: |
+        hello
+        {{ .Name | bogus }}
+    dst:
+      path: "{{ .Typo }}"
+  - type: create
+    src:
+      path: "missing.txt"
+    dst:
+      path: "out.txt"
+`,
+				"_src/tpl.txt": "line one\n{{ with .Name }}{{ .Length }}{{ end }}{{ $.Missing }}\n",
+			},
+			Expected: []string{
+				"_src/tpl.txt:2:42: undefined value: .Missing",
+				"generator.yaml:6:14: undefined value: .Nope",
+				"generator.yaml:12: function \"bogus\" not defined",
+				"generator.yaml:14:13: undefined value: .Typo",
+				"generator.yaml:17:13: tasks[1].src.path: missing.txt does not exist in _src",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Desc, func(t *testing.T) {
+			testutil.InTempDir(t, func(tmpDir string) {
+				testutil.WritePaths(t, tmpDir, tt.Files)
+
+				problems, err := ValidateGenerator(NewTestStore(), tmpDir)
+				require.NoError(t, err)
+
+				actual := []string{}
+				for _, p := range problems {
+					rel, _ := filepath.Rel(tmpDir, p.Path)
+					p.Path = rel
+					actual = append(actual, p.String())
+				}
+				assert.Equal(t, tt.Expected, actual)
+			})
+		})
+	}
+}
+
+func TestValidateGenerator_MissingMetadata(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		_, err := ValidateGenerator(NewTestStore(), tmpDir)
+		assert.ErrorContains(t, err, "validate:")
+	})
+}
+
+func TestValidateGenerator_TestData(t *testing.T) {
+	store := NewTestStore()
+	for _, name := range []string{"file", "delegating", "generator"} {
+		problems, err := ValidateGenerator(store, filepath.Join(store.BasePath, name))
+		assert.NoError(t, err)
+		assert.Empty(t, problems, "%s should be valid", name)
+	}
+}
@@ -116,6 +116,15 @@ func RegisteredTransformers() []Transformer {
 	return ts
 }
 
+// ValidateTransformRule returns an error if rule references an undefined transform.
+func ValidateTransformRule(key string, rule string) error {
+	if rule == "" {
+		return nil
+	}
+	_, err := parseTransformRule(key, rule)
+	return err
+}
+
 func parseTransformRule(key string, rule string) ([]TransformerFunc, error) {
 	tfs := []TransformerFunc{}
 	rules := strings.Split(strings.TrimSpace(rule), ",")
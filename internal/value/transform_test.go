@@ -115,3 +115,9 @@ func TestRegisteredTransformers(t *testing.T) {
 	ts := RegisteredTransformers()
 	assert.NotEmpty(t, ts)
 }
+
+func TestValidateTransformRule(t *testing.T) {
+	assert.NoError(t, ValidateTransformRule("my-val", ""))
+	assert.NoError(t, ValidateTransformRule("my-val", "trim, uppercase"))
+	assert.ErrorIs(t, ValidateTransformRule("my-val", "trim,unknown"), ErrUnknownTransformer)
+}
@@ -224,6 +224,16 @@ func (v *Value) IsEmpty() bool {
 	}
 }
 
+// IsRequired returns true if the validation rules include `required`.
+func (v *Value) IsRequired() bool {
+	for _, rule := range strings.Split(v.ValidationRules, ",") {
+		if strings.TrimSpace(rule) == "required" {
+			return true
+		}
+	}
+	return false
+}
+
 // ShouldPrompt returns true if the user should be prompted for a value.
 func (v *Value) ShouldPrompt() bool {
 	if !v.IsEnabled() {
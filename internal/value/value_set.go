@@ -138,6 +138,18 @@ func (vs *ValueSet) GetAll() map[string]any {
 	return data
 }
 
+// Answers returns the data for each value that has been explicitly set
+// (by flag, arg, prompt, etc), omitting those left to their defaults.
+func (vs *ValueSet) Answers() map[string]any {
+	data := map[string]any{}
+	for _, val := range vs.All() {
+		if !val.IsUnset() {
+			data[val.Key] = val.Get()
+		}
+	}
+	return data
+}
+
 // Set sets the value data for key.
 // If key is not found, then sets the data in the cache
 // so that it can be used by other values
@@ -184,6 +196,20 @@ func (vs *ValueSet) Prompt(prompter ui.Prompter) error {
 	return nil
 }
 
+// Missing returns the keys of any empty values that would either prompt
+// the user or are required (i.e. the values that must be supplied
+// when prompting is not an option).
+func (vs *ValueSet) Missing() []string {
+	_ = vs.GetAll() // workaround for cache invalidation issue
+	keys := []string{}
+	for _, val := range vs.All() {
+		if val.IsEnabled() && val.IsEmpty() && (val.ShouldPrompt() || val.IsRequired()) {
+			keys = append(keys, val.Key)
+		}
+	}
+	return keys
+}
+
 // Validate calls Value.Validate() for each value in the set.
 // Returns the first error received.
 func (vs *ValueSet) Validate() error {
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/twelvelabs/termite/ui"
 )
 
@@ -523,3 +524,99 @@ func TestValueSet_Prompt(t *testing.T) {
 		})
 	}
 }
+
+func TestValueSet_Missing(t *testing.T) {
+	vs := NewValueSet()
+	vs.Add(&Value{
+		Key:          "unset",
+		DataType:     DataTypeString,
+		If:           "true",
+		PromptConfig: PromptConfigOnUnset,
+	})
+	vs.Add(&Value{
+		Key:          "set",
+		DataType:     DataTypeString,
+		If:           "true",
+		PromptConfig: PromptConfigAlways,
+	})
+	vs.Add(&Value{
+		Key:          "empty",
+		DataType:     DataTypeString,
+		If:           "true",
+		PromptConfig: PromptConfigOnEmpty,
+	})
+	vs.Add(&Value{
+		Key:          "defaulted",
+		DataType:     DataTypeString,
+		Default:      "foo",
+		If:           "true",
+		PromptConfig: PromptConfigOnEmpty,
+	})
+	vs.Add(&Value{
+		Key:          "never",
+		DataType:     DataTypeString,
+		If:           "true",
+		PromptConfig: PromptConfigNever,
+	})
+	vs.Add(&Value{
+		Key:          "disabled",
+		DataType:     DataTypeString,
+		If:           "false",
+		PromptConfig: PromptConfigOnUnset,
+	})
+	vs.Add(&Value{
+		Key:          "always",
+		DataType:     DataTypeString,
+		If:           "true",
+		PromptConfig: PromptConfigAlways,
+	})
+	vs.Add(&Value{
+		Key:             "required",
+		DataType:        DataTypeString,
+		If:              "true",
+		PromptConfig:    PromptConfigOnUnset,
+		ValidationRules: "required",
+	})
+	vs.Add(&Value{
+		Key:          "unset-defaulted",
+		DataType:     DataTypeString,
+		Default:      "foo",
+		If:           "true",
+		PromptConfig: PromptConfigOnUnset,
+	})
+	require.NoError(t, vs.Set("set", "foo"))
+
+	assert.Equal(t, []string{"unset", "empty", "always", "required"}, vs.Missing())
+
+	// Set-but-empty values should still be reported if they are required.
+	require.NoError(t, vs.Set("always", "bar"))
+	require.NoError(t, vs.Set("required", "  "))
+	assert.Equal(t, []string{"unset", "empty", "required"}, vs.Missing())
+}
+
+func TestValueSet_Answers(t *testing.T) {
+	vs := NewValueSet()
+	vs.Add(&Value{
+		Key:      "name",
+		DataType: DataTypeString,
+		Default:  "foo",
+	})
+	vs.Add(&Value{
+		Key:      "tags",
+		DataType: DataTypeStringSlice,
+	})
+	vs.Add(&Value{
+		Key:      "enabled",
+		DataType: DataTypeBool,
+		Default:  true,
+	})
+	assert.Equal(t, map[string]any{}, vs.Answers())
+
+	require.NoError(t, vs.Set("tags", "a, b"))
+	require.NoError(t, vs.Set("enabled", true))
+	require.NoError(t, vs.Set("NotAValue", "ignored"))
+	assert.Equal(t, map[string]any{
+		"tags":    []string{"a", "b"},
+		"enabled": true,
+	}, vs.Answers(), "should only contain explicitly set values")
+}
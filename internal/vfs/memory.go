@@ -0,0 +1,510 @@
+package vfs
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// maxLinks is the number of symlinks followed before giving up.
+const maxLinks = 40
+
+// NewMemory returns a new in-memory FS layered over base.
+// If base is nil, the FS starts out empty.
+func NewMemory(base FS) *Memory {
+	if base == nil {
+		base = emptyFS{}
+	}
+	return &Memory{
+		base:  base,
+		nodes: map[string]*memNode{},
+	}
+}
+
+// Memory is an in-memory FS layered over a base FS.
+//
+// Paths that have not been changed are read from the base,
+// but all changes (writes, chmods, removals) are kept in memory.
+// Use [Memory.Changed] to inspect the changed paths and [Memory.Commit]
+// to apply them to the base.
+type Memory struct {
+	mu    sync.RWMutex
+	base  FS
+	nodes map[string]*memNode
+}
+
+// memNode is a path that has been changed in memory.
+type memNode struct {
+	mode    fs.FileMode
+	data    []byte
+	link    string
+	modTime time.Time
+
+	// deleted marks a removed path (hiding the base path, if any).
+	deleted bool
+	// opaque marks a dir that replaced a removed path,
+	// and so should not expose the contents of the base dir.
+	opaque bool
+}
+
+// Changed returns the sorted paths that have been written, created,
+// or removed since the FS was created (or last committed).
+func (m *Memory) Changed() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.changed()
+}
+
+// Commit applies all changes to the base FS, parents first,
+// then clears them from memory.
+func (m *Memory) Commit() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, path := range m.changed() {
+		if err := m.commit(path, m.nodes[path]); err != nil {
+			return err
+		}
+		delete(m.nodes, path)
+	}
+	return nil
+}
+
+// Reset discards all changes.
+func (m *Memory) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nodes = map[string]*memNode{}
+}
+
+func (m *Memory) Stat(name string) (fs.FileInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lstat(m.resolve(clean(name)))
+}
+
+func (m *Memory) Lstat(name string) (fs.FileInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lstat(clean(name))
+}
+
+func (m *Memory) ReadFile(name string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	path := m.resolve(clean(name))
+	n, ok := m.nodes[path]
+	if !ok {
+		if m.hidden(path) {
+			return nil, pathError("open", name, fs.ErrNotExist)
+		}
+		return m.base.ReadFile(path)
+	}
+	switch {
+	case n.deleted:
+		return nil, pathError("open", name, fs.ErrNotExist)
+	case n.mode.IsDir():
+		return nil, pathError("read", name, syscall.EISDIR)
+	}
+	return append([]byte{}, n.data...), nil
+}
+
+func (m *Memory) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.readDir(m.resolve(clean(name)))
+}
+
+func (m *Memory) Readlink(name string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	path := clean(name)
+	info, err := m.lstat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.Mode()&fs.ModeSymlink == 0 {
+		return "", pathError("readlink", name, fs.ErrInvalid)
+	}
+	if n, ok := m.nodes[path]; ok {
+		return n.link, nil
+	}
+	return m.base.Readlink(path)
+}
+
+func (m *Memory) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path := m.resolve(clean(name))
+	if err := m.ensureParent("open", path); err != nil {
+		return err
+	}
+	if info, err := m.lstat(path); err == nil {
+		if info.IsDir() {
+			return pathError("open", name, syscall.EISDIR)
+		}
+		perm = info.Mode().Perm() // existing files keep their mode
+	}
+	m.nodes[path] = &memNode{
+		mode:    perm.Perm(),
+		data:    append([]byte{}, data...),
+		modTime: time.Now(),
+	}
+	return nil
+}
+
+func (m *Memory) MkdirAll(path string, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mkdirAll(clean(path), perm)
+}
+
+func (m *Memory) Symlink(oldname string, newname string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path := clean(newname)
+	if _, err := m.lstat(path); err == nil {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: fs.ErrExist}
+	}
+	if err := m.ensureParent("symlink", path); err != nil {
+		return err
+	}
+	m.nodes[path] = &memNode{
+		mode:    fs.ModeSymlink | 0777,
+		link:    oldname,
+		modTime: time.Now(),
+	}
+	return nil
+}
+
+func (m *Memory) Chmod(name string, mode fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path := m.resolve(clean(name))
+	info, err := m.lstat(path)
+	if err != nil {
+		return pathError("chmod", name, fs.ErrNotExist)
+	}
+	n, ok := m.nodes[path]
+	if !ok {
+		// Copy the base path into memory before changing it.
+		n = &memNode{modTime: info.ModTime()}
+		if !info.IsDir() {
+			n.data, err = m.base.ReadFile(path)
+			if err != nil {
+				return err
+			}
+		}
+		m.nodes[path] = n
+	}
+	n.mode = info.Mode().Type() | mode.Perm()
+	return nil
+}
+
+func (m *Memory) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path := clean(name)
+	info, err := m.lstat(path)
+	if err != nil {
+		return pathError("remove", name, fs.ErrNotExist)
+	}
+	if info.IsDir() {
+		entries, err := m.readDir(path)
+		if err != nil {
+			return err
+		}
+		if len(entries) > 0 {
+			return pathError("remove", name, syscall.ENOTEMPTY)
+		}
+	}
+	m.remove(path)
+	return nil
+}
+
+func (m *Memory) RemoveAll(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cleaned := clean(path)
+	if _, err := m.lstat(cleaned); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	m.remove(cleaned)
+	return nil
+}
+
+func (m *Memory) lstat(path string) (fs.FileInfo, error) {
+	if n, ok := m.nodes[path]; ok {
+		if n.deleted {
+			return nil, pathError("lstat", path, fs.ErrNotExist)
+		}
+		return n.info(path), nil
+	}
+	if m.hidden(path) {
+		return nil, pathError("lstat", path, fs.ErrNotExist)
+	}
+	info, err := m.base.Lstat(path)
+	if err != nil && path == filepath.Dir(path) {
+		// The root dir always exists.
+		return (&memNode{mode: fs.ModeDir | 0755}).info(path), nil
+	}
+	return info, err
+}
+
+// resolve follows the symlink (if any) at path.
+func (m *Memory) resolve(path string) string {
+	for i := 0; i < maxLinks; i++ {
+		info, err := m.lstat(path)
+		if err != nil || info.Mode()&fs.ModeSymlink == 0 {
+			return path
+		}
+		link := ""
+		if n, ok := m.nodes[path]; ok {
+			link = n.link
+		} else if link, err = m.base.Readlink(path); err != nil {
+			return path
+		}
+		if !filepath.IsAbs(link) {
+			link = filepath.Join(filepath.Dir(path), link)
+		}
+		path = filepath.Clean(link)
+	}
+	return path
+}
+
+// hidden returns true if the base path is masked by one of
+// the in-memory ancestors of path.
+func (m *Memory) hidden(path string) bool {
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		if n, ok := m.nodes[dir]; ok && (n.deleted || n.opaque || !n.mode.IsDir()) {
+			return true
+		}
+		if dir == filepath.Dir(dir) {
+			return false
+		}
+	}
+}
+
+func (m *Memory) readDir(path string) ([]fs.DirEntry, error) {
+	info, err := m.lstat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, pathError("readdirent", path, syscall.ENOTDIR)
+	}
+
+	byName := map[string]fs.DirEntry{}
+	if n, ok := m.nodes[path]; (!ok || !n.opaque) && !m.hidden(path) {
+		entries, err := m.base.ReadDir(path)
+		if err != nil && !ok {
+			return nil, err
+		}
+		for _, entry := range entries {
+			byName[entry.Name()] = entry
+		}
+	}
+	for child, n := range m.nodes {
+		if filepath.Dir(child) != path || child == path {
+			continue
+		}
+		name := filepath.Base(child)
+		if n.deleted {
+			delete(byName, name)
+		} else {
+			byName[name] = fs.FileInfoToDirEntry(n.info(child))
+		}
+	}
+
+	entries := make([]fs.DirEntry, 0, len(byName))
+	for _, entry := range byName {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nil
+}
+
+func (m *Memory) mkdirAll(path string, perm fs.FileMode) error {
+	info, err := m.lstat(m.resolve(path))
+	if err == nil {
+		if info.IsDir() {
+			return nil
+		}
+		return pathError("mkdir", path, syscall.ENOTDIR)
+	}
+	if parent := filepath.Dir(path); parent != path {
+		if err := m.mkdirAll(parent, perm); err != nil {
+			return err
+		}
+	}
+	prev, ok := m.nodes[path]
+	m.nodes[path] = &memNode{
+		mode:    fs.ModeDir | perm.Perm(),
+		modTime: time.Now(),
+		opaque:  ok && prev.deleted,
+	}
+	return nil
+}
+
+func (m *Memory) ensureParent(op string, path string) error {
+	info, err := m.lstat(m.resolve(filepath.Dir(path)))
+	if err != nil {
+		return pathError(op, path, fs.ErrNotExist)
+	}
+	if !info.IsDir() {
+		return pathError(op, path, syscall.ENOTDIR)
+	}
+	return nil
+}
+
+// remove marks path (and everything beneath it) as deleted.
+func (m *Memory) remove(path string) {
+	prefix := path + string(filepath.Separator)
+	for child := range m.nodes {
+		if strings.HasPrefix(child, prefix) {
+			delete(m.nodes, child)
+		}
+	}
+	if n, ok := m.nodes[path]; !m.hidden(path) && (!ok || n.opaque || m.inBase(path)) {
+		m.nodes[path] = &memNode{deleted: true}
+	} else {
+		// Only ever existed in memory.
+		delete(m.nodes, path)
+	}
+}
+
+func (m *Memory) inBase(path string) bool {
+	_, err := m.base.Lstat(path)
+	return err == nil
+}
+
+func (m *Memory) changed() []string {
+	paths := make([]string, 0, len(m.nodes))
+	for path := range m.nodes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (m *Memory) commit(path string, n *memNode) error {
+	info, _ := m.base.Lstat(path)
+	switch {
+	case n.deleted:
+		return m.base.RemoveAll(path)
+	case n.mode.IsDir():
+		if info != nil && (n.opaque || !info.IsDir()) {
+			if err := m.base.RemoveAll(path); err != nil {
+				return err
+			}
+		}
+		if err := m.base.MkdirAll(path, n.mode.Perm()); err != nil {
+			return err
+		}
+		return m.base.Chmod(path, n.mode.Perm())
+	case n.mode&fs.ModeSymlink != 0:
+		if err := m.base.RemoveAll(path); err != nil {
+			return err
+		}
+		return m.base.Symlink(n.link, path)
+	default:
+		if info != nil && (info.IsDir() || info.Mode()&fs.ModeSymlink != 0) {
+			if err := m.base.RemoveAll(path); err != nil {
+				return err
+			}
+		}
+		if err := m.base.WriteFile(path, n.data, n.mode.Perm()); err != nil {
+			return err
+		}
+		return m.base.Chmod(path, n.mode.Perm())
+	}
+}
+
+func (n *memNode) info(path string) fs.FileInfo {
+	return &memFileInfo{
+		name:    filepath.Base(path),
+		size:    int64(len(n.data)),
+		mode:    n.mode,
+		modTime: n.modTime,
+	}
+}
+
+type memFileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (fi *memFileInfo) Name() string       { return fi.name }
+func (fi *memFileInfo) Size() int64        { return fi.size }
+func (fi *memFileInfo) Mode() fs.FileMode  { return fi.mode }
+func (fi *memFileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *memFileInfo) IsDir() bool        { return fi.mode.IsDir() }
+func (fi *memFileInfo) Sys() any           { return nil }
+
+// emptyFS is the base of a Memory FS created without one.
+type emptyFS struct{}
+
+func (emptyFS) Stat(name string) (fs.FileInfo, error) {
+	return nil, pathError("stat", name, fs.ErrNotExist)
+}
+func (emptyFS) Lstat(name string) (fs.FileInfo, error) {
+	return nil, pathError("lstat", name, fs.ErrNotExist)
+}
+func (emptyFS) ReadFile(name string) ([]byte, error) {
+	return nil, pathError("open", name, fs.ErrNotExist)
+}
+func (emptyFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return nil, pathError("open", name, fs.ErrNotExist)
+}
+func (emptyFS) Readlink(name string) (string, error) {
+	return "", pathError("readlink", name, fs.ErrNotExist)
+}
+func (emptyFS) WriteFile(name string, _ []byte, _ fs.FileMode) error {
+	return pathError("open", name, fs.ErrPermission)
+}
+func (emptyFS) MkdirAll(path string, _ fs.FileMode) error {
+	return pathError("mkdir", path, fs.ErrPermission)
+}
+func (emptyFS) Symlink(_ string, newname string) error {
+	return pathError("symlink", newname, fs.ErrPermission)
+}
+func (emptyFS) Chmod(name string, _ fs.FileMode) error {
+	return pathError("chmod", name, fs.ErrPermission)
+}
+func (emptyFS) Remove(name string) error {
+	return pathError("remove", name, fs.ErrPermission)
+}
+func (emptyFS) RemoveAll(path string) error {
+	return pathError("remove", path, fs.ErrPermission)
+}
+
+func clean(name string) string {
+	if abs, err := filepath.Abs(name); err == nil {
+		return abs
+	}
+	return filepath.Clean(name)
+}
+
+func pathError(op string, path string, err error) error {
+	return &fs.PathError{Op: op, Path: path, Err: err}
+}
@@ -0,0 +1,179 @@
+package vfs
+
+import (
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twelvelabs/termite/testutil"
+)
+
+func TestMemory_ReadsFallThroughToBase(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"dir/a.txt": "a",
+			"dir/b.txt": "b",
+		})
+		m := NewMemory(NewOS())
+
+		buf, err := m.ReadFile(filepath.Join(tmpDir, "dir", "a.txt"))
+		assert.NoError(t, err)
+		assert.Equal(t, "a", string(buf))
+		assert.True(t, IsDir(m, filepath.Join(tmpDir, "dir")))
+		assert.False(t, Exists(m, filepath.Join(tmpDir, "missing")))
+		assert.Empty(t, m.Changed())
+	})
+}
+
+func TestMemory_WritesStayInMemory(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"dir/a.txt": "a",
+			"dir/b.txt": "b",
+		})
+		m := NewMemory(NewOS())
+		dir := filepath.Join(tmpDir, "dir")
+
+		require.NoError(t, m.WriteFile(filepath.Join(dir, "a.txt"), []byte("changed"), 0600))
+		require.NoError(t, m.MkdirAll(filepath.Join(dir, "sub"), 0755))
+		require.NoError(t, m.WriteFile(filepath.Join(dir, "sub", "c.txt"), []byte("c"), 0600))
+		require.NoError(t, m.Remove(filepath.Join(dir, "b.txt")))
+
+		buf, err := m.ReadFile(filepath.Join(dir, "a.txt"))
+		assert.NoError(t, err)
+		assert.Equal(t, "changed", string(buf))
+		assert.False(t, Exists(m, filepath.Join(dir, "b.txt")))
+
+		entries, err := m.ReadDir(dir)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a.txt", "sub"}, entryNames(entries))
+
+		assert.Equal(t, []string{
+			filepath.Join(dir, "a.txt"),
+			filepath.Join(dir, "b.txt"),
+			filepath.Join(dir, "sub"),
+			filepath.Join(dir, "sub", "c.txt"),
+		}, m.Changed())
+
+		// Nothing on disk has changed.
+		testutil.AssertPaths(t, tmpDir, map[string]any{
+			"dir/a.txt":     "a",
+			"dir/b.txt":     "b",
+			"dir/sub/c.txt": false,
+		})
+
+		require.NoError(t, m.Commit())
+		assert.Empty(t, m.Changed())
+		testutil.AssertPaths(t, tmpDir, map[string]any{
+			"dir/a.txt":     "changed",
+			"dir/b.txt":     false,
+			"dir/sub/c.txt": "c",
+		})
+	})
+}
+
+func TestMemory_RemoveAllHidesBaseContents(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"dir/a.txt":     "a",
+			"dir/sub/b.txt": "b",
+		})
+		m := NewMemory(NewOS())
+		dir := filepath.Join(tmpDir, "dir")
+
+		require.NoError(t, m.RemoveAll(dir))
+		assert.False(t, Exists(m, filepath.Join(dir, "sub", "b.txt")))
+		assert.NoError(t, m.RemoveAll(dir), "should be a noop for missing paths")
+
+		// Recreated dirs should not expose the removed base contents.
+		require.NoError(t, m.MkdirAll(dir, 0755))
+		require.NoError(t, m.WriteFile(filepath.Join(dir, "c.txt"), []byte("c"), 0600))
+		entries, err := m.ReadDir(dir)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"c.txt"}, entryNames(entries))
+
+		require.NoError(t, m.Commit())
+		testutil.AssertPaths(t, tmpDir, map[string]any{
+			"dir/a.txt":     false,
+			"dir/sub/b.txt": false,
+			"dir/c.txt":     "c",
+		})
+	})
+}
+
+func TestMemory_Errors(t *testing.T) {
+	m := NewMemory(nil)
+	root, _ := filepath.Abs("/")
+
+	assert.True(t, IsDir(m, root))
+	assert.ErrorIs(t, m.WriteFile(filepath.Join(root, "missing", "a.txt"), nil, 0600), fs.ErrNotExist)
+
+	dir := filepath.Join(root, "dir")
+	require.NoError(t, m.MkdirAll(dir, 0755))
+	require.NoError(t, m.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0600))
+
+	assert.Error(t, m.Remove(dir), "should not remove non-empty dirs")
+	assert.Error(t, m.MkdirAll(filepath.Join(dir, "a.txt"), 0755))
+	_, err := m.ReadFile(dir)
+	assert.Error(t, err)
+
+	err = m.Symlink("a.txt", filepath.Join(dir, "a.txt"))
+	assert.ErrorIs(t, err, fs.ErrExist)
+
+	assert.ErrorIs(t, m.Commit(), fs.ErrPermission, "should not commit without a base")
+}
+
+func TestMemory_Symlinks(t *testing.T) {
+	m := NewMemory(nil)
+	dir, _ := filepath.Abs("/dir")
+	require.NoError(t, m.MkdirAll(dir, 0755))
+	require.NoError(t, m.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0600))
+	require.NoError(t, m.Symlink("a.txt", filepath.Join(dir, "link")))
+
+	link, err := m.Readlink(filepath.Join(dir, "link"))
+	assert.NoError(t, err)
+	assert.Equal(t, "a.txt", link)
+
+	info, err := m.Lstat(filepath.Join(dir, "link"))
+	assert.NoError(t, err)
+	assert.Equal(t, fs.ModeSymlink, info.Mode().Type())
+
+	buf, err := m.ReadFile(filepath.Join(dir, "link"))
+	assert.NoError(t, err)
+	assert.Equal(t, "a", string(buf))
+}
+
+func TestMemory_Chmod(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"a.txt": "a",
+		})
+		m := NewMemory(NewOS())
+		path := filepath.Join(tmpDir, "a.txt")
+
+		require.NoError(t, m.Chmod(path, 0700))
+		info, err := m.Stat(path)
+		assert.NoError(t, err)
+		assert.Equal(t, fs.FileMode(0700), info.Mode())
+		assert.Equal(t, int64(1), info.Size())
+
+		require.NoError(t, m.WriteFile(path, []byte("changed"), 0600))
+		info, _ = m.Stat(path)
+		assert.Equal(t, fs.FileMode(0700), info.Mode(), "should keep the mode of existing files")
+
+		m.Reset()
+		assert.Empty(t, m.Changed())
+		buf, _ := m.ReadFile(path)
+		assert.Equal(t, "a", string(buf))
+	})
+}
+
+func entryNames(entries []fs.DirEntry) []string {
+	names := []string{}
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
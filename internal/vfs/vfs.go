@@ -0,0 +1,183 @@
+// Package vfs provides the filesystem abstraction used when executing tasks.
+package vfs
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// FS is a writable filesystem addressed by OS paths.
+// Method semantics match the equivalently named functions in the os package.
+type FS interface {
+	Stat(name string) (fs.FileInfo, error)
+	Lstat(name string) (fs.FileInfo, error)
+	ReadFile(name string) ([]byte, error)
+	ReadDir(name string) ([]fs.DirEntry, error)
+	Readlink(name string) (string, error)
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	MkdirAll(path string, perm fs.FileMode) error
+	Symlink(oldname string, newname string) error
+	Chmod(name string, mode fs.FileMode) error
+	Remove(name string) error
+	RemoveAll(path string) error
+}
+
+// NewOS returns an FS backed by the real OS filesystem.
+func NewOS() FS {
+	return osFS{}
+}
+
+type osFS struct{}
+
+// IsOS returns true if fsys is the real OS filesystem
+// (i.e. changes are visible to other processes).
+func IsOS(fsys FS) bool {
+	_, ok := fsys.(osFS)
+	return ok
+}
+
+func (osFS) Stat(name string) (fs.FileInfo, error)      { return os.Stat(name) }
+func (osFS) Lstat(name string) (fs.FileInfo, error)     { return os.Lstat(name) }
+func (osFS) ReadFile(name string) ([]byte, error)       { return os.ReadFile(name) }
+func (osFS) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+func (osFS) Readlink(name string) (string, error)       { return os.Readlink(name) }
+func (osFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
+func (osFS) MkdirAll(path string, perm fs.FileMode) error { return os.MkdirAll(path, perm) }
+func (osFS) Symlink(oldname string, newname string) error { return os.Symlink(oldname, newname) }
+func (osFS) Chmod(name string, mode fs.FileMode) error    { return os.Chmod(name, mode) }
+func (osFS) Remove(name string) error                     { return os.Remove(name) }
+func (osFS) RemoveAll(path string) error                  { return os.RemoveAll(path) }
+func (osFS) Rename(oldpath string, newpath string) error  { return os.Rename(oldpath, newpath) }
+
+// renamer is implemented by filesystems that can move paths natively.
+type renamer interface {
+	Rename(oldpath string, newpath string) error
+}
+
+// Exists returns true if path exists in fsys.
+func Exists(fsys FS, path string) bool {
+	_, err := fsys.Stat(path)
+	return !errors.Is(err, fs.ErrNotExist) && !errors.Is(err, fs.ErrInvalid)
+}
+
+// IsDir returns true if path is a dir in fsys.
+func IsDir(fsys FS, path string) bool {
+	info, _ := fsys.Stat(path)
+	return info != nil && info.IsDir()
+}
+
+// Move moves oldpath (a file, symlink, or dir) to newpath in fsys,
+// creating any missing parent dirs of newpath.
+// Filesystems that can not rename paths natively (or across devices)
+// have oldpath copied to newpath and then removed.
+func Move(fsys FS, oldpath string, newpath string) error {
+	if err := fsys.MkdirAll(filepath.Dir(newpath), 0755); err != nil {
+		return err
+	}
+	if r, ok := fsys.(renamer); ok {
+		if err := r.Rename(oldpath, newpath); err == nil {
+			return nil
+		}
+	}
+	if err := Copy(fsys, oldpath, newpath); err != nil {
+		return err
+	}
+	return fsys.RemoveAll(oldpath)
+}
+
+// Copy recursively copies src (a file, symlink, or dir) to dst in fsys.
+// Symlinks are copied as-is and file modes are preserved.
+func Copy(fsys FS, src string, dst string) error {
+	return WalkDir(fsys, src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := fsys.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return fsys.Symlink(link, target)
+		case info.IsDir():
+			if err := fsys.MkdirAll(target, info.Mode().Perm()); err != nil {
+				return err
+			}
+			return fsys.Chmod(target, info.Mode().Perm())
+		default:
+			data, err := fsys.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if err := fsys.WriteFile(target, data, info.Mode().Perm()); err != nil {
+				return err
+			}
+			return fsys.Chmod(target, info.Mode().Perm())
+		}
+	})
+}
+
+// WalkDir walks the file tree rooted at root in fsys,
+// calling fn for each file or dir (including root).
+// Behaves the same as [filepath.WalkDir].
+func WalkDir(fsys FS, root string, fn fs.WalkDirFunc) error {
+	info, err := fsys.Lstat(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = walkDir(fsys, root, fs.FileInfoToDirEntry(info), fn)
+	}
+	if errors.Is(err, filepath.SkipDir) || errors.Is(err, filepath.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+func walkDir(fsys FS, path string, d fs.DirEntry, fn fs.WalkDirFunc) error {
+	if err := fn(path, d, nil); err != nil || !d.IsDir() {
+		if errors.Is(err, filepath.SkipDir) && d.IsDir() {
+			err = nil
+		}
+		return err
+	}
+
+	entries, err := fsys.ReadDir(path)
+	if err != nil {
+		// Second call, to report the ReadDir error.
+		err = fn(path, d, err)
+		if err != nil {
+			if errors.Is(err, filepath.SkipDir) && d.IsDir() {
+				err = nil
+			}
+			return err
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	for _, entry := range entries {
+		if err := walkDir(fsys, filepath.Join(path, entry.Name()), entry, fn); err != nil {
+			if errors.Is(err, filepath.SkipDir) {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package vfs
+
+import (
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twelvelabs/termite/testutil"
+)
+
+func TestExists(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		fsys := NewOS()
+		assert.False(t, Exists(fsys, "foo.txt"))
+		assert.False(t, IsDir(fsys, "foo.txt"))
+
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"foo.txt":     "",
+			"bar/baz.txt": "",
+		})
+		assert.True(t, Exists(fsys, "foo.txt"))
+		assert.False(t, IsDir(fsys, "foo.txt"))
+		assert.True(t, IsDir(fsys, "bar"))
+	})
+}
+
+func TestIsOS(t *testing.T) {
+	assert.True(t, IsOS(NewOS()))
+	assert.False(t, IsOS(NewMemory(NewOS())))
+}
+
+func TestWalkDir(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"root/b.txt":       "",
+			"root/a/1.txt":     "",
+			"root/a/2.txt":     "",
+			"root/skip/3.txt":  "",
+			"root/other/4.txt": "",
+		})
+		root := filepath.Join(tmpDir, "root")
+
+		for _, fsys := range []FS{NewOS(), NewMemory(NewOS())} {
+			walked := []string{}
+			err := WalkDir(fsys, root, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				rel, _ := filepath.Rel(tmpDir, path)
+				walked = append(walked, filepath.ToSlash(rel))
+				if d.IsDir() && d.Name() == "skip" {
+					return fs.SkipDir
+				}
+				return nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, []string{
+				"root",
+				"root/a",
+				"root/a/1.txt",
+				"root/a/2.txt",
+				"root/b.txt",
+				"root/other",
+				"root/other/4.txt",
+				"root/skip",
+			}, walked)
+		}
+
+		err := WalkDir(NewOS(), filepath.Join(tmpDir, "missing"), func(_ string, _ fs.DirEntry, err error) error {
+			return err
+		})
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
+}
+
+func TestMove(t *testing.T) {
+	testutil.InTempDir(t, func(tmpDir string) {
+		for _, fsys := range []FS{NewOS(), NewMemory(NewOS())} {
+			testutil.WritePaths(t, tmpDir, map[string]any{
+				"src/a.txt":     "a",
+				"src/sub/b.txt": "b",
+			})
+			src := filepath.Join(tmpDir, "src")
+			dst := filepath.Join(tmpDir, "nested", "dst")
+			require.NoError(t, fsys.Chmod(filepath.Join(src, "a.txt"), 0700))
+			require.NoError(t, fsys.Symlink("a.txt", filepath.Join(src, "link")))
+
+			require.NoError(t, Move(fsys, src, dst))
+			assert.False(t, Exists(fsys, src))
+
+			buf, err := fsys.ReadFile(filepath.Join(dst, "sub", "b.txt"))
+			assert.NoError(t, err)
+			assert.Equal(t, "b", string(buf))
+			info, err := fsys.Stat(filepath.Join(dst, "a.txt"))
+			assert.NoError(t, err)
+			assert.Equal(t, fs.FileMode(0700), info.Mode())
+			link, err := fsys.Readlink(filepath.Join(dst, "link"))
+			assert.NoError(t, err)
+			assert.Equal(t, "a.txt", link)
+
+			require.NoError(t, fsys.RemoveAll(filepath.Join(tmpDir, "nested")))
+		}
+	})
+}
@@ -0,0 +1,182 @@
+package stamp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/twelvelabs/termite/ui"
+
+	"github.com/twelvelabs/stamp/internal/fsutil"
+	core "github.com/twelvelabs/stamp/internal/stamp"
+)
+
+// Generator is a generator loaded from a [Store].
+//
+// Values are stateful: create a new generator (via [Store.Load])
+// for each run that needs a different set of values.
+type Generator struct {
+	store *Store
+	gen   *core.Generator
+}
+
+// Value describes a generator input value.
+type Value struct {
+	Key      string
+	Name     string
+	Help     string
+	DataType string
+	Default  any
+}
+
+// Result is the outcome of a [Generator.Run].
+type Result struct {
+	// Changes lists every file created, updated, or deleted by the run.
+	// Always empty for dry runs (use [WithFS] and a [MemoryFS] to preview changes instead).
+	Changes []FileChange
+}
+
+func newGenerator(store *Store, gen *core.Generator) *Generator {
+	return &Generator{
+		store: store,
+		gen:   gen,
+	}
+}
+
+// Name returns the generator name.
+func (g *Generator) Name() string {
+	return g.gen.Name()
+}
+
+// Description returns the generator description.
+func (g *Generator) Description() string {
+	return g.gen.Description()
+}
+
+// Version returns the (optional) generator version.
+func (g *Generator) Version() string {
+	return g.gen.Version()
+}
+
+// Path returns the dir containing the generator.
+func (g *Generator) Path() string {
+	return g.gen.Path()
+}
+
+// Values returns the generator's input values.
+func (g *Generator) Values() []Value {
+	values := []Value{}
+	for _, v := range g.gen.Values.All() {
+		values = append(values, Value{
+			Key:      v.Key,
+			Name:     v.DisplayName(),
+			Help:     v.Help,
+			DataType: v.DataType.String(),
+			Default:  v.Default,
+		})
+	}
+	return values
+}
+
+// Get returns the current data for the value key.
+func (g *Generator) Get(key string) any {
+	return g.gen.Values.Get(key)
+}
+
+// Set sets the data for the value key.
+// Data is processed (cast, transformed, and validated) the same way
+// as values entered on the command line.
+func (g *Generator) Set(key string, data any) error {
+	if g.gen.Values.Value(key) == nil {
+		return fmt.Errorf("unknown value: %s", key)
+	}
+	if err := g.gen.Values.Set(key, data); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	return nil
+}
+
+// SetAll calls [Generator.Set] for each key in values.
+func (g *Generator) SetAll(values map[string]any) error {
+	for key, data := range values {
+		if err := g.Set(key, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run executes the generator's tasks against the dst dir.
+//
+// Like the CLI, a manifest and journal of the run are recorded in
+// the `.stamp/` dir of the destination, and the destination is restored
+// to its original state if any task fails.
+func (g *Generator) Run(dst string, opts ...Option) (*Result, error) {
+	o := newRunOptions(opts)
+
+	// Symlinks are resolved to match the paths recorded in the journal.
+	dstPath, err := fsutil.ResolvePath(dst)
+	if err != nil {
+		return nil, err
+	}
+	if g.gen.Values.Value("DstPath") != nil {
+		if err := g.gen.Values.Set("DstPath", dstPath); err != nil {
+			return nil, err
+		}
+	}
+
+	if o.prompter == nil {
+		if missing := g.gen.Values.Missing(); len(missing) > 0 {
+			return nil, fmt.Errorf("%w: missing values for %s", ErrNoInput, strings.Join(missing, ", "))
+		}
+	} else if err := g.gen.Values.Prompt(o.prompter); err != nil {
+		return nil, err
+	}
+	if err := g.gen.Values.Validate(); err != nil {
+		return nil, err
+	}
+
+	ctx := core.NewTaskContext(g.newApp(o))
+	ctx.Logger = o.logger
+	ctx.ConflictOverride = o.conflictOverride
+
+	values := g.gen.Values.GetAll()
+	values["DstPath"] = dstPath
+
+	err = g.gen.Execute(ctx, values)
+	if err != nil {
+		if rbErr := ctx.Rollback(); rbErr != nil {
+			err = errors.Join(err, fmt.Errorf("rollback: %w", rbErr))
+		}
+	}
+	ctx.Logger.Summary(err)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Changes: ctx.Journal.Changes(dstPath),
+	}, nil
+}
+
+// newApp returns the app that tasks are run with.
+func (g *Generator) newApp(o *runOptions) *core.App {
+	config, _ := core.NewDefaultConfig()
+	config.DryRun = o.dryRun
+	config.Jobs = o.jobs
+	config.NoInput = o.prompter == nil
+
+	ios := ui.NewIOStreams()
+	userInterface := ui.NewUserInterface(ios)
+	if o.prompter != nil {
+		userInterface.Prompter = o.prompter
+	}
+
+	return &core.App{
+		Config: config,
+		IO:     ios,
+		UI:     userInterface,
+		Store:  g.store.store,
+		FS:     o.fs,
+	}
+}
@@ -0,0 +1,226 @@
+package stamp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twelvelabs/termite/testutil"
+	"github.com/twelvelabs/termite/ui"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	path, err := filepath.Abs(filepath.Join("..", "internal", "stamp", "testdata", "generators"))
+	require.NoError(t, err)
+	return NewStore(path)
+}
+
+type recordingLogger struct {
+	actions []string
+	summary error
+}
+
+func (l *recordingLogger) Info(action string, line string, _ ...any) {
+	l.actions = append(l.actions, action+" "+line)
+}
+func (l *recordingLogger) Success(action string, line string, _ ...any) {
+	l.actions = append(l.actions, action+" "+line)
+}
+func (l *recordingLogger) Warning(action string, line string, _ ...any) {
+	l.actions = append(l.actions, action+" "+line)
+}
+func (l *recordingLogger) Failure(action string, line string, _ ...any) {
+	l.actions = append(l.actions, action+" "+line)
+}
+func (l *recordingLogger) Diff(_ string)                    {}
+func (l *recordingLogger) TaskStarted(_ Task, _ int, _ int) {}
+func (l *recordingLogger) TaskFinished(_ error)             {}
+func (l *recordingLogger) Summary(err error)                { l.summary = err }
+
+type inputPrompter struct {
+	ui.Prompter
+	inputs map[string]string
+}
+
+func (p *inputPrompter) Input(msg string, value string, _ ...ui.PromptOpt) (string, error) {
+	if input, ok := p.inputs[msg]; ok {
+		return input, nil
+	}
+	return value, nil
+}
+
+func TestStore_Load(t *testing.T) {
+	store := newTestStore(t)
+
+	gen, err := store.Load("file")
+	require.NoError(t, err)
+	assert.Equal(t, "file", gen.Name())
+	assert.Equal(t, filepath.Join(store.Path(), "file"), gen.Path())
+	assert.Equal(t, []Value{
+		{Key: "DstPath", Name: "Destination Path", Help: "The path to generate files to.", DataType: "string", Default: "."},
+		{Key: "FileName", Name: "File Name", DataType: "string", Default: "untitled.txt"},
+		{Key: "FileContent", Name: "File Content", DataType: "string", Default: ""},
+	}, gen.Values())
+
+	_, err = store.Load("missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	all, err := store.LoadAll()
+	require.NoError(t, err)
+	assert.NotEmpty(t, all)
+}
+
+func TestGenerator_Set(t *testing.T) {
+	gen, err := newTestStore(t).Load("file")
+	require.NoError(t, err)
+
+	assert.NoError(t, gen.SetAll(map[string]any{"FileName": "hello.txt"}))
+	assert.Equal(t, "hello.txt", gen.Get("FileName"))
+	assert.ErrorContains(t, gen.Set("Unknown", "foo"), "unknown value: Unknown")
+}
+
+func TestGenerator_Run(t *testing.T) {
+	store := newTestStore(t)
+	testutil.InTempDir(t, func(tmpDir string) {
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"existing.txt": "before",
+		})
+
+		gen, err := store.Load("file")
+		require.NoError(t, err)
+		_, err = gen.Run(tmpDir)
+		assert.ErrorIs(t, err, ErrNoInput, "should not prompt without a prompter")
+
+		require.NoError(t, gen.SetAll(map[string]any{
+			"FileName":    "hello.txt",
+			"FileContent": "Hello",
+		}))
+		logger := &recordingLogger{}
+		result, err := gen.Run(tmpDir, WithLogger(logger))
+		require.NoError(t, err)
+		assert.Equal(t, []FileChange{
+			{Path: "hello.txt", Action: FileActionCreate},
+		}, result.Changes)
+		assert.Equal(t, []string{"create hello.txt"}, logger.actions)
+		assert.NoError(t, logger.summary)
+
+		// Conflicts fail (and roll back) unless overridden.
+		gen, _ = store.Load("file")
+		require.NoError(t, gen.SetAll(map[string]any{
+			"FileName":    "existing.txt",
+			"FileContent": "after",
+		}))
+		_, err = gen.Run(tmpDir)
+		assert.ErrorIs(t, err, ErrNoInput)
+
+		result, err = gen.Run(tmpDir, WithConflictOverride(ConflictConfigReplace))
+		require.NoError(t, err)
+		assert.Equal(t, []FileChange{
+			{Path: "existing.txt", Action: FileActionUpdate},
+		}, result.Changes)
+
+		testutil.AssertPaths(t, tmpDir, map[string]any{
+			"hello.txt":    "Hello",
+			"existing.txt": "after",
+		})
+	})
+}
+
+func TestGenerator_RunWithSymlinkedDst(t *testing.T) {
+	store := newTestStore(t)
+	testutil.InTempDir(t, func(tmpDir string) {
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"real/existing.txt": "before",
+		})
+		require.NoError(t, os.Symlink(filepath.Join(tmpDir, "real"), filepath.Join(tmpDir, "link")))
+
+		gen, err := store.Load("file")
+		require.NoError(t, err)
+		require.NoError(t, gen.SetAll(map[string]any{
+			"FileName":    "hello.txt",
+			"FileContent": "Hello",
+		}))
+		result, err := gen.Run(filepath.Join(tmpDir, "link"))
+		require.NoError(t, err)
+		assert.Equal(t, []FileChange{
+			{Path: "hello.txt", Action: FileActionCreate},
+		}, result.Changes)
+		testutil.AssertPaths(t, tmpDir, map[string]any{
+			"real/hello.txt": "Hello",
+		})
+	})
+}
+
+func TestGenerator_RunWithDryRun(t *testing.T) {
+	store := newTestStore(t)
+	testutil.InTempDir(t, func(tmpDir string) {
+		gen, err := store.Load("file")
+		require.NoError(t, err)
+		require.NoError(t, gen.SetAll(map[string]any{
+			"FileName":    "hello.txt",
+			"FileContent": "Hello",
+		}))
+
+		result, err := gen.Run(tmpDir, WithDryRun())
+		require.NoError(t, err)
+		assert.Empty(t, result.Changes)
+		testutil.AssertPaths(t, tmpDir, map[string]any{
+			"hello.txt": false,
+		})
+	})
+}
+
+func TestGenerator_RunWithPrompter(t *testing.T) {
+	store := newTestStore(t)
+	testutil.InTempDir(t, func(tmpDir string) {
+		gen, err := store.Load("file")
+		require.NoError(t, err)
+
+		prompter := &inputPrompter{inputs: map[string]string{
+			"File Name": "prompted.txt",
+		}}
+		result, err := gen.Run(tmpDir, WithPrompter(prompter))
+		require.NoError(t, err)
+		assert.Equal(t, []FileChange{
+			{Path: "prompted.txt", Action: FileActionCreate},
+		}, result.Changes)
+	})
+}
+
+func TestGenerator_RunWithFS(t *testing.T) {
+	store := newTestStore(t)
+	testutil.InTempDir(t, func(tmpDir string) {
+		testutil.WritePaths(t, tmpDir, map[string]any{
+			"existing.txt": "before",
+		})
+
+		gen, err := store.Load("file")
+		require.NoError(t, err)
+		require.NoError(t, gen.SetAll(map[string]any{
+			"FileName":    "existing.txt",
+			"FileContent": "after",
+		}))
+
+		fsys := NewMemoryFS(OSFS())
+		result, err := gen.Run(tmpDir, WithFS(fsys), WithConflictOverride(ConflictConfigReplace))
+		require.NoError(t, err)
+		assert.Equal(t, []FileChange{
+			{Path: "existing.txt", Action: FileActionUpdate},
+		}, result.Changes)
+
+		buf, err := fsys.ReadFile(filepath.Join(tmpDir, "existing.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "after", string(buf))
+		testutil.AssertPaths(t, tmpDir, map[string]any{
+			"existing.txt": "before",
+		})
+
+		require.NoError(t, fsys.Commit())
+		testutil.AssertPaths(t, tmpDir, map[string]any{
+			"existing.txt": "after",
+		})
+	})
+}
@@ -0,0 +1,87 @@
+package stamp
+
+import (
+	core "github.com/twelvelabs/stamp/internal/stamp"
+	"github.com/twelvelabs/stamp/internal/vfs"
+)
+
+// Option configures a [Generator.Run].
+type Option func(o *runOptions)
+
+type runOptions struct {
+	conflictOverride ConflictConfig
+	dryRun           bool
+	fs               FS
+	jobs             int
+	logger           Logger
+	prompter         Prompter
+}
+
+func newRunOptions(opts []Option) *runOptions {
+	o := &runOptions{
+		fs:     vfs.NewOS(),
+		logger: &core.NopLogger{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithConflictOverride resolves conflicts with existing files
+// (for tasks configured to prompt) using c rather than prompting.
+// Only [ConflictConfigKeep] and [ConflictConfigReplace] are supported.
+func WithConflictOverride(c ConflictConfig) Option {
+	return func(o *runOptions) {
+		o.conflictOverride = c
+	}
+}
+
+// WithDryRun runs the generator without modifying the destination.
+func WithDryRun() Option {
+	return func(o *runOptions) {
+		o.dryRun = true
+	}
+}
+
+// WithFS reads and writes files using fsys rather than the OS filesystem.
+//
+// Pass a [MemoryFS] to run a generator without touching disk,
+// inspect the results, and then (optionally) commit them:
+//
+//	fsys := stamp.NewMemoryFS(stamp.OSFS())
+//	result, err := gen.Run("./example", stamp.WithFS(fsys))
+//	if err != nil {
+//		return err
+//	}
+//	// Inspect result.Changes, fsys.ReadFile(...), etc.
+//	return fsys.Commit()
+func WithFS(fsys FS) Option {
+	return func(o *runOptions) {
+		o.fs = fsys
+	}
+}
+
+// WithJobs generates up to n files concurrently.
+// Tasks that modify the same path still run in order,
+// and the [Logger] receives actions in the same order as a sequential run.
+func WithJobs(n int) Option {
+	return func(o *runOptions) {
+		o.jobs = n
+	}
+}
+
+// WithLogger sends task actions to l. By default, they are discarded.
+func WithLogger(l Logger) Option {
+	return func(o *runOptions) {
+		o.logger = l
+	}
+}
+
+// WithPrompter prompts for missing values and conflicts using p.
+// By default, runs fail with [ErrNoInput] rather than prompting.
+func WithPrompter(p Prompter) Option {
+	return func(o *runOptions) {
+		o.prompter = p
+	}
+}
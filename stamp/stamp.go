@@ -0,0 +1,85 @@
+// Package stamp runs stamp generators from Go programs.
+//
+// Generators are loaded from a [Store] (either by name or by path),
+// configured with values, and then run against a destination dir:
+//
+//	store, err := stamp.DefaultStore()
+//	if err != nil {
+//		return err
+//	}
+//	gen, err := store.Load("my:generator")
+//	if err != nil {
+//		return err
+//	}
+//	if err := gen.SetAll(map[string]any{"Name": "example"}); err != nil {
+//		return err
+//	}
+//	result, err := gen.Run("./example", stamp.WithConflictOverride(stamp.ConflictConfigReplace))
+//	if err != nil {
+//		return err
+//	}
+//	for _, change := range result.Changes {
+//		fmt.Println(change.Action, change.Path)
+//	}
+//
+// Runs never prompt unless a [Prompter] is supplied via [WithPrompter].
+// Without one, missing values and file conflicts return [ErrNoInput].
+package stamp
+
+import (
+	"github.com/twelvelabs/termite/ui"
+
+	core "github.com/twelvelabs/stamp/internal/stamp"
+	"github.com/twelvelabs/stamp/internal/vfs"
+)
+
+type (
+	// ConflictConfig determines how to resolve conflicts with existing files.
+	ConflictConfig = core.ConflictConfig
+	// FileAction describes how a file was changed by a run.
+	FileAction = core.FileAction
+	// FileChange is a file in the destination that was changed by a run.
+	FileChange = core.FileChange
+	// FS is the filesystem that generators read from and write to.
+	FS = vfs.FS
+	// Logger receives the actions taken by each task.
+	Logger = core.Logger
+	// MemoryFS is an in-memory FS layered over another FS.
+	// Changes are kept in memory until committed.
+	MemoryFS = vfs.Memory
+	// Prompter prompts the user for values and how to resolve conflicts.
+	Prompter = ui.Prompter
+	// Task is a single step in a generator (passed to [Logger.TaskStarted]).
+	Task = core.Task
+)
+
+const (
+	ConflictConfigKeep    = core.ConflictConfigKeep
+	ConflictConfigReplace = core.ConflictConfigReplace
+
+	FileActionCreate = core.FileActionCreate
+	FileActionUpdate = core.FileActionUpdate
+	FileActionDelete = core.FileActionDelete
+
+	// NoIteration is the iteration passed to [Logger.TaskStarted]
+	// for tasks that are not run once per item.
+	NoIteration = core.NoIteration
+)
+
+var (
+	// ErrNotFound is returned when a generator can not be found.
+	ErrNotFound = core.ErrNotFound
+	// ErrNoInput is returned when a run needs input but has no [Prompter].
+	ErrNoInput = core.ErrNoInput
+)
+
+// OSFS returns an FS backed by the real OS filesystem (the default).
+func OSFS() FS {
+	return vfs.NewOS()
+}
+
+// NewMemoryFS returns an in-memory FS layered over base.
+// Unchanged paths are read from base (if not nil).
+func NewMemoryFS(base FS) *MemoryFS {
+	return vfs.NewMemory(base)
+}
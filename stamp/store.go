@@ -0,0 +1,62 @@
+package stamp
+
+import (
+	"fmt"
+
+	"github.com/twelvelabs/stamp/internal/fsutil"
+	core "github.com/twelvelabs/stamp/internal/stamp"
+)
+
+// Store is a directory of installed generators.
+type Store struct {
+	store *core.Store
+}
+
+// NewStore returns the store rooted at path.
+func NewStore(path string) *Store {
+	return &Store{
+		store: core.NewStore(path),
+	}
+}
+
+// DefaultStore returns the store used by the stamp CLI
+// (as configured by `.stamp.yaml` or `~/.stamp/config.yaml`).
+func DefaultStore() (*Store, error) {
+	config, err := core.NewConfig("")
+	if err != nil {
+		return nil, err
+	}
+	path, err := fsutil.NormalizePath(config.StorePath)
+	if err != nil {
+		return nil, fmt.Errorf("store path: %w", err)
+	}
+	return NewStore(path), nil
+}
+
+// Path returns the root dir of the store.
+func (s *Store) Path() string {
+	return s.store.BasePath
+}
+
+// Load returns the generator with the given name (i.e. "foo:bar")
+// or at the given filesystem path. Returns [ErrNotFound] if neither exist.
+func (s *Store) Load(nameOrPath string) (*Generator, error) {
+	gen, err := s.store.Load(nameOrPath)
+	if err != nil {
+		return nil, err
+	}
+	return newGenerator(s, gen), nil
+}
+
+// LoadAll returns all the generators in the store.
+func (s *Store) LoadAll() ([]*Generator, error) {
+	all, err := s.store.LoadAll()
+	if err != nil {
+		return nil, err
+	}
+	generators := []*Generator{}
+	for _, gen := range all {
+		generators = append(generators, newGenerator(s, gen))
+	}
+	return generators, nil
+}